@@ -3,6 +3,7 @@ package bob
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"github.com/benchkram/bob/bobtask"
 	"github.com/benchkram/bob/pkg/auth"
 	"github.com/benchkram/bob/pkg/boblog"
+	"github.com/benchkram/bob/pkg/buildinfostore"
 	"github.com/benchkram/bob/pkg/envutil"
 	"github.com/benchkram/bob/pkg/file"
 	"github.com/benchkram/bob/pkg/usererror"
@@ -35,12 +37,12 @@ func (b *B) PrintVersionCompatibility(bobfile *bobfile.Bobfile) {
 			bobletVersion, _ := version.NewVersion(boblet.Version)
 
 			if binVersion.Core().Segments64()[0] != bobletVersion.Core().Segments64()[0] {
-				fmt.Println(aurora.Red(fmt.Sprintf("Warning: major version mismatch: Your bobfile's major version (%s, '%s') is different from the CLI version (%s). This might lead to unexpected errors.", boblet.Version, boblet.Dir(), binVersion)).String())
+				boblog.Log.Info(aurora.Red(fmt.Sprintf("Warning: major version mismatch: Your bobfile's major version (%s, '%s') is different from the CLI version (%s). This might lead to unexpected errors.", boblet.Version, boblet.Dir(), binVersion)).String())
 				continue
 			}
 
 			if binVersion.LessThan(bobletVersion) {
-				fmt.Println(aurora.Red(fmt.Sprintf("Warning: possible version incompatibility: Your bobfile's version (%s, '%s') is higher than the CLI version (%s). Some features might not work as expected.", boblet.Version, boblet.Dir(), binVersion)).String())
+				boblog.Log.Info(aurora.Red(fmt.Sprintf("Warning: possible version incompatibility: Your bobfile's version (%s, '%s') is higher than the CLI version (%s). Some features might not work as expected.", boblet.Version, boblet.Dir(), binVersion)).String())
 				continue
 			}
 		}
@@ -73,7 +75,7 @@ func (b *B) AggregateSparse(omitRunTasks ...bool) (aggregate *bobfile.Bobfile, e
 		return nil, usererror.Wrap(ErrCouldNotFindTopLevelBobfile)
 	}
 
-	bobs, err := readImports(aggregate, true)
+	bobs, err := readImports(aggregate, true, b.env)
 	errz.Fatal(err)
 
 	if aggregate.Project == "" {
@@ -107,7 +109,7 @@ func (b *B) Aggregate() (aggregate *bobfile.Bobfile, err error) {
 	// Passing "." instead of the absPath so the
 	// tasks can be initialized with the relative path.
 	// The absolute path is only stored in `aggregate.Project`.
-	aggregate, err = bobfile.BobfileRead(".")
+	aggregate, err = bobfile.BobfileRead(".", bobfile.WithVariableOverrides(b.env))
 	errz.Fatal(err)
 
 	if !file.Exists(global.BobFileName) {
@@ -122,12 +124,17 @@ func (b *B) Aggregate() (aggregate *bobfile.Bobfile, err error) {
 	decorations, err := collectDecorations(aggregate)
 	errz.Fatal(err)
 
-	bobs, err := readImports(aggregate, false)
+	bobs, err := readImports(aggregate, false, b.env)
 	errz.Fatal(err)
 
 	for _, boblet := range append(bobs, aggregate) {
 		for key, task := range boblet.BTasks {
-			task.SetEnv(envutil.Merge(boblet.Vars(), b.env))
+			if task.DeclaresEnv() {
+				// only declared variables are visible to the task
+				task.SetEnv(task.ResolveEnv(b.env, os.Environ(), boblet.Vars()))
+			} else {
+				task.SetEnv(envutil.Merge(boblet.Vars(), b.env))
+			}
 			boblet.BTasks[key] = task
 		}
 
@@ -155,16 +162,32 @@ func (b *B) Aggregate() (aggregate *bobfile.Bobfile, err error) {
 	// Merge runs into one Bobfile
 	aggregate = b.addRunTasksToAggregate(aggregate, bobs)
 
+	aggregate, err = addPoolsToAggregate(aggregate, bobs)
+	errz.Fatal(err)
+
+	// Artifact compression is set per project,
+	// a codec passed to bob takes precedence.
+	codec := b.codec
+	if codec == "" {
+		codec, err = bobtask.ParseCodec(aggregate.Compression)
+		errz.Fatal(err)
+	}
+
 	// Assure tasks are correctly initialised.
 	for i, task := range aggregate.BTasks {
+		task.SetCodec(codec)
 		task.WithLocalstore(b.local)
 		task.WithEnvStore(b.nix.EnvStore())
+		task.WithSecretsDir(b.secretsDir)
 		task.WithBuildinfoStore(b.buildInfoStore)
 
 		// a task must always-rebuild when caching is disabled
 		if !b.enableCaching {
 			task.SetRebuildStrategy(bobtask.RebuildAlways)
 		}
+		if b.strict {
+			task.Sandbox = true
+		}
 		aggregate.BTasks[i] = task
 	}
 
@@ -186,6 +209,10 @@ func (b *B) Aggregate() (aggregate *bobfile.Bobfile, err error) {
 	aggregate.Dependencies = make([]string, 0)
 	aggregate.Dependencies = append(aggregate.Dependencies, allDeps...)
 
+	// remoteBuildinfoStore shares build infos through the remote store,
+	// so a task built elsewhere is considered unchanged.
+	var remoteBuildinfoStore buildinfostore.Store
+
 	// Initialize remote store in case of a valid remote url / project name
 	if aggregate.Project != "" {
 		projectName, err := project.Parse(aggregate.Project)
@@ -206,19 +233,48 @@ func (b *B) Aggregate() (aggregate *bobfile.Bobfile, err error) {
 			authCtx, err := b.CurrentAuthContext()
 			if err != nil {
 				if errors.Is(err, auth.ErrNotFound) {
-					fmt.Printf("Will not sync to %s because of missing auth context\n", projectName)
+					boblog.Log.Info(fmt.Sprintf("Will not sync to %s because of missing auth context", projectName))
 				} else {
 					return nil, err
 				}
 			} else {
 				boblog.Log.V(1).Info(fmt.Sprintf("Using remote store: %s", url.String()))
 				aggregate.SetRemotestore(bobfile.NewRemotestore(url, b.allowInsecure, authCtx.Token))
+				remoteBuildinfoStore = bobfile.NewBuildinfoRemotestore(url, b.allowInsecure, authCtx.Token)
 			}
 		}
 	} else {
 		aggregate.Project = aggregate.Dir()
 	}
 
+	// A self hosted store takes precedence
+	if aggregate.RemoteStoreHost != "" {
+		remote, err := bobfile.NewRemotestoreFromHost(aggregate.RemoteStoreHost, b.allowInsecure)
+		errz.Fatal(err)
+
+		u, _ := url.Parse(aggregate.RemoteStoreHost)
+		boblog.Log.V(1).Info(fmt.Sprintf("Using remote store: %s", u.Redacted()))
+		aggregate.SetRemotestore(remote)
+
+		remoteBuildinfoStore, err = bobfile.NewBuildinfoRemotestoreFromHost(aggregate.RemoteStoreHost, b.allowInsecure)
+		errz.Fatal(err)
+	}
+
+	if remoteBuildinfoStore != nil && b.enableCaching {
+		buildinfoStore := buildinfostore.NewLayeredStore(
+			b.buildInfoStore,
+			remoteBuildinfoStore,
+			buildinfostore.WithPushEnabled(b.enablePush),
+			buildinfostore.WithPullEnabled(b.enablePull),
+			// a dry run must not have side effects
+			buildinfostore.WithCacheEnabled(!b.dryRun),
+		)
+		for i, task := range aggregate.BTasks {
+			task.WithBuildinfoStore(buildinfoStore)
+			aggregate.BTasks[i] = task
+		}
+	}
+
 	var dockerRegistryClientInitialized bool
 
 	// Assure tasks are correctly initialised with a docker registry client.
@@ -230,9 +286,18 @@ func (b *B) Aggregate() (aggregate *bobfile.Bobfile, err error) {
 
 		if target != nil && len(target.DockerImages()) > 0 {
 			if !dockerRegistryClientInitialized {
-				b.dockerRegistryClient, err = dockermobyutil.NewRegistryClient()
+				runtime, host, err := b.selectContainerRuntime(aggregate.ContainerRuntime)
+				errz.Fatal(err)
+
+				b.dockerRegistryClient, err = dockermobyutil.NewRegistryClient(
+					dockermobyutil.WithRuntime(runtime),
+					dockermobyutil.WithHost(host),
+				)
 				if errors.Is(err, dockermobyutil.ErrConnectionFailed) {
-					errz.Fatal(usererror.Wrapm(err, fmt.Sprintf("task `%s` exports an image, but docker is not reachable", task.Name())))
+					errz.Fatal(usererror.Wrapm(err, fmt.Sprintf("task `%s` exports an image, but no container runtime is reachable", task.Name())))
+				}
+				if errors.Is(err, dockermobyutil.ErrUnknownRuntime) {
+					errz.Fatal(usererror.Wrap(err))
 				}
 				errz.Fatal(err)
 
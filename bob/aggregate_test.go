@@ -1,15 +1,25 @@
 package bob
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	_ "net/http/pprof"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/benchkram/errz"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/benchkram/bob/bob/bobfile"
+	"github.com/benchkram/bob/bobtask/buildinfo"
+	"github.com/benchkram/bob/pkg/auth"
 )
 
 var result *bobfile.Bobfile
@@ -357,3 +367,298 @@ func TestMultiLevelBobfileSameProjectName(t *testing.T) {
 		assert.Equal(t, bobFile.Project, projectName)
 	}
 }
+
+// fakeStoreServer serves the artifact api of a bob server,
+// artifacts are kept in memory by id.
+type fakeStoreServer struct {
+	mu        sync.Mutex
+	artifacts map[string][]byte
+}
+
+func newFakeStoreServer(t *testing.T) (*httptest.Server, *fakeStoreServer) {
+	f := &fakeStoreServer{artifacts: map[string][]byte{}}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case r.Method == http.MethodPost && len(parts) == 4 && parts[3] == "artifacts":
+			id := ""
+			var data []byte
+			mr, err := r.MultipartReader()
+			assert.Nil(t, err)
+			for {
+				part, err := mr.NextPart()
+				if err != nil {
+					break
+				}
+				b, _ := io.ReadAll(part)
+				if part.FormName() == "id" {
+					id = string(b)
+				} else {
+					data = b
+				}
+			}
+			f.artifacts[id] = data
+			_, _ = w.Write([]byte("{}"))
+		case r.Method == http.MethodGet && len(parts) == 5 && parts[3] == "artifact":
+			id, _ := url.PathUnescape(parts[4])
+			if _, ok := f.artifacts[id]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			location := server.URL + "/download/" + id
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]string{"id": id, "location": location})
+		case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "download":
+			_, _ = w.Write(f.artifacts[parts[1]])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, f
+}
+
+func TestAggregateProjectRemoteBuildinfo(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	assert.Nil(t, os.Chdir(dir))
+
+	server, remote := newFakeStoreServer(t)
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+	projectName := u.Host + "/test-user/test-project"
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "bob.yaml"), []byte(fmt.Sprintf(`project: %s
+build:
+  build:
+    input: a.txt
+    cmd: cp a.txt b.txt
+    target: b.txt
+`, projectName)), 0644))
+
+	testBob, err := Bob(
+		WithDir(dir),
+		WithInsecure(true),
+		WithPullEnabled(true),
+		WithAuthStore(auth.New(t.TempDir())),
+	)
+	assert.Nil(t, err)
+	assert.Nil(t, testBob.CreateAuthContext("test", "token"))
+
+	aggregate, err := testBob.Aggregate()
+	assert.Nil(t, err)
+	assert.NotNil(t, aggregate.Remotestore())
+
+	task := aggregate.BTasks["build"]
+	changed, err := task.DidTaskChange()
+	assert.Nil(t, err)
+	assert.True(t, changed)
+
+	// a teammate built the task and pushed its build info
+	hashIn, err := task.HashIn()
+	assert.Nil(t, err)
+	endpoint, err := url.Parse("https://" + projectName)
+	assert.Nil(t, err)
+	bi := buildinfo.New()
+	bi.Meta.Task = "build"
+	err = bobfile.NewBuildinfoRemotestore(endpoint, true, "token").NewBuildInfo(hashIn.String(), bi)
+	assert.Nil(t, err)
+	assert.Contains(t, remote.artifacts, "buildinfo-"+hashIn.String())
+
+	aggregate, err = testBob.Aggregate()
+	assert.Nil(t, err)
+	task = aggregate.BTasks["build"]
+	changed, err = task.DidTaskChange()
+	assert.Nil(t, err)
+	assert.False(t, changed)
+}
+
+// newObjectServer serves objects by path through GET, HEAD and PUT,
+// which is enough for the http cache and the S3 protocol.
+func newObjectServer(t *testing.T) (*httptest.Server, map[string][]byte) {
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method {
+		case http.MethodPut:
+			b, err := io.ReadAll(r.Body)
+			assert.Nil(t, err)
+			objects[r.URL.Path] = b
+		case http.MethodGet, http.MethodHead:
+			b, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if r.Method == http.MethodGet {
+				_, _ = w.Write(b)
+			}
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, objects
+}
+
+func TestAggregateRemoteStoreHost(t *testing.T) {
+	// the project remote never has the build info
+	projectServer, _ := newFakeStoreServer(t)
+	u, err := url.Parse(projectServer.URL)
+	assert.Nil(t, err)
+	projectName := u.Host + "/test-user/test-project"
+
+	server, objects := newObjectServer(t)
+	u, err = url.Parse(server.URL)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name   string
+		host   string
+		object string
+	}{
+		{"http", "http://" + u.Host + "/cache", "/cache/buildinfo/ac/"},
+		{"s3", "s3://bucket/cache?endpoint=" + u.Host, "/bucket/cache/buildinfo/artifacts/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			dir := t.TempDir()
+			assert.Nil(t, os.Chdir(dir))
+
+			assert.Nil(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644))
+			assert.Nil(t, os.WriteFile(filepath.Join(dir, "bob.yaml"), []byte(fmt.Sprintf(`project: %s
+remotestore: %s
+build:
+  build:
+    input: a.txt
+    cmd: cp a.txt b.txt
+    target: b.txt
+`, projectName, tt.host)), 0644))
+
+			testBob, err := Bob(
+				WithDir(dir),
+				WithInsecure(true),
+				WithPullEnabled(true),
+				WithAuthStore(auth.New(t.TempDir())),
+			)
+			assert.Nil(t, err)
+			assert.Nil(t, testBob.CreateAuthContext("test", "token"))
+
+			aggregate, err := testBob.Aggregate()
+			assert.Nil(t, err)
+			assert.Equal(t, tt.host, aggregate.RemoteStoreHost)
+
+			task := aggregate.BTasks["build"]
+			changed, err := task.DidTaskChange()
+			assert.Nil(t, err)
+			assert.True(t, changed)
+
+			// a teammate built the task and pushed its build info
+			hashIn, err := task.HashIn()
+			assert.Nil(t, err)
+			remote, err := bobfile.NewBuildinfoRemotestoreFromHost(tt.host, true)
+			assert.Nil(t, err)
+			bi := buildinfo.New()
+			bi.Meta.Task = "build"
+			assert.Nil(t, remote.NewBuildInfo(hashIn.String(), bi))
+			assert.Contains(t, objects, tt.object+hashIn.String())
+
+			aggregate, err = testBob.Aggregate()
+			assert.Nil(t, err)
+			task = aggregate.BTasks["build"]
+			changed, err = task.DidTaskChange()
+			assert.Nil(t, err)
+			assert.False(t, changed)
+		})
+	}
+
+	t.Run("unsupported scheme", func(t *testing.T) {
+		t.Setenv("HOME", t.TempDir())
+		dir := t.TempDir()
+		assert.Nil(t, os.Chdir(dir))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "bob.yaml"), []byte(`remotestore: ftp://`+u.Host+`
+build:
+  build:
+    cmd: echo
+`), 0644))
+
+		testBob, err := Bob(WithDir(dir), WithAuthStore(auth.New(t.TempDir())))
+		assert.Nil(t, err)
+		_, err = testBob.Aggregate()
+		assert.ErrorIs(t, err, bobfile.ErrInvalidRemoteStoreHost)
+	})
+}
+
+func TestAggregateMatrix(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.Chdir(dir))
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "bob.yaml"), []byte(`import:
+  - child
+build:
+  # decorates the umbrella task of the matrix
+  child/build:
+    dependsOn: [before]
+  before:
+    cmd: echo before
+  all:
+    dependsOn: [child/build]
+`), 0644))
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "child"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "child", "bob.yaml"), []byte(`build:
+  build:
+    matrix:
+      os: [linux, darwin]
+    cmd: echo ${matrix.os} > out-${matrix.os}
+    target: out-${matrix.os}
+    dependsOn:
+      - generate[${matrix.os}]
+  generate:
+    matrix:
+      os: [linux, darwin]
+    cmd: echo ${matrix.os}
+`), 0644))
+
+	testBob, err := Bob(WithDir(dir))
+	assert.Nil(t, err)
+
+	aggregate, err := testBob.Aggregate()
+	assert.Nil(t, err)
+
+	instance, ok := aggregate.BTasks["child/build[linux]"]
+	assert.True(t, ok)
+	assert.Equal(t, "child/build[linux]", instance.Name())
+	assert.Equal(t, "out-linux", instance.TargetDirty)
+	assert.Equal(t, []string{"child/generate[linux]"}, instance.DependsOn)
+
+	umbrella := aggregate.BTasks["child/build"]
+	assert.Equal(t, []string{"before", "child/build[linux]", "child/build[darwin]"}, umbrella.DependsOn)
+	assert.Equal(t, []string{"child/build"}, aggregate.BTasks["all"].DependsOn)
+
+	// tasks listed by `bob build ls`
+	tasks, err := testBob.GetBuildTasks()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"all",
+		"before",
+		"child/build",
+		"child/build[darwin]",
+		"child/build[linux]",
+		"child/generate",
+		"child/generate[darwin]",
+		"child/generate[linux]",
+	}, tasks)
+}
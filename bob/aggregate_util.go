@@ -74,6 +74,30 @@ func (b *B) addBuildTasksToAggregate(a *bobfile.Bobfile, bobs []*bobfile.Bobfile
 	return a, nil
 }
 
+// addPoolsToAggregate merges the pools of all bobfiles into the aggregate.
+// Pools are global, declaring a pool with different limits is an error.
+func addPoolsToAggregate(a *bobfile.Bobfile, bobs []*bobfile.Bobfile) (*bobfile.Bobfile, error) {
+	if a.Pools == nil {
+		a.Pools = make(map[string]int)
+	}
+
+	for _, bobfile := range bobs {
+		// Skip the aggregate
+		if bobfile.Dir() == a.Dir() {
+			continue
+		}
+
+		for name, limit := range bobfile.Pools {
+			if existing, ok := a.Pools[name]; ok && existing != limit {
+				return a, usererror.Wrap(fmt.Errorf("pool `%s` is declared with different limits (%d, %d)", name, existing, limit))
+			}
+			a.Pools[name] = limit
+		}
+	}
+
+	return a, nil
+}
+
 func (b *B) addRunTasksToAggregate(
 	a *bobfile.Bobfile,
 	bobs []*bobfile.Bobfile,
@@ -115,11 +139,15 @@ func (b *B) addRunTasksToAggregate(
 // readModePlain allows to read bobfiles without
 // doing sanitization.
 //
+// Imported bobfiles inherit the variables of the importing bobfile,
+// env takes precedence over all variables.
+//
 // If prefix is given it's appended to the search path to assure
 // correctness of the search path in case of recursive calls.
 func readImports(
 	a *bobfile.Bobfile,
 	readModePlain bool,
+	env []string,
 	prefix ...string,
 ) (imports []*bobfile.Bobfile, err error) {
 	errz.Recover(&err)
@@ -137,7 +165,11 @@ func readImports(
 		if readModePlain {
 			boblet, err = bobfile.BobfileReadPlain(filepath.Join(p, importPath))
 		} else {
-			boblet, err = bobfile.BobfileRead(filepath.Join(p, importPath))
+			boblet, err = bobfile.BobfileRead(
+				filepath.Join(p, importPath),
+				bobfile.WithParentVariables(a.Variables),
+				bobfile.WithVariableOverrides(env),
+			)
 		}
 		if err != nil {
 			if errors.Is(err, bobfile.ErrBobfileNotFound) {
@@ -148,7 +180,7 @@ func readImports(
 		imports = append(imports, boblet)
 
 		// read imports recursively
-		childImports, err := readImports(boblet, readModePlain, boblet.Dir())
+		childImports, err := readImports(boblet, readModePlain, env, boblet.Dir())
 		errz.Fatal(err)
 		imports = append(imports, childImports...)
 	}
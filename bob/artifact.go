@@ -10,6 +10,7 @@ import (
 
 	"github.com/benchkram/bob/bobtask"
 	"github.com/benchkram/bob/bobtask/targettype"
+	"github.com/benchkram/bob/pkg/store"
 	"github.com/benchkram/bob/pkg/usererror"
 	"github.com/benchkram/errz"
 )
@@ -114,3 +115,78 @@ func (b *B) ArtifactInspect(artifactID string) (ai bobtask.ArtifactInfo, err err
 
 	return bobtask.ArtifactInspectFromReader(artifact)
 }
+
+// ArtifactRemove removes artifacts from the local store. Blobs referenced by
+// the removed artifacts are only deleted when no other artifact references them.
+func (b *B) ArtifactRemove(ctx context.Context, artifactIDs ...string) (err error) {
+	defer errz.Recover(&err)
+
+	for _, id := range artifactIDs {
+		if !b.local.ArtifactExists(ctx, id) {
+			return usererror.Wrapm(bobtask.ErrArtifactDoesNotExist, fmt.Sprintf("[artifact: %s]", id))
+		}
+		err = b.local.ArtifactRemove(ctx, id)
+		errz.Fatal(err)
+	}
+
+	_, err = b.ArtifactGarbageCollect(ctx)
+	errz.Fatal(err)
+
+	return nil
+}
+
+// ArtifactGarbageCollect removes blobs from the local store which are not
+// referenced by any artifact. Returns the ids of the removed blobs.
+func (b *B) ArtifactGarbageCollect(ctx context.Context) (removed []string, err error) {
+	defer errz.Recover(&err)
+
+	removed = []string{}
+
+	blobStore, ok := b.local.(store.BlobStore)
+	if !ok {
+		return removed, nil
+	}
+
+	references, err := b.blobReferences(ctx)
+	errz.Fatal(err)
+
+	blobs, err := blobStore.ListBlobs(ctx)
+	errz.Fatal(err)
+
+	for _, id := range blobs {
+		if references[id] > 0 {
+			continue
+		}
+		err = blobStore.BlobRemove(ctx, id)
+		errz.Fatal(err)
+		removed = append(removed, id)
+	}
+
+	return removed, nil
+}
+
+// blobReferences counts the references to each blob
+// over all artifacts in the local store.
+func (b *B) blobReferences(ctx context.Context) (_ map[string]int, err error) {
+	defer errz.Recover(&err)
+
+	references := make(map[string]int)
+
+	items, err := b.local.List(ctx)
+	errz.Fatal(err)
+
+	for _, item := range items {
+		artifact, _, err := b.local.GetArtifact(ctx, item)
+		errz.Fatal(err)
+
+		artifactInfo, err := bobtask.ArtifactInspectFromReader(artifact)
+		_ = artifact.Close()
+		errz.Fatal(err)
+
+		for _, id := range artifactInfo.Manifest().Blobs() {
+			references[id]++
+		}
+	}
+
+	return references, nil
+}
@@ -5,6 +5,8 @@ import (
 	"runtime"
 
 	nixbuilder "github.com/benchkram/bob/bob/nix-builder"
+	"github.com/benchkram/bob/bob/playbook"
+	"github.com/benchkram/bob/bobtask"
 	"github.com/benchkram/bob/pkg/auth"
 	"github.com/benchkram/bob/pkg/dockermobyutil"
 	"github.com/benchkram/bob/pkg/usererror"
@@ -56,18 +58,46 @@ type B struct {
 	// enablePull enables the artifacts download from remote store
 	enablePull bool
 
+	// codec overrides the artifact compression set in the bobfile
+	codec bobtask.Codec
+
+	// eventHandler receives task state transitions of builds
+	eventHandler playbook.EventHandler
+
+	// durationStore records task durations to prioritise the critical path
+	durationStore buildinfostore.DurationStore
+
+	// traceFile and junitFile are paths build reports are written to
+	traceFile string
+	junitFile string
+
 	// nix builds dependencies for tasks
 	nix *nixbuilder.NB
 
 	// authStore is used to store authentication credentials for remote store
 	authStore *auth.Store
 
+	// secretsDir contains files with secrets of tasks
+	secretsDir string
+
 	// env is a list of strings representing the environment in the form "key=value"
 	env []string
 
 	// maxParallel is the maximum number of parallel executed tasks
 	maxParallel int
 
+	// strict runs all build tasks sandboxed
+	strict bool
+
+	// dryRun is set during BuildDryRun(), remote build infos
+	// are read without caching them locally.
+	dryRun bool
+
+	// containerRuntime and containerHost override the
+	// container runtime set in the bobfile
+	containerRuntime string
+	containerHost    string
+
 	// dockerRegistryClient is used to access the local docker registry
 	dockerRegistryClient dockermobyutil.RegistryClient
 }
@@ -114,11 +144,18 @@ func BobWithBaseStoreDir(baseStoreDir string, opts ...Option) (*B, error) {
 	}
 	bob.buildInfoStore = bis
 
+	ds, err := DurationStore(baseStoreDir, bob.dir)
+	if err != nil {
+		return nil, err
+	}
+	bob.durationStore = ds
+
 	authStore, err := AuthStore(baseStoreDir)
 	if err != nil {
 		return nil, err
 	}
 	bob.authStore = authStore
+	bob.secretsDir = SecretsDir(baseStoreDir)
 
 	nixBuilder, err := NixBuilder(baseStoreDir)
 	if err != nil {
@@ -165,6 +202,15 @@ func Bob(opts ...Option) (*B, error) {
 			return nil, err
 		}
 		bob.buildInfoStore = bis
+
+		// durations are kept alongside the build infos
+		if bob.durationStore == nil {
+			ds, err := DefaultDurationStore(bob.dir)
+			if err != nil {
+				return nil, err
+			}
+			bob.durationStore = ds
+		}
 	}
 
 	if bob.nix == nil {
@@ -183,6 +229,14 @@ func Bob(opts ...Option) (*B, error) {
 		bob.authStore = fs
 	}
 
+	if bob.secretsDir == "" {
+		dir, err := DefaultSecretsDir()
+		if err != nil {
+			return nil, err
+		}
+		bob.secretsDir = dir
+	}
+
 	return bob, nil
 }
 
@@ -1,8 +1,10 @@
 package bob
 
 import (
+	"encoding/hex"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/benchkram/errz"
 
@@ -10,6 +12,7 @@ import (
 	nixbuilder "github.com/benchkram/bob/bob/nix-builder"
 	"github.com/benchkram/bob/pkg/auth"
 	"github.com/benchkram/bob/pkg/buildinfostore"
+	"github.com/benchkram/bob/pkg/filehash"
 	"github.com/benchkram/bob/pkg/nix"
 	"github.com/benchkram/bob/pkg/store"
 	"github.com/benchkram/bob/pkg/store/filestore"
@@ -31,7 +34,11 @@ func Filestore(dir string) (s store.Store, err error) {
 	err = os.MkdirAll(storeDir, 0775)
 	errz.Fatal(err)
 
-	return filestore.New(storeDir), nil
+	blobDir := filepath.Join(dir, global.BobCacheBlobsDir)
+	err = os.MkdirAll(blobDir, 0775)
+	errz.Fatal(err)
+
+	return filestore.New(storeDir, filestore.WithBlobDir(blobDir)), nil
 }
 
 func MustDefaultFilestore() store.Store {
@@ -58,6 +65,31 @@ func BuildinfoStore(baseDir string) (s buildinfostore.Store, err error) {
 	return buildinfostore.NewProtoStore(storeDir), nil
 }
 
+func DefaultDurationStore(workspaceDir string) (s buildinfostore.DurationStore, err error) {
+	defer errz.Recover(&err)
+
+	home, err := os.UserHomeDir()
+	errz.Fatal(err)
+
+	return DurationStore(home, workspaceDir)
+}
+
+// DurationStore returns the store of task durations of a workspace.
+// Each workspace uses its own file as task names are not unique
+// across workspaces.
+func DurationStore(baseDir, workspaceDir string) (s buildinfostore.DurationStore, err error) {
+	defer errz.Recover(&err)
+
+	abs, err := filepath.Abs(workspaceDir)
+	errz.Fatal(err)
+
+	h, err := filehash.HashBytes(strings.NewReader(abs))
+	errz.Fatal(err)
+
+	path := filepath.Join(baseDir, global.BobCacheDurationsDir, hex.EncodeToString(h)+".json")
+	return buildinfostore.NewDurationStore(path), nil
+}
+
 func MustDefaultBuildinfoStore() buildinfostore.Store {
 	s, _ := DefaultBuildinfoStore()
 	return s
@@ -87,6 +119,20 @@ func DefaultAuthStore() (s *auth.Store, err error) {
 	return AuthStore(home)
 }
 
+// SecretsDir is the directory secrets are read from,
+// next to the auth store.
+func SecretsDir(baseDir string) string {
+	return filepath.Join(baseDir, global.BobSecretsDir)
+}
+
+func DefaultSecretsDir() (_ string, err error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return SecretsDir(home), nil
+}
+
 // NixBuilder initialises a new nix builder object with the cache setup
 // in the given location.
 //
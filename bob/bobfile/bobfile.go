@@ -5,15 +5,19 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
+	"github.com/benchkram/bob/pkg/buildinfostore"
 	"github.com/benchkram/bob/pkg/nix"
 	storeclient "github.com/benchkram/bob/pkg/store-client"
 
 	"github.com/benchkram/bob/pkg/sliceutil"
 	"github.com/benchkram/bob/pkg/store"
+	"github.com/benchkram/bob/pkg/store/httpstore"
 	"github.com/benchkram/bob/pkg/store/remotestore"
+	"github.com/benchkram/bob/pkg/store/s3store"
 	"github.com/benchkram/bob/pkg/usererror"
 
 	"github.com/hashicorp/go-version"
@@ -39,9 +43,12 @@ var (
 	ErrDuplicateTaskName      = fmt.Errorf("duplicate task name")
 	ErrInvalidProjectName     = fmt.Errorf("invalid project name")
 	ErrSelfReference          = fmt.Errorf("self reference")
+	ErrInvalidRemoteStoreHost = fmt.Errorf("invalid remote store host")
 
 	ErrInvalidRunType = fmt.Errorf("Invalid run type")
 
+	ErrInvalidPool = fmt.Errorf("invalid pool")
+
 	ProjectNameFormatHint = "project name should be in the form 'project' or 'registry.com/user/project'"
 )
 
@@ -58,8 +65,13 @@ type Bobfile struct {
 	Imports []string `yaml:"import,omitempty"`
 
 	// Variables is a map of variables that can be used in the tasks.
+	// Values can reference other variables, e.g. `${HOST:-localhost}:8080`.
 	Variables VariableMap
 
+	// VariablesFrom lists `.env` or YAML files, relative to the bobfile,
+	// to load variables from. Variables of the bobfile take precedence.
+	VariablesFrom []string `yaml:"variables_from,omitempty"`
+
 	// BTasks build tasks
 	BTasks bobtask.Map `yaml:"build"`
 	// RTasks run tasks
@@ -70,16 +82,42 @@ type Bobfile struct {
 	// Nixpkgs specifies an optional nixpkgs source.
 	Nixpkgs string `yaml:"nixpkgs"`
 
+	// Compression codec used for artifacts of this project (optional).
+	// One of gzip, zstd, brotli or none, defaults to gzip.
+	Compression string `yaml:"compression,omitempty"`
+
+	// Pools limit the number of tasks running in parallel
+	// which reference the pool, e.g. `docker: 1`.
+	// Pools are shared by all bobfiles of a workspace.
+	Pools map[string]int `yaml:"pools,omitempty"`
+
+	// ContainerRuntime selects the container runtime docker image
+	// targets are saved to and loaded from (optional), auto-detected
+	// by default. `BOB_CONTAINER_RUNTIME` overrides the runtime.
+	ContainerRuntime ContainerRuntime `yaml:"containerRuntime,omitempty"`
+
 	// Parent directory of the Bobfile.
 	// Populated through BobfileRead().
 	dir string
 
 	bobfiles []*Bobfile
 
-	RemoteStoreHost string
+	// RemoteStoreHost selects a self hosted artifact store by url scheme (optional).
+	//   http(s)://host/path                          plain http cache
+	//   s3://bucket/prefix?endpoint=host&region=...  S3 compatible api
+	// Takes precedence over the store derived from the project name.
+	RemoteStoreHost string `yaml:"remotestore,omitempty"`
 	remotestore     store.Store
 }
 
+// ContainerRuntime is the container runtime setting of a bobfile.
+type ContainerRuntime struct {
+	// Runtime is one of docker, podman, containerd or auto.
+	Runtime string `yaml:"runtime,omitempty"`
+	// Host is the socket of the runtime, e.g. `unix:///run/podman/podman.sock`.
+	Host string `yaml:"host,omitempty"`
+}
+
 func NewBobfile() *Bobfile {
 	b := &Bobfile{
 		Variables: make(VariableMap),
@@ -138,6 +176,9 @@ func bobfileRead(dir string) (_ *Bobfile, err error) {
 		bobfile.RTasks = bobrun.RunMap{}
 	}
 
+	err = bobfile.BTasks.ExpandMatrices()
+	errz.Fatal(err)
+
 	// Assure tasks are initialized with their defaults
 	for key, task := range bobfile.BTasks {
 		task.SetDir(bobfile.dir)
@@ -236,17 +277,123 @@ func NewRemotestore(endpoint *url.URL, allowInsecure bool, token string) (s stor
 	return s
 }
 
+// NewBuildinfoRemotestore creates a build info store in the project of
+// the remote store. Build infos are stored next to the artifacts.
+func NewBuildinfoRemotestore(endpoint *url.URL, allowInsecure bool, token string) buildinfostore.Store {
+	return buildinfostore.NewRemoteStore(
+		NewRemotestore(endpoint, allowInsecure, token),
+		buildinfostore.WithIDPrefix("buildinfo-"),
+	)
+}
+
+// NewRemotestoreFromHost creates the store matching the scheme of host.
+// Credentials for S3 are read from the environment (AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN).
+func NewRemotestoreFromHost(host string, allowInsecure bool) (_ store.Store, err error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, usererror.Wrap(errors.WithMessage(ErrInvalidRemoteStoreHost, err.Error()))
+	}
+	return newRemotestoreFromURL(u, allowInsecure)
+}
+
+// NewBuildinfoRemotestoreFromHost creates a build info store next to
+// the artifacts of the store matching host.
+func NewBuildinfoRemotestoreFromHost(host string, allowInsecure bool) (_ buildinfostore.Store, err error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, usererror.Wrap(errors.WithMessage(ErrInvalidRemoteStoreHost, err.Error()))
+	}
+	u.Path = path.Join(u.Path, "buildinfo")
+
+	s, err := newRemotestoreFromURL(u, allowInsecure)
+	if err != nil {
+		return nil, err
+	}
+	return buildinfostore.NewRemoteStore(s), nil
+}
+
+func newRemotestoreFromURL(u *url.URL, allowInsecure bool) (_ store.Store, err error) {
+	switch u.Scheme {
+	case "http", "https":
+		return httpstore.New(u), nil
+	case "s3":
+		if u.Host == "" {
+			return nil, usererror.Wrap(errors.WithMessage(ErrInvalidRemoteStoreHost, "missing bucket"))
+		}
+
+		query := u.Query()
+		region := query.Get("region")
+		if region == "" {
+			region = os.Getenv("AWS_REGION")
+		}
+		if region == "" {
+			region = s3store.DefaultRegion
+		}
+		endpoint := query.Get("endpoint")
+		if endpoint == "" {
+			endpoint = "s3." + region + ".amazonaws.com"
+		}
+
+		return s3store.New(
+			endpoint,
+			u.Host,
+			s3store.WithPrefix(u.Path),
+			s3store.WithRegion(region),
+			s3store.WithInsecure(allowInsecure),
+			s3store.WithCredentials(
+				os.Getenv("AWS_ACCESS_KEY_ID"),
+				os.Getenv("AWS_SECRET_ACCESS_KEY"),
+				os.Getenv("AWS_SESSION_TOKEN"),
+			),
+		), nil
+	default:
+		return nil, usererror.Wrap(errors.WithMessage(ErrInvalidRemoteStoreHost, fmt.Sprintf("unsupported scheme [%s]", u.Scheme)))
+	}
+}
+
+type readOptions struct {
+	parentVariables VariableMap
+	overrides       []string
+}
+
+type ReadOption func(*readOptions)
+
+// WithParentVariables passes the resolved variables
+// of the importing bobfile to be inherited.
+func WithParentVariables(variables VariableMap) ReadOption {
+	return func(o *readOptions) {
+		o.parentVariables = variables
+	}
+}
+
+// WithVariableOverrides sets variables in the form "key=value"
+// taking precedence over the variables of the bobfile.
+func WithVariableOverrides(env []string) ReadOption {
+	return func(o *readOptions) {
+		o.overrides = env
+	}
+}
+
 // BobfileRead read from a bobfile.
-// Calls sanitize on the result.
-func BobfileRead(dir string) (_ *Bobfile, err error) {
+// Resolves variables and calls sanitize on the result.
+func BobfileRead(dir string, opts ...ReadOption) (_ *Bobfile, err error) {
 	defer errz.Recover(&err)
 
+	var o readOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	b, err := bobfileRead(dir)
 	errz.Fatal(err)
 
 	err = b.Validate()
 	errz.Fatal(err)
 
+	err = b.ResolveVariables(o.parentVariables, o.overrides)
+	errz.Fatal(err)
+
 	err = b.BTasks.Sanitize()
 	errz.Fatal(err)
 
@@ -291,6 +438,10 @@ func (b *Bobfile) Validate() (err error) {
 		}
 	}
 
+	if _, err := bobtask.ParseCodec(b.Compression); err != nil {
+		return usererror.Wrap(err)
+	}
+
 	// use for duplicate names validation
 	names := map[string]bool{}
 
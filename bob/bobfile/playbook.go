@@ -7,6 +7,9 @@ import (
 
 func (b *Bobfile) Playbook(taskName string, opts ...playbook.Option) (*playbook.Playbook, error) {
 
+	// pools are declared in the bobfile, options passed by the caller take precedence
+	opts = append([]playbook.Option{playbook.WithPools(b.Pools)}, opts...)
+
 	var idCounter int
 	pb := playbook.New(
 		taskName,
@@ -1,3 +1,438 @@
 package bobfile
 
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benchkram/bob/bob/global"
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+var (
+	ErrUnresolvedVariable = fmt.Errorf("unresolved variable")
+	ErrVariableCycle      = fmt.Errorf("variable references itself")
+	ErrInvalidVariables   = fmt.Errorf("invalid variables file")
+)
+
+var variableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 type VariableMap map[string]string
+
+// variableResolver resolves references in the form `${NAME}`
+// and `${NAME:-default}`. Names are looked up in overrides,
+// variables (resolved recursively) and the host environment.
+// Unresolved references are left as they are, see expandStrict().
+type variableResolver struct {
+	overrides map[string]string
+	variables VariableMap
+
+	resolved  map[string]string
+	resolving map[string]bool
+	// unresolved references contained in a resolved variable
+	unresolved map[string][]string
+}
+
+func newVariableResolver(variables VariableMap, overrides []string) *variableResolver {
+	r := &variableResolver{
+		overrides:  map[string]string{},
+		variables:  variables,
+		resolved:   map[string]string{},
+		resolving:  map[string]bool{},
+		unresolved: map[string][]string{},
+	}
+	for _, kv := range overrides {
+		k, v, _ := strings.Cut(kv, "=")
+		r.overrides[k] = v
+	}
+	return r
+}
+
+// lookup returns the value of a variable and the
+// names of unresolved references it contains.
+func (r *variableResolver) lookup(name string) (string, []string, bool, error) {
+	if v, ok := r.overrides[name]; ok {
+		return v, nil, true, nil
+	}
+	if v, ok := r.resolved[name]; ok {
+		return v, r.unresolved[name], true, nil
+	}
+	if raw, ok := r.variables[name]; ok {
+		if r.resolving[name] {
+			return "", nil, false, fmt.Errorf("%w `%s`", ErrVariableCycle, name)
+		}
+		r.resolving[name] = true
+		v, unresolved, err := r.expand(raw)
+		delete(r.resolving, name)
+		if err != nil {
+			return "", nil, false, err
+		}
+		r.resolved[name] = v
+		r.unresolved[name] = unresolved
+		return v, unresolved, true, nil
+	}
+	v, ok := os.LookupEnv(name)
+	return v, nil, ok, nil
+}
+
+// expand all references in s. References which can't be resolved, e.g.
+// to variables only set at runtime, are left in place and returned.
+func (r *variableResolver) expand(s string) (_ string, unresolved []string, _ error) {
+	var sb strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			sb.WriteString(s)
+			return sb.String(), unresolved, nil
+		}
+		sb.WriteString(s[:start])
+
+		end := closingBrace(s, start+2)
+		if end < 0 {
+			// not a reference, e.g. a lone `${`
+			sb.WriteString(s[start:])
+			return sb.String(), unresolved, nil
+		}
+
+		name, def, hasDefault := strings.Cut(s[start+2:end], ":-")
+		if !variableNamePattern.MatchString(name) {
+			// leave other expressions, e.g. `${matrix.os}`, to the shell
+			sb.WriteString(s[start : end+1])
+			s = s[end+1:]
+			continue
+		}
+
+		value, nested, ok, err := r.lookup(name)
+		if err != nil {
+			return "", nil, err
+		}
+		if !ok || (value == "" && hasDefault) {
+			if !hasDefault {
+				sb.WriteString(s[start : end+1])
+				s = s[end+1:]
+				unresolved = append(unresolved, name)
+				continue
+			}
+			value, nested, err = r.expand(def)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+		sb.WriteString(value)
+		unresolved = append(unresolved, nested...)
+		s = s[end+1:]
+	}
+}
+
+// expandStrict expands all references in s,
+// failing on the first unresolved reference.
+func (r *variableResolver) expandStrict(s string) (string, error) {
+	value, unresolved, err := r.expand(s)
+	if err != nil {
+		return "", err
+	}
+	if len(unresolved) > 0 {
+		return "", fmt.Errorf("%w `%s`", ErrUnresolvedVariable, unresolved[0])
+	}
+	return value, nil
+}
+
+// closingBrace returns the index of the brace closing the
+// reference starting at from, nested references are skipped.
+func closingBrace(s string, from int) int {
+	depth := 1
+	for i := from; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// ResolveVariables merges the variables inherited from a parent bobfile,
+// variables loaded from `variables_from` files and the bobfile's own
+// variables, later ones take precedence. References to other variables
+// are resolved, overrides (`--env`) take precedence over all of them.
+// Input and target of build tasks are substituted with the result.
+//
+// Variables may contain references only resolvable at runtime, which are
+// kept as they are. In input and target they fail with ErrUnresolvedVariable.
+func (b *Bobfile) ResolveVariables(parent VariableMap, overrides []string) (err error) {
+	bobfilePath := filepath.Join(b.dir, global.BobFileName)
+
+	variables := VariableMap{}
+	for k, v := range parent {
+		variables[k] = v
+	}
+
+	// source of each variable to point at the line of an error
+	sources := map[string]string{}
+	for _, from := range b.VariablesFrom {
+		path := filepath.Join(b.dir, from)
+		fromFile, err := readVariablesFile(path)
+		if err != nil {
+			return usererror.Wrapm(err, fmt.Sprintf("failed to load variables from %s", path))
+		}
+		for k, v := range fromFile {
+			variables[k] = v
+			sources[k] = path
+		}
+	}
+	for k, v := range b.Variables {
+		variables[k] = v
+		sources[k] = bobfilePath
+	}
+
+	r := newVariableResolver(variables, overrides)
+
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resolved := VariableMap{}
+	for _, name := range names {
+		value, _, _, err := r.lookup(name)
+		if err != nil {
+			source, ok := sources[name]
+			if !ok {
+				return usererror.Wrap(fmt.Errorf("%w (%s)", err, bobfilePath))
+			}
+			return variableError(source, variableLine(source, bobfilePath, name), err)
+		}
+		resolved[name] = value
+	}
+	b.Variables = resolved
+
+	for key, task := range b.BTasks {
+		// tasks expanded from a matrix are defined by their umbrella task
+		name, _, _ := strings.Cut(key, "[")
+
+		task.InputDirty, err = r.expandStrict(task.InputDirty)
+		if err != nil {
+			return variableError(bobfilePath, referenceLine(bobfilePath, err, "build", name, "input"), err)
+		}
+
+		task.TargetDirty, err = expandTarget(task.TargetDirty, r)
+		if err != nil {
+			return variableError(bobfilePath, referenceLine(bobfilePath, err, "build", name, "target"), err)
+		}
+
+		b.BTasks[key] = task
+	}
+
+	return nil
+}
+
+// expandTarget expands all strings of a target definition.
+func expandTarget(target interface{}, r *variableResolver) (_ interface{}, err error) {
+	switch t := target.(type) {
+	case string:
+		return r.expandStrict(t)
+	case []interface{}:
+		expanded := make([]interface{}, 0, len(t))
+		for _, v := range t {
+			e, err := expandTarget(v, r)
+			if err != nil {
+				return nil, err
+			}
+			expanded = append(expanded, e)
+		}
+		return expanded, nil
+	case map[string]interface{}:
+		expanded := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			expanded[k], err = expandTarget(v, r)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return expanded, nil
+	default:
+		return target, nil
+	}
+}
+
+// variableError points at a line of path, unknown if line is 0.
+func variableError(path string, line int, err error) error {
+	if line > 0 {
+		return usererror.Wrap(fmt.Errorf("%w (%s:%d)", err, path, line))
+	}
+	return usererror.Wrap(fmt.Errorf("%w (%s)", err, path))
+}
+
+// variableLine returns the line defining a variable in its source.
+func variableLine(source, bobfilePath, name string) int {
+	switch {
+	case source == bobfilePath:
+		return valueLine(source, "variables", name)
+	case filepath.Ext(source) == ".yaml" || filepath.Ext(source) == ".yml":
+		return valueLine(source, name)
+	default:
+		return envLine(source, name)
+	}
+}
+
+var variableErrorName = regexp.MustCompile("`([^`]*)`")
+
+// referenceLine returns the line of the reference which caused err in
+// the value at keys, falls back to the first line of the value.
+func referenceLine(path string, err error, keys ...string) int {
+	node := valueNode(path, keys...)
+	if node == nil {
+		return 0
+	}
+
+	m := variableErrorName.FindStringSubmatch(err.Error())
+	if m == nil {
+		return node.Line
+	}
+	if scalar := findScalar(node, "${"+m[1]); scalar != nil {
+		node = scalar
+	}
+	if i := strings.Index(node.Value, "${"+m[1]); i >= 0 && node.Style == yaml.LiteralStyle {
+		// the content of a block scalar starts on the next line
+		return node.Line + 1 + strings.Count(node.Value[:i], "\n")
+	}
+	return node.Line
+}
+
+// valueLine returns the line of the value at keys in a YAML file.
+func valueLine(path string, keys ...string) int {
+	node := valueNode(path, keys...)
+	if node == nil {
+		return 0
+	}
+	return node.Line
+}
+
+// valueNode looks up the value at keys of nested mappings in a YAML file.
+func valueNode(path string, keys ...string) *yaml.Node {
+	bin, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var doc yaml.Node
+	err = yaml.Unmarshal(bin, &doc)
+	if err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+
+	node := doc.Content[0]
+	for _, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var value *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				value = node.Content[i+1]
+				break
+			}
+		}
+		if value == nil {
+			return nil
+		}
+		node = value
+	}
+	return node
+}
+
+// findScalar returns the first scalar below node containing text.
+func findScalar(node *yaml.Node, text string) *yaml.Node {
+	if node.Kind == yaml.ScalarNode {
+		if strings.Contains(node.Value, text) {
+			return node
+		}
+		return nil
+	}
+	for _, child := range node.Content {
+		if found := findScalar(child, text); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// envLine returns the line defining a variable in a `.env` file.
+func envLine(path, name string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		l := strings.TrimPrefix(strings.TrimSpace(s.Text()), "export ")
+		if n, _, ok := strings.Cut(l, "="); ok && strings.TrimSpace(n) == name {
+			return line
+		}
+	}
+	return 0
+}
+
+// readVariablesFile reads a YAML file (.yaml, .yml) mapping
+// names to values or a `.env` file with `NAME=value` lines.
+func readVariablesFile(path string) (VariableMap, error) {
+	bin, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	variables := VariableMap{}
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(bin, &variables)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidVariables, err.Error())
+		}
+	default:
+		s := bufio.NewScanner(bytes.NewReader(bin))
+		for line := 1; s.Scan(); line++ {
+			l := strings.TrimSpace(s.Text())
+			if l == "" || strings.HasPrefix(l, "#") {
+				continue
+			}
+			l = strings.TrimPrefix(l, "export ")
+
+			name, value, ok := strings.Cut(l, "=")
+			name = strings.TrimSpace(name)
+			if !ok || !variableNamePattern.MatchString(name) {
+				return nil, fmt.Errorf("%w, expected `NAME=value` on line %d", ErrInvalidVariables, line)
+			}
+			variables[name] = unquote(strings.TrimSpace(value))
+		}
+	}
+
+	for name := range variables {
+		if !variableNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("%w, invalid name `%s`", ErrInvalidVariables, name)
+		}
+	}
+	return variables, nil
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 {
+		first, last := value[0], value[len(value)-1]
+		if (first == '"' || first == '\'') && first == last {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
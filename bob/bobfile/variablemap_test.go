@@ -0,0 +1,147 @@
+package bobfile_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/benchkram/bob/bob/bobfile"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestResolveVariables(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "bob.yaml"), `
+variables_from:
+  - .env
+  - vars.yaml
+variables:
+  HOST: ${BOB_TEST_UNSET_HOST:-localhost}
+  URL: http://${HOST}:${PORT}
+  OUT: ${BOB_TEST_UNSET_OUT:-${NAME}-dist}
+build:
+  build:
+    input: ./cmd/${NAME}
+    cmd: go build -o ${OUT}/${NAME}
+    target: ${OUT}/${NAME}
+`)
+	writeFile(t, filepath.Join(dir, ".env"), `
+# comment
+export NAME="server"
+PORT=8080
+`)
+	writeFile(t, filepath.Join(dir, "vars.yaml"), `PORT: "9090"`)
+
+	b, err := bobfile.BobfileRead(dir)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "localhost", b.Variables["HOST"])
+	assert.Equal(t, "9090", b.Variables["PORT"], "later files take precedence")
+	assert.Equal(t, "http://localhost:9090", b.Variables["URL"])
+	assert.Equal(t, "server-dist", b.Variables["OUT"])
+
+	task := b.BTasks["build"]
+	assert.Equal(t, "./cmd/server", task.InputDirty)
+	// left to the shell
+	assert.Equal(t, "go build -o ${OUT}/${NAME}", task.CmdDirty)
+
+	b, err = bobfile.BobfileRead(dir, bobfile.WithVariableOverrides([]string{"HOST=example.com"}))
+	assert.Nil(t, err)
+	assert.Equal(t, "http://example.com:9090", b.Variables["URL"])
+}
+
+func TestResolveVariablesInherited(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "bob.yaml"), `
+variables:
+  URL: http://${HOST}
+  HOST: child
+`)
+
+	b, err := bobfile.BobfileRead(dir, bobfile.WithParentVariables(bobfile.VariableMap{
+		"HOST":   "parent",
+		"REGION": "eu",
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, "http://child", b.Variables["URL"])
+	assert.Equal(t, "eu", b.Variables["REGION"])
+}
+
+func TestResolveVariablesErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "bob.yaml"), `
+build:
+  build:
+    cmd: echo
+    target: dist/${BOB_TEST_UNSET}
+`)
+
+	_, err := bobfile.BobfileRead(dir)
+	assert.True(t, errors.Is(err, bobfile.ErrUnresolvedVariable))
+	assert.True(t, strings.Contains(err.Error(), filepath.Join(dir, "bob.yaml")+":5)"), err.Error())
+
+	// the line of the offending value is reported
+	writeFile(t, filepath.Join(dir, "bob.yaml"), `
+build:
+  ${BOB_TEST_UNSET}:
+    cmd: echo
+  build:
+    input: |-
+      main.go
+      ${BOB_TEST_UNSET}/*.go
+    cmd: echo
+`)
+	_, err = bobfile.BobfileRead(dir)
+	assert.True(t, errors.Is(err, bobfile.ErrUnresolvedVariable))
+	assert.True(t, strings.Contains(err.Error(), filepath.Join(dir, "bob.yaml")+":8)"), err.Error())
+
+	writeFile(t, filepath.Join(dir, "bob.yaml"), `
+variables:
+  A: ${B}
+  B: ${A}
+`)
+	_, err = bobfile.BobfileRead(dir)
+	assert.True(t, errors.Is(err, bobfile.ErrVariableCycle))
+	assert.True(t, strings.Contains(err.Error(), filepath.Join(dir, "bob.yaml")+":3)"), err.Error())
+}
+
+func TestResolveVariablesRuntime(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "bob.yaml"), `
+variables:
+  GREETING: hello ${BOB_TEST_UNSET}
+  MESSAGE: ${GREETING}!
+build:
+  build:
+    cmd: echo ${MESSAGE}
+`)
+
+	// references only resolvable at runtime are kept
+	b, err := bobfile.BobfileRead(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello ${BOB_TEST_UNSET}", b.Variables["GREETING"])
+	assert.Equal(t, "hello ${BOB_TEST_UNSET}!", b.Variables["MESSAGE"])
+
+	// but fail when used in input or target
+	writeFile(t, filepath.Join(dir, "bob.yaml"), `
+variables:
+  GREETING: hello ${BOB_TEST_UNSET}
+build:
+  build:
+    input: ${GREETING}
+    cmd: echo
+`)
+	_, err = bobfile.BobfileRead(dir)
+	assert.True(t, errors.Is(err, bobfile.ErrUnresolvedVariable))
+	assert.True(t, strings.Contains(err.Error(), "`BOB_TEST_UNSET`"), err.Error())
+	assert.True(t, strings.Contains(err.Error(), filepath.Join(dir, "bob.yaml")+":6)"), err.Error())
+}
@@ -1,6 +1,9 @@
 package bobfile
 
 import (
+	"fmt"
+
+	"github.com/benchkram/bob/pkg/usererror"
 	"github.com/benchkram/errz"
 )
 
@@ -26,6 +29,9 @@ func (b *Bobfile) verifyBefore() (err error) {
 	err = b.BTasks.VerifyDuplicateTargets()
 	errz.Fatal(err)
 
+	err = b.verifyPools()
+	errz.Fatal(err)
+
 	for _, task := range b.BTasks {
 		err = task.VerifyBefore()
 		errz.Fatal(err)
@@ -34,6 +40,27 @@ func (b *Bobfile) verifyBefore() (err error) {
 	return nil
 }
 
+// verifyPools checks pool limits and that
+// tasks only reference declared pools.
+func (b *Bobfile) verifyPools() error {
+	for name, limit := range b.Pools {
+		if limit < 1 {
+			return usererror.Wrap(fmt.Errorf("%w, limit of pool `%s` must be at least 1", ErrInvalidPool, name))
+		}
+	}
+
+	for _, task := range b.BTasks {
+		if task.Pool == "" {
+			continue
+		}
+		if _, ok := b.Pools[task.Pool]; !ok {
+			return usererror.Wrap(fmt.Errorf("%w, task `%s` uses pool `%s` which is not declared in `pools`", ErrInvalidPool, task.Name(), task.Pool))
+		}
+	}
+
+	return nil
+}
+
 // verifyAfter verifies a Bobfile after Run() is called.
 func (b *Bobfile) verifyAfter() (err error) {
 	defer errz.Recover(&err)
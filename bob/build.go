@@ -26,19 +26,7 @@ func (b *B) Build(ctx context.Context, taskName string) (err error) {
 	err = b.nix.BuildNixDependenciesInPipeline(ag, taskName)
 	errz.Fatal(err)
 
-	// Hint: Hash computation (playbook execution) can only start after
-	// nix dependencies are resolved.
-	// Nix dependencies are considered in the input hash of a task.
-	p, err := ag.Playbook(
-		taskName,
-		playbook.WithCachingEnabled(b.enableCaching),
-		playbook.WithPredictedNumOfTasks(len(ag.BTasks)),
-		playbook.WithMaxParallel(b.maxParallel),
-		playbook.WithRemoteStore(ag.Remotestore()),
-		playbook.WithLocalStore(b.local),
-		playbook.WithPushEnabled(b.enablePush),
-		playbook.WithPullEnabled(b.enablePull),
-	)
+	p, err := b.playbook(ag, taskName)
 	errz.Fatal(err)
 
 	err = p.Build(ctx)
@@ -47,6 +35,48 @@ func (b *B) Build(ctx context.Context, taskName string) (err error) {
 	return nil
 }
 
+// BuildDryRun determines what Build() would do for a task and it's
+// dependencies. No commands are executed and no artifacts are written.
+func (b *B) BuildDryRun(ctx context.Context, taskName string) (_ []playbook.DryRunResult, err error) {
+	defer errz.Recover(&err)
+
+	b.dryRun = true
+	defer func() { b.dryRun = false }()
+
+	ag, err := b.Aggregate()
+	errz.Fatal(err)
+
+	err = b.nix.BuildNixDependenciesInPipeline(ag, taskName)
+	errz.Fatal(err)
+
+	p, err := b.playbook(ag, taskName)
+	errz.Fatal(err)
+
+	return p.DryRun(ctx)
+}
+
+func (b *B) playbook(ag *bobfile.Bobfile, taskName string, opts ...playbook.Option) (*playbook.Playbook, error) {
+	// Hint: Hash computation (playbook execution) can only start after
+	// nix dependencies are resolved.
+	// Nix dependencies are considered in the input hash of a task.
+	return ag.Playbook(
+		taskName,
+		append([]playbook.Option{
+			playbook.WithCachingEnabled(b.enableCaching),
+			playbook.WithPredictedNumOfTasks(len(ag.BTasks)),
+			playbook.WithMaxParallel(b.maxParallel),
+			playbook.WithRemoteStore(ag.Remotestore()),
+			playbook.WithLocalStore(b.local),
+			playbook.WithPushEnabled(b.enablePush),
+			playbook.WithPullEnabled(b.enablePull),
+			playbook.WithEventHandler(b.eventHandler),
+			playbook.WithDurationStore(b.durationStore),
+			playbook.WithTraceFile(b.traceFile),
+			playbook.WithJUnitFile(b.junitFile),
+		}, opts...)...,
+	)
+}
+
 // AggregateWithNixDeps does aggregation together with evaluating nix dependecies.
 // Nic dependencies are altering a tasks input hash.
 // Use this function for building `bob inspect` cmds.
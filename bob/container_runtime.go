@@ -0,0 +1,39 @@
+package bob
+
+import (
+	"os"
+
+	"github.com/benchkram/bob/bob/bobfile"
+	"github.com/benchkram/bob/pkg/dockermobyutil"
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+// selectContainerRuntime selects the container runtime of the registry client.
+// The bobfile setting is overridden by `BOB_CONTAINER_RUNTIME`, both are
+// overridden by WithContainerRuntime(). A host set for a runtime is dropped
+// when the runtime is overridden.
+func (b *B) selectContainerRuntime(cfg bobfile.ContainerRuntime) (_ dockermobyutil.Runtime, host string, err error) {
+	runtime, err := dockermobyutil.ParseRuntime(cfg.Runtime)
+	if err != nil {
+		return "", "", usererror.Wrap(err)
+	}
+	host = cfg.Host
+
+	for _, override := range []string{os.Getenv(dockermobyutil.EnvRuntime), b.containerRuntime} {
+		if override == "" {
+			continue
+		}
+		r, err := dockermobyutil.ParseRuntime(override)
+		if err != nil {
+			return "", "", usererror.Wrap(err)
+		}
+		if r != runtime {
+			runtime, host = r, ""
+		}
+	}
+	if b.containerHost != "" {
+		host = b.containerHost
+	}
+
+	return runtime, host, nil
+}
@@ -0,0 +1,50 @@
+package bob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/benchkram/bob/bob/bobfile"
+	"github.com/benchkram/bob/pkg/dockermobyutil"
+)
+
+func TestSelectContainerRuntime(t *testing.T) {
+	podman := bobfile.ContainerRuntime{Runtime: "podman", Host: "unix:///run/podman/podman.sock"}
+
+	tests := []struct {
+		name        string
+		bobfile     bobfile.ContainerRuntime
+		env         string
+		cliRuntime  string
+		cliHost     string
+		wantRuntime dockermobyutil.Runtime
+		wantHost    string
+		wantErr     bool
+	}{
+		{name: "auto-detection by default", wantRuntime: dockermobyutil.RuntimeAuto},
+		{name: "bobfile", bobfile: podman, wantRuntime: dockermobyutil.RuntimePodman, wantHost: podman.Host},
+		{name: "env overrides bobfile", bobfile: podman, env: "containerd", wantRuntime: dockermobyutil.RuntimeContainerd},
+		{name: "env keeps the host of the same runtime", bobfile: podman, env: "Podman", wantRuntime: dockermobyutil.RuntimePodman, wantHost: podman.Host},
+		{name: "cli overrides env", bobfile: podman, env: "containerd", cliRuntime: "docker", wantRuntime: dockermobyutil.RuntimeDocker},
+		{name: "cli selects auto-detection", bobfile: podman, cliRuntime: "auto", wantRuntime: dockermobyutil.RuntimeAuto},
+		{name: "cli host", bobfile: podman, cliHost: "unix:///tmp/podman.sock", wantRuntime: dockermobyutil.RuntimePodman, wantHost: "unix:///tmp/podman.sock"},
+		{name: "invalid bobfile runtime", bobfile: bobfile.ContainerRuntime{Runtime: "rkt"}, wantErr: true},
+		{name: "invalid env runtime", env: "rkt", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(dockermobyutil.EnvRuntime, tt.env)
+
+			b := newBob(WithContainerRuntime(tt.cliRuntime, tt.cliHost))
+			runtime, host, err := b.selectContainerRuntime(tt.bobfile)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, dockermobyutil.ErrUnknownRuntime)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantRuntime, runtime)
+			assert.Equal(t, tt.wantHost, host)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package bob
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/benchkram/errz"
+
+	"github.com/benchkram/bob/pkg/boberror"
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+// ExportOCI builds a task and writes the oci image layout
+// stored in the artifact of the task to dst.
+func (b *B) ExportOCI(ctx context.Context, taskName, dst string) (err error) {
+	defer errz.Recover(&err)
+
+	if !b.enableCaching {
+		return usererror.Wrap(fmt.Errorf("export requires caching, the image is read from the artifact of %s", taskName))
+	}
+
+	ag, err := b.Aggregate()
+	errz.Fatal(err)
+
+	task, ok := ag.BTasks[taskName]
+	if !ok {
+		return usererror.Wrap(boberror.ErrTaskDoesNotExistF(taskName))
+	}
+	if tt, err := task.Target(); err != nil || tt == nil || tt.OCIImage() == nil {
+		return usererror.Wrap(fmt.Errorf("task %s has no oci target", taskName))
+	}
+
+	err = b.Build(ctx, taskName)
+	errz.Fatal(err)
+
+	// the input hash is only known after the build
+	ag, err = b.AggregateWithNixDeps(taskName)
+	errz.Fatal(err)
+	task = ag.BTasks[taskName]
+
+	hashIn, err := task.HashIn()
+	errz.Fatal(err)
+
+	// e.g. the target was built with caching disabled before
+	if !task.ArtifactExists(hashIn) {
+		err = task.ArtifactCreate(hashIn)
+		errz.Fatal(err)
+	}
+
+	return task.ArtifactExportOCI(hashIn, dst)
+}
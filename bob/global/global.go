@@ -13,12 +13,20 @@ const (
 const BobCacheDir = ".bobcache"
 const BobNixCacheFile = ".nix_cache"
 
+// Workspace directory for files written by bob which are not a cache
+const BobDir = ".bob"
+
 var (
 	BobCacheBuildinfoDir       = filepath.Join(BobCacheDir, "buildinfos")
+	BobCacheDurationsDir       = filepath.Join(BobCacheBuildinfoDir, "durations")
 	BobCacheTaskHashesFileName = filepath.Join(BobCacheDir, "hashes")
 	BobCacheArtifactsDir       = filepath.Join(BobCacheDir, "artifacts")
+	BobCacheBlobsDir           = filepath.Join(BobCacheDir, "blobs")
 	BobAuthStoreDir            = filepath.Join(BobCacheDir, "auth")
+	BobSecretsDir              = filepath.Join(BobAuthStoreDir, "secrets")
 
 	BobCacheNixFileName      = filepath.Join(BobCacheDir, BobNixCacheFile)
 	BobCacheNixShellCacheDir = filepath.Join(BobCacheDir, "env")
+
+	BobLogsDir = filepath.Join(BobDir, "logs")
 )
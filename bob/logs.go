@@ -0,0 +1,37 @@
+package bob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/benchkram/errz"
+
+	"github.com/benchkram/bob/bob/global"
+	"github.com/benchkram/bob/pkg/runlog"
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+// Logs writes the logs of a run task persisted by `bob run` to w.
+// With follow new output is written until ctx is canceled.
+func (b *B) Logs(ctx context.Context, runTaskName string, w io.Writer, follow bool) (err error) {
+	defer errz.Recover(&err)
+
+	aggregate, err := b.AggregateSparse()
+	errz.Fatal(err)
+
+	if _, ok := aggregate.RTasks[runTaskName]; !ok {
+		return usererror.Wrap(fmt.Errorf("%w: %s", ErrRunDoesNotExist, runTaskName))
+	}
+
+	dir := runlog.Dir(filepath.Join(b.dir, global.BobLogsDir), runTaskName)
+
+	files, err := runlog.Files(dir)
+	errz.Fatal(err)
+	if len(files) == 0 && !follow {
+		return usererror.Wrap(fmt.Errorf("no logs found for %s, logs are written by `bob run`", runTaskName))
+	}
+
+	return runlog.Tail(ctx, dir, w, follow)
+}
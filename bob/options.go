@@ -2,6 +2,8 @@ package bob
 
 import (
 	nixbuilder "github.com/benchkram/bob/bob/nix-builder"
+	"github.com/benchkram/bob/bob/playbook"
+	"github.com/benchkram/bob/bobtask"
 	"github.com/benchkram/bob/pkg/auth"
 	"github.com/benchkram/bob/pkg/buildinfostore"
 	"github.com/benchkram/bob/pkg/store"
@@ -65,6 +67,49 @@ func WithPullEnabled(enabled bool) Option {
 	}
 }
 
+// WithCodec overrides the artifact compression codec set in the bobfile.
+func WithCodec(codec bobtask.Codec) Option {
+	return func(b *B) {
+		b.codec = codec
+	}
+}
+
+// WithContainerRuntime overrides the container runtime and its host
+// set in the bobfile or by `BOB_CONTAINER_RUNTIME`. Empty values are ignored.
+func WithContainerRuntime(runtime, host string) Option {
+	return func(b *B) {
+		b.containerRuntime = runtime
+		b.containerHost = host
+	}
+}
+
+// WithEventHandler receives the state transitions of all build tasks.
+func WithEventHandler(h playbook.EventHandler) Option {
+	return func(b *B) {
+		b.eventHandler = h
+	}
+}
+
+func WithDurationStore(store buildinfostore.DurationStore) Option {
+	return func(b *B) {
+		b.durationStore = store
+	}
+}
+
+// WithTraceFile writes a chrome trace of a build to path.
+func WithTraceFile(path string) Option {
+	return func(b *B) {
+		b.traceFile = path
+	}
+}
+
+// WithJUnitFile writes a junit report of a build to path.
+func WithJUnitFile(path string) Option {
+	return func(b *B) {
+		b.junitFile = path
+	}
+}
+
 func WithInsecure(allow bool) Option {
 	return func(b *B) {
 		b.allowInsecure = allow
@@ -83,6 +128,14 @@ func WithEnvVariables(env []string) Option {
 	}
 }
 
+// WithStrict runs all build tasks sandboxed to
+// detect undeclared inputs and targets.
+func WithStrict(strict bool) Option {
+	return func(b *B) {
+		b.strict = strict
+	}
+}
+
 func WithMaxParallel(maxParallel int) Option {
 	return func(b *B) {
 		b.maxParallel = maxParallel
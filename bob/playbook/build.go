@@ -24,6 +24,19 @@ func (p *Playbook) Build(ctx context.Context) (err error) {
 
 	p.pickTaskColors()
 
+	err = p.loadDurations()
+	if err != nil {
+		boblog.Log.V(1).Info(fmt.Sprintf("failed to load task durations: %s", err))
+	}
+	p.preparePriorities()
+	p.prepareUnchangedTasks()
+
+	for _, t := range p.TasksOptimized {
+		if t.State() == StatePending {
+			p.emit(t)
+		}
+	}
+
 	wm := p.startWorkers(ctx, workers)
 
 	// listen for idle workers
@@ -90,6 +103,14 @@ func (p *Playbook) Build(ctx context.Context) (err error) {
 
 	p.summary(wm.processed)
 
+	err = p.storeDurations()
+	if err != nil {
+		boblog.Log.V(1).Info(fmt.Sprintf("failed to store task durations: %s", err))
+	}
+
+	// reports are written independent of the build result
+	reportErr := p.writeReports()
+
 	if len(wm.errors) > 0 {
 		// Pass only the very first processing error.
 		return wm.errors[0]
@@ -105,6 +126,10 @@ func (p *Playbook) Build(ctx context.Context) (err error) {
 		}
 	}
 
+	if reportErr != nil {
+		return usererror.Wrapm(reportErr, "failed to write build report")
+	}
+
 	return nil
 }
 
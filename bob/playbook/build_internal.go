@@ -49,6 +49,13 @@ func (p *Playbook) build(ctx context.Context, task *bobtask.Task) (pt *processed
 		}
 	}()
 
+	if p.isUnchanged(task.TaskID) {
+		status := StateNoRebuildRequired
+		boblog.Log.V(2).Info(fmt.Sprintf("%-*s\t%s, inputs unchanged", p.namePad, coloredName, status.Short()))
+		taskSuccessFul = true
+		return pt, p.TaskNoRebuildRequired(task.TaskID)
+	}
+
 	rebuild, err := p.TaskNeedsRebuild(task.TaskID)
 	errz.Fatal(err)
 	boblog.Log.V(2).Info(fmt.Sprintf("TaskNeedsRebuild [rebuildRequired: %t] [cause:%s]", rebuild.IsRequired, rebuild.Cause))
@@ -56,11 +63,15 @@ func (p *Playbook) build(ctx context.Context, task *bobtask.Task) (pt *processed
 	// Task might need a rebuild due to an input change.
 	// Could still be possible to load the targets from the artifact store.
 	// If a task needs a rebuild due to a dependency change => rebuild.
+	ts := p.TasksOptimized[task.TaskID]
 	if rebuild.IsRequired {
+		ts.SetRebuildCause(rebuild.Cause)
+
 		switch rebuild.Cause {
 		case InputNotFoundInBuildInfo:
 			hashIn, err := task.HashIn()
 			errz.Fatal(err)
+			source := artifactSource(task.ArtifactExists(hashIn))
 
 			// pull artifact if it exists on the remote. if exists locally will use that one
 			err = p.pullArtifact(ctx, hashIn, task, false)
@@ -72,6 +83,7 @@ func (p *Playbook) build(ctx context.Context, task *bobtask.Task) (pt *processed
 				if errors.Is(err, io.ErrUnexpectedEOF) {
 					err = p.pullArtifact(ctx, hashIn, task, true)
 					errz.Fatal(err)
+					source = ArtifactSourceRemote
 					success, err = task.ArtifactExtract(hashIn, rebuild.VerifyResult.InvalidFiles)
 				}
 			}
@@ -79,6 +91,7 @@ func (p *Playbook) build(ctx context.Context, task *bobtask.Task) (pt *processed
 			errz.Fatal(err)
 			if success {
 				rebuild.IsRequired = false
+				ts.SetArtifactSource(source)
 
 				// In case an artifact was synced from the remote store no buildinfo exists...
 				// To avoid subsequent artifact extraction the Buildinfo is created after
@@ -92,10 +105,20 @@ func (p *Playbook) build(ctx context.Context, task *bobtask.Task) (pt *processed
 			boblog.Log.V(2).Info(fmt.Sprintf("%-*s\t%s, extracting artifact", p.namePad, coloredName, rebuild.Cause))
 			hashIn, err := task.HashIn()
 			errz.Fatal(err)
+
+			// the buildinfo might originate from the remote store,
+			// in that case the artifact is likely to exist there as well.
+			source := artifactSource(task.ArtifactExists(hashIn))
+			if source == ArtifactSourceRemote {
+				err = p.pullArtifact(ctx, hashIn, task, false)
+				errz.Fatal(err)
+			}
+
 			success, err := task.ArtifactExtract(hashIn, rebuild.VerifyResult.InvalidFiles)
 			errz.Fatal(err)
 			if success {
 				rebuild.IsRequired = false
+				ts.SetArtifactSource(source)
 			}
 		case TargetNotInLocalStore:
 		case TaskForcedRebuild:
@@ -114,7 +137,11 @@ func (p *Playbook) build(ctx context.Context, task *bobtask.Task) (pt *processed
 	err = task.CleanTargetsWithReason(rebuild.VerifyResult.InvalidFiles)
 	errz.Fatal(err)
 
-	err = task.Run(ctx, p.namePad)
+	if task.Sandbox {
+		err = task.RunSandboxed(ctx, p.namePad, p.dependencyTargets(task.TaskID))
+	} else {
+		err = task.Run(ctx, p.namePad)
+	}
 	if err != nil {
 		taskSuccessFul = false
 		taskErr = err
@@ -144,3 +171,34 @@ func (p *Playbook) build(ctx context.Context, task *bobtask.Task) (pt *processed
 
 	return pt, nil
 }
+
+// artifactSource assumes an artifact not existing
+// locally is pulled from the remote store.
+func artifactSource(existsLocally bool) ArtifactSource {
+	if existsLocally {
+		return ArtifactSourceLocal
+	}
+	return ArtifactSourceRemote
+}
+
+// dependencyTargets returns the filesystem targets of all
+// tasks the given task depends on, directly or transitively.
+func (p *Playbook) dependencyTargets(taskID int) (targets []string) {
+	visited := map[int]bool{}
+
+	var collect func(id int)
+	collect = func(id int) {
+		for _, dependentID := range p.TasksOptimized[id].DependsOnIDs {
+			if visited[dependentID] {
+				continue
+			}
+			visited[dependentID] = true
+
+			targets = append(targets, p.TasksOptimized[dependentID].TargetPaths()...)
+			collect(dependentID)
+		}
+	}
+	collect(taskID)
+
+	return targets
+}
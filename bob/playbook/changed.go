@@ -0,0 +1,130 @@
+package playbook
+
+import "sync"
+
+// Succeeded records the tasks which completed successfully in the
+// builds of a watch session, see WithSucceededTasks(). Tasks must be
+// invalidated together with their dependents when their inputs change.
+type Succeeded struct {
+	mu    sync.Mutex
+	tasks map[string]bool
+	// stale tasks were invalidated after their build started,
+	// they are not recorded as succeeded on completion.
+	stale map[string]bool
+}
+
+func NewSucceeded() *Succeeded {
+	return &Succeeded{
+		tasks: map[string]bool{},
+		stale: map[string]bool{},
+	}
+}
+
+// Invalidate forgets that the tasks succeeded, e.g. as their inputs changed.
+// A task which is currently built is not recorded as succeeded on completion.
+func (s *Succeeded) Invalidate(tasknames ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, taskname := range tasknames {
+		s.tasks[taskname] = false
+		s.stale[taskname] = true
+	}
+}
+
+// Reset forgets all tasks, e.g. when the build definition changed.
+func (s *Succeeded) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for taskname := range s.tasks {
+		s.tasks[taskname] = false
+		s.stale[taskname] = true
+	}
+}
+
+// Has returns true if the task succeeded and wasn't invalidated since.
+func (s *Succeeded) Has(taskname string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tasks[taskname]
+}
+
+func (s *Succeeded) set(taskname string, succeeded bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if succeeded && s.stale[taskname] {
+		return
+	}
+	s.tasks[taskname] = succeeded
+}
+
+// start forgets the previous result of a task about to be built.
+func (s *Succeeded) start(taskname string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks[taskname] = false
+	delete(s.stale, taskname)
+}
+
+// recordSucceeded tracks the final state of a task, see WithSucceededTasks().
+func (p *Playbook) recordSucceeded(taskID int, state State) {
+	if p.succeeded == nil {
+		return
+	}
+
+	switch state {
+	case StateCompleted, StateNoRebuildRequired:
+		p.succeeded.set(p.TasksOptimized[taskID].Task.Name(), true)
+	case StateFailed, StateCanceled:
+		p.succeeded.set(p.TasksOptimized[taskID].Task.Name(), false)
+	}
+}
+
+// prepareUnchangedTasks flags all tasks which succeeded in a previous
+// build and only depend on such tasks, see WithSucceededTasks().
+// Tasks which failed or were never reached are checked.
+func (p *Playbook) prepareUnchangedTasks() {
+	if p.succeeded == nil {
+		return
+	}
+	p.prepareOptimizedAccess()
+
+	names := make([]string, len(p.TasksOptimized))
+	for name, t := range p.Tasks {
+		names[t.TaskID] = name
+	}
+
+	p.unchanged = make([]bool, len(p.TasksOptimized))
+	visited := make([]bool, len(p.TasksOptimized))
+	var check func(id int) bool
+	check = func(id int) bool {
+		if visited[id] {
+			return p.unchanged[id]
+		}
+		visited[id] = true
+
+		unchanged := p.succeeded.Has(names[id])
+		for _, dependency := range p.TasksOptimized[id].DependsOnIDs {
+			if !check(dependency) {
+				unchanged = false
+			}
+		}
+		p.unchanged[id] = unchanged
+		return unchanged
+	}
+	for id := range p.TasksOptimized {
+		check(id)
+	}
+
+	// tasks about to be built are only skipped again once they succeed
+	for id, name := range names {
+		if !p.unchanged[id] {
+			p.succeeded.start(name)
+		}
+	}
+}
+
+// isUnchanged returns true if the task is known to be up to date
+// and can be skipped without computing its hash.
+func (p *Playbook) isUnchanged(taskID int) bool {
+	return p.unchanged != nil && p.unchanged[taskID]
+}
@@ -0,0 +1,74 @@
+package playbook
+
+import (
+	"testing"
+
+	"github.com/benchkram/bob/bobtask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrepareUnchangedTasks(t *testing.T) {
+	// build -> [lib -> gen, assets]
+	tasks := []struct {
+		name      string
+		dependsOn []string
+	}{
+		{name: "build", dependsOn: []string{"lib", "assets"}},
+		{name: "lib", dependsOn: []string{"gen"}},
+		{name: "gen"},
+		{name: "assets"},
+	}
+
+	newPlaybook := func(opts ...Option) *Playbook {
+		p := New("build", 0, opts...)
+		for id, task := range tasks {
+			status := NewStatus(&bobtask.Task{TaskID: id, DependsOn: task.dependsOn})
+			p.Tasks[task.name] = status
+			p.TasksOptimized = append(p.TasksOptimized, status)
+		}
+		return p
+	}
+
+	// assets failed in the previous build, lib's inputs changed
+	succeeded := NewSucceeded()
+	for _, name := range []string{"build", "lib", "gen"} {
+		succeeded.set(name, true)
+	}
+	succeeded.Invalidate("lib")
+
+	p := newPlaybook(WithSucceededTasks(succeeded))
+	p.prepareUnchangedTasks()
+
+	assert.False(t, p.isUnchanged(0), "build depends on lib")
+	assert.False(t, p.isUnchanged(1), "lib changed")
+	assert.True(t, p.isUnchanged(2))
+	assert.False(t, p.isUnchanged(3), "assets didn't succeed before")
+
+	// tasks about to be built are only skipped again once they succeed
+	assert.False(t, succeeded.Has("build"))
+	assert.False(t, succeeded.Has("lib"))
+	assert.True(t, succeeded.Has("gen"))
+
+	succeeded.set("lib", true)
+	assert.True(t, succeeded.Has("lib"))
+
+	// inputs changing while a task is built
+	succeeded.start("build")
+	succeeded.Invalidate("build")
+	succeeded.set("build", true)
+	assert.False(t, succeeded.Has("build"))
+
+	// nothing is skipped without knowing the previous build
+	p = newPlaybook()
+	p.prepareUnchangedTasks()
+	for id := range tasks {
+		assert.False(t, p.isUnchanged(id))
+	}
+
+	succeeded.Reset()
+	p = newPlaybook(WithSucceededTasks(succeeded))
+	p.prepareUnchangedTasks()
+	for id := range tasks {
+		assert.False(t, p.isUnchanged(id))
+	}
+}
@@ -3,6 +3,7 @@ package playbook
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/benchkram/bob/bobtask/buildinfo"
 	"github.com/benchkram/bob/bobtask/target"
@@ -36,6 +37,11 @@ func (p *Playbook) computeBuildinfo(taskname string) (_ *buildinfo.I, err error)
 	}
 	buildInfo.Meta.Task = task.Name()
 	buildInfo.Meta.InputHash = hashIn.String()
+	buildInfo.Meta.CreatedAt = time.Now().UnixNano()
+
+	inputs, err := task.InputManifest()
+	errz.Fatal(err)
+	buildInfo.Inputs = *inputs
 
 	// Compute buildinfo for the target
 	trgt, err := task.Task.Target()
@@ -0,0 +1,146 @@
+package playbook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/benchkram/errz"
+	"github.com/logrusorgru/aurora"
+
+	"github.com/benchkram/bob/bobtask/target"
+	"github.com/benchkram/bob/pkg/store"
+)
+
+// ArtifactSource tells from where the targets of a task would be restored.
+type ArtifactSource string
+
+const (
+	ArtifactSourceNone   ArtifactSource = ""
+	ArtifactSourceLocal  ArtifactSource = "local"
+	ArtifactSourceRemote ArtifactSource = "remote"
+)
+
+// DryRunResult describes what a build would do for a single task.
+type DryRunResult struct {
+	Task      string `json:"task"`
+	InputHash string `json:"input_hash"`
+
+	// Rebuild is true when the task's commands would run.
+	Rebuild bool `json:"rebuild"`
+
+	// Cause is the reason the task is not up to date.
+	// Empty if the task is up to date.
+	Cause RebuildCause `json:"cause,omitempty"`
+
+	// InvalidFiles are the target files failing verification.
+	InvalidFiles map[string][]target.Reason `json:"invalid_files,omitempty"`
+
+	// ArtifactSource is set when the targets would be
+	// restored from an artifact instead of rebuilding.
+	ArtifactSource ArtifactSource `json:"artifact_source,omitempty"`
+}
+
+// DryRun walks the playbook like Build() does, without running any
+// commands or writing artifacts. Results are ordered like the tasks
+// would be built.
+func (p *Playbook) DryRun(ctx context.Context) (_ []DryRunResult, err error) {
+	defer errz.Recover(&err)
+
+	p.prepareOptimizedAccess()
+
+	results := []DryRunResult{}
+	err = p.TasksOptimized.walkBottomFirst(p.rootID, func(taskID int, task *Status, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// tasks are reachable through multiple paths
+		if task.State() != StatePending {
+			return nil
+		}
+
+		rebuild, err := p.TaskNeedsRebuild(taskID)
+		errz.Fatal(err)
+
+		hashIn, err := task.HashIn()
+		errz.Fatal(err)
+
+		result := DryRunResult{
+			Task:         task.Name(),
+			InputHash:    hashIn.String(),
+			Rebuild:      rebuild.IsRequired,
+			Cause:        rebuild.Cause,
+			InvalidFiles: rebuild.VerifyResult.InvalidFiles,
+		}
+
+		// Mirror the artifact handling of build()
+		switch rebuild.Cause {
+		case InputNotFoundInBuildInfo, TargetInvalid:
+			if task.ArtifactExists(hashIn) {
+				result.ArtifactSource = ArtifactSourceLocal
+			} else if p.canPull() {
+				found, err := store.Exists(ctx, p.remoteStore, hashIn.String())
+				errz.Fatal(err)
+				if found {
+					result.ArtifactSource = ArtifactSourceRemote
+				}
+			}
+			if result.ArtifactSource != ArtifactSourceNone {
+				result.Rebuild = false
+			}
+		}
+
+		// Dependent tasks evaluate the state of their children
+		state := StateNoRebuildRequired
+		if result.Rebuild {
+			state = StateCompleted
+		}
+		err = p.setTaskState(taskID, state, nil)
+		errz.Fatal(err)
+
+		results = append(results, result)
+		return nil
+	})
+	errz.Fatal(err)
+
+	return results, nil
+}
+
+// PrintDryRun writes a human readable description of the results to w.
+func PrintDryRun(w io.Writer, results []DryRunResult) {
+	namePad := 0
+	for _, r := range results {
+		if len(r.Task) > namePad {
+			namePad = len(r.Task)
+		}
+	}
+
+	for _, r := range results {
+		var action string
+		switch {
+		case r.Rebuild:
+			action = aurora.Yellow("rebuild").String()
+		case r.ArtifactSource != ArtifactSourceNone:
+			action = aurora.Green(fmt.Sprintf("restore from %s artifact", r.ArtifactSource)).String()
+		default:
+			action = aurora.Green("cached").String()
+		}
+
+		line := fmt.Sprintf("%-*s\t%s", namePad, r.Task, action)
+		if r.Cause != "" {
+			line += aurora.Faint(fmt.Sprintf("\t(%s)", r.Cause)).String()
+		}
+		fmt.Fprintln(w, line)
+
+		files := make([]string, 0, len(r.InvalidFiles))
+		for f := range r.InvalidFiles {
+			files = append(files, f)
+		}
+		sort.Strings(files)
+		for _, f := range files {
+			fmt.Fprintf(w, "  %s\t%s\n", f, aurora.Faint(fmt.Sprint(r.InvalidFiles[f])))
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package playbook
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event describes a state transition of a task.
+type Event struct {
+	Time  time.Time `json:"time"`
+	Task  string    `json:"task"`
+	State string    `json:"state"`
+
+	InputHash string       `json:"input_hash,omitempty"`
+	Cause     RebuildCause `json:"cause,omitempty"`
+
+	// DurationMs is the execution time of the task,
+	// only set when the task reached a final state.
+	DurationMs *int64 `json:"duration_ms,omitempty"`
+
+	ArtifactSource ArtifactSource `json:"artifact_source,omitempty"`
+	Error          string         `json:"error,omitempty"`
+}
+
+// EventHandler is called on every state transition of a task.
+// It is called concurrently from multiple workers.
+type EventHandler func(Event)
+
+// NewJSONLEventHandler writes events as json lines to w.
+func NewJSONLEventHandler(w io.Writer) EventHandler {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+
+	return func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		// events are best effort and must not fail a build
+		_ = enc.Encode(e)
+	}
+}
+
+// emit passes the current state of a task to the event handler.
+func (p *Playbook) emit(task *Status) {
+	if p.eventHandler == nil {
+		return
+	}
+
+	state := task.State()
+	e := Event{
+		Time:           time.Now(),
+		Task:           task.Name(),
+		State:          strings.ToLower(string(state)),
+		Cause:          task.RebuildCause(),
+		ArtifactSource: task.ArtifactSource(),
+	}
+
+	switch state {
+	case StateCompleted, StateNoRebuildRequired, StateFailed:
+		if hashIn, err := task.HashIn(); err == nil {
+			e.InputHash = hashIn.String()
+		}
+		fallthrough
+	case StateCanceled:
+		d := task.ExecutionTime().Milliseconds()
+		e.DurationMs = &d
+	}
+
+	if task.Error != nil {
+		e.Error = task.Error.Error()
+	}
+
+	p.eventHandler(e)
+}
@@ -9,22 +9,10 @@ func (p *Playbook) Next() (_ *Status, err error) {
 		return nil, ErrDone
 	}
 
-	// translate dependen tasks name to id's and store them in the task.
-	p.oncePrepareOptimizedAccess.Do(func() {
-		_ = p.Tasks.walk(p.root, func(taskname string, task *Status, _ error) error {
-			for _, dependentTaskName := range task.DependsOn {
-				t := p.Tasks[dependentTaskName]
-				task.DependsOnIDs = append(task.DependsOnIDs, t.TaskID)
-			}
-			return nil
-		})
-	})
+	p.preparePriorities()
 
 	// Walk the task chain and determine the next build task. Send it to the task channel.
-	// Returns `taskQueued` when a task has been send to the taskChannel.
 	// Returns `taskFailed` when a task has failed.
-	// Once it returns `nil` the playbook is done with it's work.
-	var taskQueued = fmt.Errorf("task queued")
 	var taskFailed = fmt.Errorf("task failed")
 
 	type result struct {
@@ -38,6 +26,12 @@ func (p *Playbook) Next() (_ *Status, err error) {
 	// from Next().
 	go func(output chan result) {
 		didAllTaskComplete := true
+		// stopped is set when a failed or canceled task was reported
+		stopped := false
+
+		// next is the ready task with the highest priority
+		var next *Status
+
 		_ = p.TasksOptimized.walkBottomFirst(p.rootID, func(taskID int, task *Status, err error) error {
 			if err != nil {
 				return err
@@ -58,10 +52,14 @@ func (p *Playbook) Next() (_ *Status, err error) {
 					}
 				}
 			case StateFailed:
+				stopped = true
 				output <- result{t: task, state: "failed"}
 				return taskFailed
 			case StateCanceled:
-				output <- result{t: task, state: "canceled"}
+				if !stopped {
+					output <- result{t: task, state: "canceled"}
+				}
+				stopped = true
 				return nil
 			case StateNoRebuildRequired:
 				return nil
@@ -76,13 +74,27 @@ func (p *Playbook) Next() (_ *Status, err error) {
 			default:
 			}
 
-			// TODO: for async assure to handle send to a closed channel.
-			_ = p.setTaskState(task.TaskID, StateQueued, nil)
-			output <- result{t: task, state: "queued"}
-			return taskQueued
+			// The task is ready but has to wait for resources
+			// occupied by running tasks.
+			if !p.resources.fits(task) {
+				return nil
+			}
+
+			// The task is ready, keep walking to find the
+			// ready task on the longest remaining chain.
+			if next == nil || p.priorities[taskID] > p.priorities[next.TaskID] {
+				next = task
+			}
+			return nil
 		})
 
-		if didAllTaskComplete {
+		switch {
+		case stopped:
+		case next != nil && p.resources.acquire(next):
+			// TODO: for async assure to handle send to a closed channel.
+			_ = p.setTaskState(next.TaskID, StateQueued, nil)
+			output <- result{t: next, state: "queued"}
+		case didAllTaskComplete:
 			output <- result{t: nil, state: "playbook-done"}
 		}
 		close(output)
@@ -105,3 +117,17 @@ func (p *Playbook) Next() (_ *Status, err error) {
 	return nil, nil
 
 }
+
+// prepareOptimizedAccess translates dependent task names
+// to id's and stores them in the task.
+func (p *Playbook) prepareOptimizedAccess() {
+	p.oncePrepareOptimizedAccess.Do(func() {
+		_ = p.Tasks.walk(p.root, func(taskname string, task *Status, _ error) error {
+			for _, dependentTaskName := range task.DependsOn {
+				t := p.Tasks[dependentTaskName]
+				task.DependsOnIDs = append(task.DependsOnIDs, t.TaskID)
+			}
+			return nil
+		})
+	})
+}
@@ -1,6 +1,7 @@
 package playbook
 
 import (
+	"github.com/benchkram/bob/pkg/buildinfostore"
 	"github.com/benchkram/bob/pkg/store"
 )
 
@@ -47,3 +48,56 @@ func WithLocalStore(s store.Store) Option {
 		p.localStore = s
 	}
 }
+
+func WithEventHandler(h EventHandler) Option {
+	return func(p *Playbook) {
+		p.eventHandler = h
+	}
+}
+
+// WithTraceFile writes a chrome trace of the build to path.
+func WithTraceFile(path string) Option {
+	return func(p *Playbook) {
+		p.traceFile = path
+	}
+}
+
+// WithJUnitFile writes a junit report of the build to path.
+func WithJUnitFile(path string) Option {
+	return func(p *Playbook) {
+		p.junitFile = path
+	}
+}
+
+// WithDurationStore reads and records task durations
+// to schedule long running chains of tasks first.
+func WithDurationStore(s buildinfostore.DurationStore) Option {
+	return func(p *Playbook) {
+		p.durationStore = s
+	}
+}
+
+// WithSucceededTasks records the tasks completing successfully, shared by
+// the builds of a watch session. Tasks which succeeded before and only depend
+// on such tasks are considered up to date and are neither hashed nor verified.
+func WithSucceededTasks(s *Succeeded) Option {
+	return func(p *Playbook) {
+		p.succeeded = s
+	}
+}
+
+// WithPools limits the number of parallel tasks per pool.
+func WithPools(pools map[string]int) Option {
+	return func(p *Playbook) {
+		p.pools = pools
+	}
+}
+
+// WithResourceCapacity overrides the cpu cores and memory
+// in bytes available to tasks. Zero values are detected.
+func WithResourceCapacity(cpu int, memory uint64) Option {
+	return func(p *Playbook) {
+		p.cpuCapacity = cpu
+		p.memoryCapacity = memory
+	}
+}
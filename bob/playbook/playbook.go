@@ -12,6 +12,7 @@ import (
 	"github.com/benchkram/bob/bobtask/buildinfo"
 	"github.com/benchkram/bob/bobtask/hash"
 	"github.com/benchkram/bob/pkg/boberror"
+	"github.com/benchkram/bob/pkg/buildinfostore"
 	"github.com/benchkram/bob/pkg/store"
 	"github.com/benchkram/bob/pkg/usererror"
 	"github.com/benchkram/errz"
@@ -72,9 +73,40 @@ type Playbook struct {
 	// enablePull allows pulling artifacts from remote store
 	enablePull bool
 
+	// eventHandler is notified about task state transitions
+	eventHandler EventHandler
+
+	// traceFile is the path the chrome trace is written to after a build
+	traceFile string
+	// junitFile is the path the junit report is written to after a build
+	junitFile string
+
 	// oncePrepareOptimizedAccess is used to initalize the optimized
 	// slice to access tasks.
 	oncePrepareOptimizedAccess sync.Once
+
+	// durationStore provides historical task durations
+	// used to prioritise the critical path.
+	durationStore buildinfostore.DurationStore
+	// durations are the historical durations by task name
+	durations map[string]time.Duration
+	// priorities of tasks indexed by task id, see preparePriorities()
+	priorities            []time.Duration
+	oncePreparePriorities sync.Once
+
+	// unchanged is indexed by task id
+	unchanged []bool
+	// succeeded records successful tasks across builds,
+	// see WithSucceededTasks().
+	succeeded *Succeeded
+
+	// pools limit the number of parallel tasks referencing a pool
+	pools map[string]int
+	// cpuCapacity and memoryCapacity are shared by running tasks,
+	// detected from the host when not set through options.
+	cpuCapacity    int
+	memoryCapacity uint64
+	resources      *resources
 }
 
 func New(root string, rootID int, opts ...Option) *Playbook {
@@ -99,6 +131,14 @@ func New(root string, rootID int, opts ...Option) *Playbook {
 		opt(p)
 	}
 
+	if p.cpuCapacity == 0 {
+		p.cpuCapacity = defaultCPUCapacity(p.maxParallel)
+	}
+	if p.memoryCapacity == 0 {
+		p.memoryCapacity = totalMemory()
+	}
+	p.resources = newResources(p.cpuCapacity, p.memoryCapacity, p.pools)
+
 	// Try to make the task channel the same size as the number of tasks.
 	// (Matthias) There was a reason why this was neccessary, probably it's related
 	// to beeing able to shutdown the playbook correctly? Unsure!
@@ -249,6 +289,9 @@ func (p *Playbook) setTaskState(taskID int, state State, taskError error) error
 	case StateCompleted, StateCanceled, StateNoRebuildRequired, StateFailed:
 		task.SetEnd(time.Now())
 	}
+	p.recordSucceeded(taskID, state)
+
+	p.emit(task)
 
 	return nil
 }
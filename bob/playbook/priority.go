@@ -0,0 +1,107 @@
+package playbook
+
+import (
+	"time"
+)
+
+// preparePriorities weights each task by the longest chain of tasks
+// depending on it, the task itself included. Chains are weighted by
+// historical durations, tasks without history count as the average
+// known duration. Without any history the priority is the distance
+// to the root task.
+//
+// Starting ready tasks with the highest priority first prevents long
+// chains deep in the graph from starting late.
+func (p *Playbook) preparePriorities() {
+	p.oncePreparePriorities.Do(func() {
+		p.prepareOptimizedAccess()
+
+		fallback := time.Millisecond
+		if len(p.durations) > 0 {
+			var sum time.Duration
+			for _, d := range p.durations {
+				sum += d
+			}
+			fallback = sum / time.Duration(len(p.durations))
+		}
+
+		dependents := p.dependents()
+
+		p.priorities = make([]time.Duration, len(p.TasksOptimized))
+		visited := make([]bool, len(p.TasksOptimized))
+
+		var priority func(id int) time.Duration
+		priority = func(id int) time.Duration {
+			if visited[id] {
+				return p.priorities[id]
+			}
+			visited[id] = true
+
+			weight, ok := p.durations[p.TasksOptimized[id].Name()]
+			if !ok || weight <= 0 {
+				weight = fallback
+			}
+
+			var longest time.Duration
+			for _, dependent := range dependents[id] {
+				if d := priority(dependent); d > longest {
+					longest = d
+				}
+			}
+
+			p.priorities[id] = weight + longest
+			return p.priorities[id]
+		}
+
+		for id := range p.TasksOptimized {
+			priority(id)
+		}
+	})
+}
+
+// dependents returns the reversed edges of DependsOnIDs indexed by task id.
+func (p *Playbook) dependents() [][]int {
+	p.prepareOptimizedAccess()
+
+	dependents := make([][]int, len(p.TasksOptimized))
+	for _, t := range p.TasksOptimized {
+		for _, id := range t.DependsOnIDs {
+			dependents[id] = append(dependents[id], t.TaskID)
+		}
+	}
+	return dependents
+}
+
+// loadDurations reads historical task durations from the duration store.
+func (p *Playbook) loadDurations() error {
+	if p.durationStore == nil {
+		return nil
+	}
+
+	durations, err := p.durationStore.Durations()
+	if err != nil {
+		return err
+	}
+	p.durations = durations
+	return nil
+}
+
+// storeDurations records the duration of tasks which ran.
+func (p *Playbook) storeDurations() error {
+	if p.durationStore == nil {
+		return nil
+	}
+
+	durations := make(map[string]time.Duration)
+	for _, t := range p.TasksOptimized {
+		if t.State() != StateCompleted {
+			continue
+		}
+		durations[t.Name()] = t.ExecutionTime()
+	}
+	if len(durations) == 0 {
+		return nil
+	}
+
+	return p.durationStore.UpdateDurations(durations)
+}
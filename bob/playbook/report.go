@@ -0,0 +1,228 @@
+package playbook
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/benchkram/errz"
+)
+
+// finishedTasks returns tasks processed by a worker ordered by their start time.
+func (p *Playbook) finishedTasks() []*Status {
+	tasks := []*Status{}
+	for _, t := range p.TasksOptimized {
+		if t.End().IsZero() {
+			continue
+		}
+		tasks = append(tasks, t)
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Start().Before(tasks[j].Start())
+	})
+	return tasks
+}
+
+type traceEvent struct {
+	Name string                 `json:"name"`
+	Cat  string                 `json:"cat,omitempty"`
+	Ph   string                 `json:"ph"`
+	Ts   int64                  `json:"ts"`
+	Dur  int64                  `json:"dur,omitempty"`
+	Pid  int                    `json:"pid"`
+	Tid  int                    `json:"tid"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+type trace struct {
+	TraceEvents     []traceEvent `json:"traceEvents"`
+	DisplayTimeUnit string       `json:"displayTimeUnit"`
+}
+
+// WriteChromeTrace writes the task timings in chrome trace event format
+// to w, using one lane per worker. Timestamps are relative to the start
+// of the playbook. Load the output in chrome://tracing or ui.perfetto.dev.
+func (p *Playbook) WriteChromeTrace(w io.Writer) error {
+	t := trace{
+		TraceEvents:     []traceEvent{},
+		DisplayTimeUnit: "ms",
+	}
+
+	t.TraceEvents = append(t.TraceEvents, traceEvent{
+		Name: "process_name",
+		Ph:   "M",
+		Args: map[string]interface{}{"name": "bob build " + p.root},
+	})
+
+	workers := map[int]bool{}
+	for _, task := range p.finishedTasks() {
+		worker := task.Worker()
+		if !workers[worker] {
+			workers[worker] = true
+			t.TraceEvents = append(t.TraceEvents, traceEvent{
+				Name: "thread_name",
+				Ph:   "M",
+				Tid:  worker,
+				Args: map[string]interface{}{"name": fmt.Sprintf("worker %d", worker)},
+			})
+		}
+
+		state := task.State()
+		args := map[string]interface{}{
+			"state": strings.ToLower(string(state)),
+		}
+		if cause := task.RebuildCause(); cause != "" {
+			args["cause"] = cause
+		}
+		if source := task.ArtifactSource(); source != ArtifactSourceNone {
+			args["artifact_source"] = source
+		}
+		if hashIn, err := task.HashIn(); err == nil {
+			args["input_hash"] = hashIn.String()
+		}
+		if task.Error != nil {
+			args["error"] = task.Error.Error()
+		}
+
+		t.TraceEvents = append(t.TraceEvents, traceEvent{
+			Name: task.Name(),
+			Cat:  strings.ToLower(string(state)),
+			Ph:   "X",
+			Ts:   task.Start().Sub(p.start).Microseconds(),
+			Dur:  task.ExecutionTime().Microseconds(),
+			Tid:  worker,
+			Args: args,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t)
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes a JUnit XML report to w, every task is reported as
+// a test case. Cached tasks pass, tasks which never ran are skipped.
+// The output of failed tasks is attached to the failure.
+func (p *Playbook) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:      p.root,
+		Time:      seconds(p.ExecutionTime()),
+		Timestamp: p.start.Format(time.RFC3339),
+		Cases:     []junitTestCase{},
+	}
+
+	tasks := make([]*Status, len(p.TasksOptimized))
+	copy(tasks, p.TasksOptimized)
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Name() < tasks[j].Name()
+	})
+
+	for _, task := range tasks {
+		c := junitTestCase{
+			Name:      task.Name(),
+			Classname: "bob.build",
+		}
+
+		switch state := task.State(); state {
+		case StateCompleted, StateNoRebuildRequired:
+			c.Time = seconds(task.ExecutionTime())
+		case StateFailed:
+			c.Time = seconds(task.ExecutionTime())
+			c.Failure = &junitMessage{
+				Message: "task failed",
+				Text:    strings.Join(task.Output(), "\n"),
+			}
+			if task.Error != nil {
+				c.Failure.Message = task.Error.Error()
+			}
+			suite.Failures++
+		default:
+			c.Time = seconds(0)
+			c.Skipped = &junitMessage{Message: strings.ToLower(string(state))}
+			suite.Skipped++
+		}
+
+		suite.Cases = append(suite.Cases, c)
+	}
+	suite.Tests = len(suite.Cases)
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	err = enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// writeReports writes the reports requested through options.
+func (p *Playbook) writeReports() (err error) {
+	defer errz.Recover(&err)
+
+	if p.traceFile != "" {
+		err = writeFile(p.traceFile, p.WriteChromeTrace)
+		errz.Fatal(err)
+	}
+	if p.junitFile != "" {
+		err = writeFile(p.junitFile, p.WriteJUnit)
+		errz.Fatal(err)
+	}
+
+	return nil
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = write(f)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,155 @@
+package playbook
+
+import (
+	"bufio"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// resources tracks cpu, memory and pool slots occupied by running tasks.
+// Requests exceeding the capacity are clamped, so a task always
+// fits once nothing else is running.
+type resources struct {
+	mu sync.Mutex
+
+	cpu    int
+	memory uint64 // 0 means unlimited
+	pools  map[string]int
+
+	usedCPU    int
+	usedMemory uint64
+	usedPools  map[string]int
+
+	// acquired holds the resources of running tasks by task id
+	acquired map[int]request
+}
+
+type request struct {
+	cpu    int
+	memory uint64
+	pool   string
+}
+
+func newResources(cpu int, memory uint64, pools map[string]int) *resources {
+	if cpu < 1 {
+		cpu = 1
+	}
+	if pools == nil {
+		pools = map[string]int{}
+	}
+	return &resources{
+		cpu:       cpu,
+		memory:    memory,
+		pools:     pools,
+		usedPools: map[string]int{},
+		acquired:  map[int]request{},
+	}
+}
+
+func (r *resources) request(t *Status) request {
+	req := request{
+		cpu:    t.CPU(),
+		memory: t.Memory(),
+		pool:   t.Pool,
+	}
+	if req.cpu > r.cpu {
+		req.cpu = r.cpu
+	}
+	if r.memory == 0 || req.memory > r.memory {
+		req.memory = r.memory
+	}
+	return req
+}
+
+// fits returns true when the task can be started right now.
+func (r *resources) fits(t *Status) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.fitsLocked(r.request(t))
+}
+
+func (r *resources) fitsLocked(req request) bool {
+	if r.usedCPU+req.cpu > r.cpu {
+		return false
+	}
+	if r.memory > 0 && r.usedMemory+req.memory > r.memory {
+		return false
+	}
+	if limit, ok := r.pools[req.pool]; ok && r.usedPools[req.pool] >= limit {
+		return false
+	}
+	return true
+}
+
+// acquire occupies the resources of the task,
+// returns false if they are not available.
+func (r *resources) acquire(t *Status) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	req := r.request(t)
+	if !r.fitsLocked(req) {
+		return false
+	}
+
+	r.usedCPU += req.cpu
+	r.usedMemory += req.memory
+	if req.pool != "" {
+		r.usedPools[req.pool]++
+	}
+	r.acquired[t.TaskID] = req
+	return true
+}
+
+// release frees the resources occupied by the task.
+func (r *resources) release(t *Status) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	req, ok := r.acquired[t.TaskID]
+	if !ok {
+		return
+	}
+	delete(r.acquired, t.TaskID)
+
+	r.usedCPU -= req.cpu
+	r.usedMemory -= req.memory
+	if req.pool != "" {
+		r.usedPools[req.pool]--
+	}
+}
+
+// defaultCPUCapacity allows at least maxParallel single core tasks.
+func defaultCPUCapacity(maxParallel int) int {
+	if n := runtime.NumCPU(); n > maxParallel {
+		return n
+	}
+	return maxParallel
+}
+
+// totalMemory reads the physical memory from /proc/meminfo.
+// Returns 0 (unlimited) when it can't be determined.
+func totalMemory() uint64 {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemTotal:" {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0
+		}
+		return kb * 1024
+	}
+	return 0
+}
@@ -0,0 +1,45 @@
+package playbook
+
+import (
+	"testing"
+
+	"github.com/benchkram/bob/bobtask"
+	"github.com/stretchr/testify/assert"
+)
+
+func newResourceTask(id int, cpu int, pool string) *Status {
+	return NewStatus(&bobtask.Task{
+		TaskID:         id,
+		ResourcesDirty: bobtask.Resources{CPU: cpu},
+		Pool:           pool,
+	})
+}
+
+func TestResources(t *testing.T) {
+	r := newResources(4, 0, map[string]int{"docker": 1})
+
+	big := newResourceTask(0, 3, "")
+	small := newResourceTask(1, 1, "")
+	other := newResourceTask(2, 1, "")
+	assert.True(t, r.acquire(big))
+	assert.True(t, r.acquire(small))
+	assert.False(t, r.fits(other))
+
+	r.release(small)
+	assert.True(t, r.fits(other))
+	r.release(big)
+
+	// pool slots are limited independent of cpu
+	docker1 := newResourceTask(3, 1, "docker")
+	docker2 := newResourceTask(4, 1, "docker")
+	assert.True(t, r.acquire(docker1))
+	assert.False(t, r.acquire(docker2))
+	r.release(docker1)
+	assert.True(t, r.acquire(docker2))
+	r.release(docker2)
+
+	// requests exceeding the capacity are clamped
+	huge := newResourceTask(5, 64, "")
+	assert.True(t, r.acquire(huge))
+	assert.False(t, r.fits(small))
+}
@@ -21,6 +21,14 @@ type Status struct {
 	end     time.Time
 
 	Error error
+
+	// cause is the reason a rebuild was required
+	cause RebuildCause
+	// artifactSource is set when the targets were restored from an artifact
+	artifactSource ArtifactSource
+
+	// worker is the id of the worker which processed the task
+	worker int
 }
 
 func NewStatus(task *bobtask.Task) *Status {
@@ -45,6 +53,42 @@ func (ts *Status) SetState(s State, err error) {
 	ts.stateMu.Unlock()
 }
 
+func (ts *Status) RebuildCause() RebuildCause {
+	ts.stateMu.RLock()
+	defer ts.stateMu.RUnlock()
+	return ts.cause
+}
+
+func (ts *Status) SetRebuildCause(cause RebuildCause) {
+	ts.stateMu.Lock()
+	defer ts.stateMu.Unlock()
+	ts.cause = cause
+}
+
+func (ts *Status) ArtifactSource() ArtifactSource {
+	ts.stateMu.RLock()
+	defer ts.stateMu.RUnlock()
+	return ts.artifactSource
+}
+
+func (ts *Status) SetArtifactSource(source ArtifactSource) {
+	ts.stateMu.Lock()
+	defer ts.stateMu.Unlock()
+	ts.artifactSource = source
+}
+
+func (ts *Status) Worker() int {
+	ts.stateMu.RLock()
+	defer ts.stateMu.RUnlock()
+	return ts.worker
+}
+
+func (ts *Status) SetWorker(worker int) {
+	ts.stateMu.Lock()
+	defer ts.stateMu.Unlock()
+	ts.worker = worker
+}
+
 func (ts *Status) ExecutionTime() time.Duration {
 	ts.startMu.RLock()
 	ts.endMu.RLock()
@@ -21,7 +21,7 @@ func (p *Playbook) summary(processedTasks []*processed.Task) {
 	for _, t := range processedTasks {
 		stat, err := p.TaskStatus(t.Name())
 		if err != nil {
-			fmt.Println(err)
+			boblog.Log.Info(err.Error())
 			continue
 		}
 
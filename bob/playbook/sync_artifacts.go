@@ -16,8 +16,13 @@ import (
 // TaskKey is key for context values passed to client for upload/download output formatting
 type TaskKey string
 
+// canPull reports if artifacts can be pulled from the remote store.
+func (p *Playbook) canPull() bool {
+	return p.enablePull && p.enableCaching && p.remoteStore != nil && p.localStore != nil
+}
+
 func (p *Playbook) pullArtifact(ctx context.Context, a hash.In, task *bobtask.Task, ignoreLocal bool) error {
-	if !(p.enablePull && p.enableCaching && p.remoteStore != nil && p.localStore != nil) {
+	if !p.canPull() {
 		return nil
 	}
 
@@ -39,7 +44,7 @@ func (p *Playbook) pushArtifact(ctx context.Context, a hash.In, taskName string)
 // pull syncs the artifact from the remote store to the local store.
 // if ignoreAlreadyExists is true it will ignore local artifact and perform a fresh download
 func pull(ctx context.Context, remote store.Store, local store.Store, a hash.In, namePad int, task *bobtask.Task, ignoreAlreadyExists bool) error {
-	err := store.Sync(ctx, remote, local, a.String(), ignoreAlreadyExists)
+	err := bobtask.ArtifactSync(ctx, remote, local, a.String(), ignoreAlreadyExists)
 	if errors.Is(err, store.ErrArtifactAlreadyExists) {
 		boblog.Log.V(5).Info(fmt.Sprintf("artifact already exists locally [artifactId: %s]. skipping...", a.String()))
 	} else if errors.Is(err, store.ErrArtifactNotFoundinSrc) {
@@ -47,11 +52,11 @@ func pull(ctx context.Context, remote store.Store, local store.Store, a hash.In,
 	} else if errors.Is(err, context.Canceled) {
 		return usererror.Wrap(err)
 	} else if err != nil {
-		fmt.Printf("%-*s\t%s\n",
+		boblog.Log.Info(fmt.Sprintf("%-*s\t%s",
 			namePad,
 			task.ColoredName(),
 			aurora.Red(fmt.Errorf("failed pull [artifactId: %s]: %w", a.String(), err)),
-		)
+		))
 	}
 
 	boblog.Log.V(5).Info(fmt.Sprintf("pull succeeded [artifactId: %s]", a.String()))
@@ -60,7 +65,7 @@ func pull(ctx context.Context, remote store.Store, local store.Store, a hash.In,
 
 // push syncs the artifact from the local store to the remote store.
 func push(ctx context.Context, local store.Store, remote store.Store, a hash.In, taskName string, namePad int) error {
-	err := store.Sync(ctx, local, remote, a.String(), false)
+	err := bobtask.ArtifactSync(ctx, local, remote, a.String(), false)
 	if errors.Is(err, store.ErrArtifactAlreadyExists) {
 		boblog.Log.V(5).Info(fmt.Sprintf("artifact already exists on the remote [artifactId: %s]. skipping...", a.String()))
 		return nil
@@ -94,6 +94,7 @@ func (p *Playbook) startWorkers(ctx context.Context, workers int) *workerManager
 
 			for t := range queue {
 				t.SetStart(time.Now())
+				t.SetWorker(workerID)
 				_ = p.setTaskState(t.Task.TaskID, StateRunning, nil)
 
 				// check if a shutdown is required.
@@ -110,6 +111,9 @@ func (p *Playbook) startWorkers(ctx context.Context, workers int) *workerManager
 				}
 				wm.addProcessedTask(processedTask)
 
+				// free resources before asking for more workload
+				p.resources.release(t)
+
 				// done with processing. signal availability.
 				wm.idleChan <- workerID
 			}
@@ -3,11 +3,14 @@ package bob
 import (
 	"context"
 	"errors"
+	"path/filepath"
 
 	"github.com/benchkram/errz"
 
 	"github.com/benchkram/bob/bob/bobfile"
+	"github.com/benchkram/bob/bob/global"
 	nixbuilder "github.com/benchkram/bob/bob/nix-builder"
+	"github.com/benchkram/bob/bob/playbook"
 	"github.com/benchkram/bob/pkg/boberror"
 	"github.com/benchkram/bob/pkg/ctl"
 	"github.com/benchkram/bob/pkg/sliceutil"
@@ -50,15 +53,45 @@ func (b *B) Run(ctx context.Context, runTaskName string) (_ ctl.Commander, err e
 	interactiveTasks := []string{runTask.Name()}
 	interactiveTasks = append(interactiveTasks, childInteractiveTasks...)
 
+	// watch the build dependencies from the start, so changes during the
+	// initial build restart the commands. succeeded lets rebuilds on
+	// changes skip up to date tasks.
+	var succeeded *playbook.Succeeded
+	var w *watcher
+	watching := len(affectedRunTasks(aggregate, interactiveTasks, nil)) > 0
+	if watching {
+		workspace, err := filepath.Abs(".")
+		errz.Fatal(err)
+
+		succeeded = playbook.NewSucceeded()
+		w, err = newWatcher(ctx, workspace, succeeded)
+		errz.Fatal(err)
+		w.setIndex(newWatchIndex(aggregate, workspace, buildTasksOf(aggregate, interactiveTasks)...))
+	}
+
+	build := func(ctx context.Context, runTaskName string, aggregate *bobfile.Bobfile, nix *nixbuilder.NB) error {
+		return executeBuildTasksInPipeline(ctx, runTaskName, aggregate, nix,
+			playbook.WithEventHandler(b.eventHandler),
+			playbook.WithDurationStore(b.durationStore),
+			playbook.WithSucceededTasks(succeeded),
+		)
+	}
+
 	for _, task := range interactiveTasks {
-		err = executeBuildTasksInPipeline(ctx, task, aggregate, b.nix)
+		err = build(ctx, task, aggregate, b.nix)
 		errz.Fatal(err)
 	}
 
+	// resolve port conflicts between run tasks
+	// and pass the ports to their dependents.
+	err = aggregate.RTasks.ResolvePorts(interactiveTasks)
+	errz.Fatal(err)
+
 	// generate run controls to steer the run cmd.
 	runCommands := []ctl.Command{}
 	for _, name := range interactiveTasks {
 		runTask := aggregate.RTasks[name]
+		runTask.SetLogDir(filepath.Join(b.dir, global.BobLogsDir))
 
 		command, err := runTask.Command(ctx)
 		errz.Fatal(err)
@@ -66,9 +99,14 @@ func (b *B) Run(ctx context.Context, runTaskName string) (_ ctl.Commander, err e
 		runCommands = append(runCommands, command)
 	}
 
-	builder := NewBuilder(runTaskName, aggregate, executeBuildTasksInPipeline, b.nix)
+	builder := NewBuilder(runTaskName, aggregate, build, b.nix)
 	commander := ctl.NewCommander(ctx, builder, runCommands...)
 
+	// restart commands when their build dependencies change
+	if watching {
+		return newWatchingCommander(ctx, b, commander, interactiveTasks, w), nil
+	}
+
 	return commander, nil
 }
 
@@ -142,6 +180,7 @@ func executeBuildTasksInPipeline(
 	runTaskName string,
 	aggregate *bobfile.Bobfile,
 	nix *nixbuilder.NB,
+	opts ...playbook.Option,
 ) (err error) {
 	defer errz.Recover(&err)
 
@@ -168,13 +207,28 @@ func executeBuildTasksInPipeline(
 	}
 	buildTasks = sliceutil.Unique(buildTasks)
 
+	return executeBuildTasks(ctx, buildTasks, append(runTasksInPipeline, runTaskName), aggregate, nix, opts...)
+}
+
+// executeBuildTasks builds the build tasks run tasks depend on,
+// each build task is built by its own playbook.
+func executeBuildTasks(
+	ctx context.Context,
+	buildTasks []string,
+	runTasks []string,
+	aggregate *bobfile.Bobfile,
+	nix *nixbuilder.NB,
+	opts ...playbook.Option,
+) (err error) {
+	defer errz.Recover(&err)
+
 	// Build nix dependencies
-	err = nix.BuildNixDependencies(aggregate, buildTasks, append(runTasksInPipeline, runTaskName))
+	err = nix.BuildNixDependencies(aggregate, buildTasks, runTasks)
 	errz.Fatal(err)
 
 	// Initiate each build
 	for _, buildTask := range buildTasks {
-		playbook, err := aggregate.Playbook(buildTask)
+		playbook, err := aggregate.Playbook(buildTask, opts...)
 		if err != nil {
 			if errors.Is(err, boberror.ErrTaskDoesNotExist) {
 				continue
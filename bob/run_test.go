@@ -5,6 +5,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/benchkram/bob/bob/bobfile"
+	"github.com/benchkram/bob/bobrun"
+	"github.com/benchkram/bob/bobtask"
 )
 
 func TestNormalize(t *testing.T) {
@@ -46,3 +50,33 @@ func TestNormalize(t *testing.T) {
 		assert.True(t, reflect.DeepEqual(test.expected, result))
 	}
 }
+
+func TestAffectedRunTasks(t *testing.T) {
+	ag := bobfile.NewBobfile()
+	ag.BTasks["generate"] = bobtask.Task{}
+	ag.BTasks["server"] = bobtask.Task{DependsOn: []string{"generate"}}
+	ag.BTasks["migrations"] = bobtask.Task{}
+	ag.RTasks["app"] = &bobrun.Run{DependsOn: []string{"server", "database"}}
+	ag.RTasks["database"] = &bobrun.Run{DependsOn: []string{"migrations"}}
+	ag.RTasks["proxy"] = &bobrun.Run{}
+
+	runTasks := []string{"app", "database", "proxy"}
+
+	assert.Equal(t, []string{"app"}, affectedRunTasks(ag, runTasks, []string{"generate"}))
+	assert.Equal(t, []string{"database"}, affectedRunTasks(ag, runTasks, []string{"migrations"}))
+	assert.Empty(t, affectedRunTasks(ag, runTasks, []string{"unknown"}))
+	assert.Equal(t, []string{"app", "database"}, affectedRunTasks(ag, runTasks, nil))
+}
+
+func TestBuildTasksOf(t *testing.T) {
+	ag := bobfile.NewBobfile()
+	ag.BTasks["server"] = bobtask.Task{}
+	ag.BTasks["assets"] = bobtask.Task{}
+	ag.RTasks["app"] = &bobrun.Run{DependsOn: []string{"server", "assets", "database"}}
+	ag.RTasks["worker"] = &bobrun.Run{DependsOn: []string{"server"}}
+	ag.RTasks["database"] = &bobrun.Run{}
+
+	// shared build tasks are built once for all affected run tasks
+	assert.Equal(t, []string{"server", "assets"}, buildTasksOf(ag, []string{"app", "worker", "database"}))
+	assert.Empty(t, buildTasksOf(ag, []string{"database", "unknown"}))
+}
@@ -0,0 +1,203 @@
+package bob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/benchkram/bob/bob/bobfile"
+	"github.com/benchkram/bob/bob/playbook"
+	"github.com/benchkram/bob/bobtask"
+	"github.com/benchkram/bob/pkg/boblog"
+	"github.com/benchkram/bob/pkg/ctl"
+	"github.com/benchkram/bob/pkg/sliceutil"
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+var _ ctl.Rebuilder = (*watchingCommander)(nil)
+
+// watchingCommander watches the inputs of the build tasks run tasks depend
+// on. On a change the build tasks are rebuilt and the commands of the
+// affected run tasks are restarted.
+type watchingCommander struct {
+	ctl.Commander
+
+	b   *B
+	ctx context.Context
+
+	// runTasks are the run tasks controlled by the commander
+	runTasks []string
+
+	// commands by run task name
+	commands map[string]ctl.Command
+
+	// w watches the build tasks since before the initial build
+	w *watcher
+
+	// mu serializes rebuilds and restarts
+	mu sync.Mutex
+
+	once     sync.Once
+	rebuilds chan ctl.Rebuild
+}
+
+func newWatchingCommander(ctx context.Context, b *B, commander ctl.Commander, runTasks []string, w *watcher) *watchingCommander {
+	c := &watchingCommander{
+		Commander: commander,
+		b:         b,
+		ctx:       ctx,
+		runTasks:  runTasks,
+		commands:  map[string]ctl.Command{},
+		w:         w,
+		rebuilds:  make(chan ctl.Rebuild, 16),
+	}
+	for _, command := range commander.Subcommands() {
+		c.commands[command.Name()] = command
+	}
+	return c
+}
+
+// Start the commander, watching starts after the first start.
+func (c *watchingCommander) Start() error {
+	c.mu.Lock()
+	err := c.Commander.Start()
+	c.mu.Unlock()
+
+	c.once.Do(func() {
+		go func() {
+			roots := func(ag *bobfile.Bobfile) []string { return buildTasksOf(ag, c.runTasks) }
+			err := c.b.watch(c.ctx, c.w, roots, c.rebuild)
+			if err != nil {
+				boblog.Log.Error(err, "Stopped watching build dependencies")
+			}
+		}()
+	})
+
+	return err
+}
+
+func (c *watchingCommander) Restart() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Commander.Restart()
+}
+
+func (c *watchingCommander) Rebuilds() <-chan ctl.Rebuild {
+	return c.rebuilds
+}
+
+// buildTasksOf returns the build tasks run tasks depend on directly.
+func buildTasksOf(ag *bobfile.Bobfile, runTasks []string) []string {
+	var tasks []string
+	for _, runTask := range runTasks {
+		buildTasks, err := gatherBuildTasks(runTask, ag)
+		if err != nil {
+			continue
+		}
+		tasks = append(tasks, buildTasks...)
+	}
+	return sliceutil.Unique(tasks)
+}
+
+// rebuild the changed build tasks and restart the commands of
+// run tasks depending on them.
+func (c *watchingCommander) rebuild(ag *bobfile.Bobfile, changed []string, reload bool) (err error) {
+	if changed == nil && !reload {
+		// initial call, the commander built everything on start
+		return nil
+	}
+
+	affected := affectedRunTasks(ag, c.runTasks, changed)
+	if len(affected) == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.emit(ctl.Rebuild{Commands: affected, State: ctl.RebuildStarted})
+
+	// build tasks shared by the affected run tasks are built once,
+	// a task built for one is up to date for the others.
+	err = executeBuildTasks(c.ctx, buildTasksOf(ag, affected), affected, ag, c.b.nix,
+		playbook.WithEventHandler(c.b.eventHandler),
+		playbook.WithDurationStore(c.b.durationStore),
+		playbook.WithSucceededTasks(c.w.succeeded),
+	)
+	if err != nil {
+		c.emit(ctl.Rebuild{Commands: affected, State: ctl.RebuildFailed, Err: err})
+		return err
+	}
+
+	for _, runTask := range affected {
+		command, ok := c.commands[runTask]
+		if !ok {
+			continue
+		}
+		boblog.Log.Info(fmt.Sprintf("Restarting %s", runTask))
+		err = command.Restart()
+		if err != nil {
+			c.emit(ctl.Rebuild{Commands: affected, State: ctl.RebuildFailed, Err: err})
+			if errors.As(err, &usererror.Err) {
+				return err
+			}
+			return usererror.Wrapm(err, fmt.Sprintf("failed to restart %s", runTask))
+		}
+	}
+
+	c.emit(ctl.Rebuild{Commands: affected, State: ctl.RebuildRestarted})
+	boblog.Log.Info(fmt.Sprintf("Restarted %s", strings.Join(affected, ", ")))
+
+	return nil
+}
+
+// emit drops updates nobody listens to.
+func (c *watchingCommander) emit(r ctl.Rebuild) {
+	select {
+	case c.rebuilds <- r:
+	default:
+	}
+}
+
+// affectedRunTasks returns the run tasks depending on one of the changed
+// build tasks, directly or through other build tasks. All run tasks
+// depending on a build task are affected when changed is nil.
+// Only build dependencies are considered, a run task isn't affected
+// by changes of another run task it depends on.
+func affectedRunTasks(ag *bobfile.Bobfile, runTasks []string, changed []string) []string {
+	isChanged := map[string]bool{}
+	for _, tn := range changed {
+		isChanged[tn] = true
+	}
+
+	var affected []string
+	for _, runTask := range runTasks {
+		buildTasks, err := gatherBuildTasks(runTask, ag)
+		if err != nil || len(buildTasks) == 0 {
+			continue
+		}
+		if changed == nil {
+			affected = append(affected, runTask)
+			continue
+		}
+
+		found := false
+		for _, buildTask := range buildTasks {
+			_ = ag.BTasks.Walk(buildTask, "", func(tn string, _ bobtask.Task, err error) error {
+				if err != nil {
+					return err
+				}
+				if isChanged[tn] {
+					found = true
+				}
+				return nil
+			})
+		}
+		if found {
+			affected = append(affected, runTask)
+		}
+	}
+	return affected
+}
@@ -0,0 +1,90 @@
+package bob
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/benchkram/errz"
+
+	"github.com/benchkram/bob/pkg/boberror"
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+// TraceInputsResult compares the files read by a traced
+// run of a task against its declared inputs.
+type TraceInputsResult struct {
+	Task string
+
+	// Dir of the task relative to the workspace root
+	Dir string
+
+	// Suggested inputs relative to the task's directory
+	Suggested []string
+
+	// Missing are read but not declared as input,
+	// Unused are declared but not read.
+	// Paths are relative to the workspace root.
+	Missing []string
+	Unused  []string
+}
+
+// TraceInputs builds the dependencies of a task and runs the task
+// once while recording the files it reads.
+func (b *B) TraceInputs(ctx context.Context, taskName string) (_ *TraceInputsResult, err error) {
+	defer errz.Recover(&err)
+
+	ag, err := b.Aggregate()
+	errz.Fatal(err)
+
+	task, ok := ag.BTasks[taskName]
+	if !ok {
+		return nil, usererror.Wrap(boberror.ErrTaskDoesNotExistF(taskName))
+	}
+
+	// dependencies must exist before the task can run
+	for _, dependency := range task.DependsOn {
+		err = b.Build(ctx, dependency)
+		errz.Fatal(err)
+	}
+
+	ag, err = b.AggregateWithNixDeps(taskName)
+	errz.Fatal(err)
+	task = ag.BTasks[taskName]
+
+	trace, err := task.RunTraced(ctx, len(taskName))
+	errz.Fatal(err)
+
+	result := &TraceInputsResult{
+		Task: taskName,
+		Dir:  task.Dir(),
+	}
+
+	declared := map[string]bool{}
+	for _, input := range task.Inputs() {
+		declared[filepath.Clean(input)] = true
+	}
+	read := map[string]bool{}
+	for _, path := range trace.Read {
+		read[path] = true
+		if !declared[path] {
+			result.Missing = append(result.Missing, path)
+		}
+
+		rel, err := filepath.Rel(task.Dir(), path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			// inputs are limited to the task's directory
+			continue
+		}
+		result.Suggested = append(result.Suggested, rel)
+	}
+	for input := range declared {
+		if !read[input] {
+			result.Unused = append(result.Unused, input)
+		}
+	}
+	sort.Strings(result.Unused)
+
+	return result, nil
+}
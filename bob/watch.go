@@ -0,0 +1,493 @@
+package bob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benchkram/errz"
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/benchkram/bob/bob/bobfile"
+	"github.com/benchkram/bob/bob/global"
+	"github.com/benchkram/bob/bob/playbook"
+	"github.com/benchkram/bob/bobtask"
+	"github.com/benchkram/bob/bobtask/target"
+	"github.com/benchkram/bob/pkg/boblog"
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+// watchDebounce is the quiet period after the last file event
+// before a rebuild starts, bursts of edits trigger a single rebuild.
+const watchDebounce = 200 * time.Millisecond
+
+// Watch builds a task and rebuilds it whenever one of the inputs of the task
+// or its dependencies changes, until ctx is canceled. Only tasks with changed
+// inputs and their dependents are considered on a rebuild. A change to a
+// bob.yaml reloads the whole build. Build errors are reported without
+// stopping to watch.
+func (b *B) Watch(ctx context.Context, taskName string) (err error) {
+	defer errz.Recover(&err)
+
+	workspace, err := filepath.Abs(".")
+	errz.Fatal(err)
+
+	succeeded := playbook.NewSucceeded()
+	w, err := newWatcher(ctx, workspace, succeeded)
+	errz.Fatal(err)
+	defer w.close()
+
+	roots := func(*bobfile.Bobfile) []string { return []string{taskName} }
+	return b.watch(ctx, w, roots, func(ag *bobfile.Bobfile, _ []string, _ bool) error {
+		return b.watchBuild(ctx, ag, taskName, playbook.WithSucceededTasks(succeeded))
+	})
+}
+
+// watchFunc is called by watch() with changed set to the build tasks whose
+// inputs changed. changed is nil on the initial call and when a bob.yaml
+// changed (reload), all tasks must be considered then.
+type watchFunc func(ag *bobfile.Bobfile, changed []string, reload bool) error
+
+// watch the inputs of the build tasks returned by roots and their
+// dependencies until ctx is canceled, fn is called on changes.
+// Changes made while fn is running are handled by the next call.
+// User errors are reported without stopping to watch.
+func (b *B) watch(ctx context.Context, w *watcher, roots func(*bobfile.Bobfile) []string, fn watchFunc) (err error) {
+	defer errz.Recover(&err)
+
+	var previous *watchIndex
+	var changed []string
+	for {
+		ag, err := b.Aggregate()
+		if err != nil {
+			if previous == nil || !errors.As(err, &usererror.Err) {
+				return err
+			}
+			// e.g. a broken bob.yaml, wait for it to be fixed
+			boblog.Log.UserError(err)
+			changed, err = w.wait(ctx)
+			errz.Fatal(err)
+			if ctx.Err() != nil {
+				return nil
+			}
+			// reconsider all tasks once the aggregate is valid again
+			changed = append(changed, previous.anyBobfile())
+			continue
+		}
+
+		index := newWatchIndex(ag, w.workspace, roots(ag)...)
+		w.setIndex(index)
+
+		call := true
+		var tasks []string
+		var reload bool
+		if previous != nil {
+			tasks, reload = previous.affected(changed, index)
+			switch {
+			case reload:
+				boblog.Log.Info("bob.yaml changed, reloading")
+				w.succeeded.Reset()
+			case len(tasks) == 0:
+				call = false
+			default:
+				boblog.Log.Info(fmt.Sprintf("Changes detected in %s", strings.Join(tasks, ", ")))
+				// created files only belong to tasks of the new index
+				w.succeeded.Invalidate(index.withDependents(tasks)...)
+			}
+		}
+
+		if call {
+			err = fn(ag, tasks, reload)
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				if !errors.As(err, &usererror.Err) {
+					return err
+				}
+				boblog.Log.UserError(err)
+			}
+			boblog.Log.Info(fmt.Sprintf("Watching %d files for changes...", len(index.inputs)))
+		}
+
+		previous = index
+		changed, err = w.wait(ctx)
+		errz.Fatal(err)
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+func (b *B) watchBuild(ctx context.Context, ag *bobfile.Bobfile, taskName string, opts ...playbook.Option) (err error) {
+	defer errz.Recover(&err)
+
+	b.PrintVersionCompatibility(ag)
+
+	err = b.nix.BuildNixDependenciesInPipeline(ag, taskName)
+	errz.Fatal(err)
+
+	p, err := b.playbook(ag, taskName, opts...)
+	errz.Fatal(err)
+
+	return p.Build(ctx)
+}
+
+// watchIndex holds the watched paths of a build,
+// paths are relative to the workspace root.
+type watchIndex struct {
+	workspace string
+
+	// inputs maps input files to the tasks using them
+	inputs map[string][]string
+	// bobfiles are the bob.yaml files of the aggregate
+	bobfiles map[string]bool
+	// targets are ignored, they are written by the build itself
+	targets []string
+	// dependents maps tasks to the tasks depending on them directly
+	dependents map[string][]string
+}
+
+func newWatchIndex(ag *bobfile.Bobfile, workspace string, taskNames ...string) *watchIndex {
+	index := &watchIndex{
+		workspace:  workspace,
+		inputs:     map[string][]string{},
+		bobfiles:   map[string]bool{},
+		dependents: map[string][]string{},
+	}
+
+	for _, bobfile := range append(ag.Bobfiles(), ag) {
+		path := filepath.Join(bobfile.Dir(), global.BobFileName)
+		if rel, err := filepath.Rel(workspace, path); err == nil && filepath.IsAbs(path) {
+			path = rel
+		}
+		index.bobfiles[filepath.Clean(path)] = true
+	}
+
+	visited := map[string]bool{}
+	for _, taskName := range taskNames {
+		_ = ag.BTasks.Walk(taskName, "", func(tn string, task bobtask.Task, err error) error {
+			if err != nil {
+				return err
+			}
+			if visited[tn] {
+				return nil
+			}
+			visited[tn] = true
+
+			for _, input := range task.Inputs() {
+				input = filepath.Clean(input)
+				index.inputs[input] = append(index.inputs[input], tn)
+			}
+			index.targets = append(index.targets, task.TargetPaths()...)
+			for _, dependency := range task.DependsOn {
+				index.dependents[dependency] = append(index.dependents[dependency], tn)
+			}
+			return nil
+		})
+	}
+
+	return index
+}
+
+func (ix *watchIndex) anyBobfile() string {
+	for path := range ix.bobfiles {
+		return path
+	}
+	return global.BobFileName
+}
+
+// withDependents returns the tasks and all tasks depending on them.
+func (ix *watchIndex) withDependents(tasks []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	var add func(tn string)
+	add = func(tn string) {
+		if seen[tn] {
+			return
+		}
+		seen[tn] = true
+		result = append(result, tn)
+		for _, dependent := range ix.dependents[tn] {
+			add(dependent)
+		}
+	}
+	for _, tn := range tasks {
+		add(tn)
+	}
+	return result
+}
+
+// dirs returns the directories to watch. Directories are watched
+// instead of files to notice files created or replaced by editors.
+func (ix *watchIndex) dirs() []string {
+	seen := map[string]bool{}
+	for path := range ix.inputs {
+		seen[filepath.Dir(path)] = true
+	}
+	for path := range ix.bobfiles {
+		seen[filepath.Dir(path)] = true
+	}
+
+	dirs := make([]string, 0, len(seen))
+	for dir := range seen {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// relevant returns false for paths the build doesn't depend on.
+func (ix *watchIndex) relevant(path string) bool {
+	if path == global.BobWorkspaceFile {
+		return false
+	}
+	for _, dir := range []string{global.BobCacheDir, global.BobLogsDir} {
+		if path == dir || strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return false
+		}
+	}
+	for _, t := range ix.targets {
+		if target.MatchPath(t, path) {
+			return false
+		}
+	}
+	return true
+}
+
+// affected returns the tasks using one of the changed paths as input, either
+// before (deleted files) or after the change (created files). reload is true
+// when a bob.yaml changed which requires to reconsider all tasks.
+func (ix *watchIndex) affected(changed []string, next *watchIndex) (tasks []string, reload bool) {
+	seen := map[string]bool{}
+	for _, path := range changed {
+		if ix.bobfiles[path] || next.bobfiles[path] {
+			return nil, true
+		}
+		for _, tn := range append(append([]string{}, ix.inputs[path]...), next.inputs[path]...) {
+			if !seen[tn] {
+				seen[tn] = true
+				tasks = append(tasks, tn)
+			}
+		}
+	}
+	sort.Strings(tasks)
+	return tasks, false
+}
+
+// watcher collects changes of the watched files during a whole watch
+// session. Changes are collected while a build is running, the tasks using
+// a changed file are invalidated right away.
+type watcher struct {
+	workspace string
+	succeeded *playbook.Succeeded
+
+	fs *fsnotify.Watcher
+	// notify is signaled on new changes
+	notify chan struct{}
+	errors chan error
+
+	mu sync.Mutex
+	// index decides which paths are relevant
+	index *watchIndex
+	// watched are the watched directories
+	watched map[string]bool
+	// changed are the paths changed since the last wait()
+	changed []string
+}
+
+// newWatcher starts watching, it stops when ctx is done or on close().
+func newWatcher(ctx context.Context, workspace string, succeeded *playbook.Succeeded) (*watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &watcher{
+		workspace: workspace,
+		succeeded: succeeded,
+		fs:        fsw,
+		notify:    make(chan struct{}, 1),
+		errors:    make(chan error, 1),
+		watched:   map[string]bool{},
+	}
+	go w.run(ctx)
+
+	return w, nil
+}
+
+func (w *watcher) close() {
+	_ = w.fs.Close()
+}
+
+func (w *watcher) run(ctx context.Context) {
+	defer w.close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+			select {
+			case w.errors <- err:
+			default:
+			}
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			w.handle(event)
+		}
+	}
+}
+
+// setIndex watches the directories of the index, directories
+// watched before are kept.
+func (w *watcher) setIndex(ix *watchIndex) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.index = ix
+	for _, dir := range ix.dirs() {
+		if w.watched[dir] {
+			continue
+		}
+		err := w.fs.Add(filepath.Join(w.workspace, dir))
+		if err != nil {
+			// directory was removed in the meantime
+			boblog.Log.V(3).Info(fmt.Sprintf("Failed to watch %s: %s", dir, err.Error()))
+			continue
+		}
+		w.watched[dir] = true
+	}
+}
+
+func (w *watcher) handle(event fsnotify.Event) {
+	if event.Op == fsnotify.Chmod {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.index == nil {
+		return
+	}
+	path, err := filepath.Rel(w.workspace, event.Name)
+	if err != nil || !w.index.relevant(path) {
+		return
+	}
+	boblog.Log.V(3).Info(fmt.Sprintf("Watch event %s", event.String()))
+
+	paths := []string{path}
+	switch {
+	case event.Op&fsnotify.Create != 0:
+		paths = append(paths, w.watchCreatedDir(event.Name)...)
+	case event.Op&(fsnotify.Remove|fsnotify.Rename) != 0:
+		// watches are dropped with the directory
+		delete(w.watched, path)
+	}
+
+	for _, path := range paths {
+		if w.succeeded != nil {
+			w.succeeded.Invalidate(w.index.withDependents(w.index.inputs[path])...)
+		}
+		w.changed = append(w.changed, path)
+	}
+
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// wait blocks until relevant files changed and no further events arrived
+// for the debounce period. Changes made since the last call are returned
+// right after the debounce period.
+func (w *watcher) wait(ctx context.Context) (changed []string, err error) {
+	var debounce <-chan time.Time
+	if w.pending() {
+		debounce = time.After(watchDebounce)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case err := <-w.errors:
+			return nil, err
+		case <-w.notify:
+			debounce = time.After(watchDebounce)
+		case <-debounce:
+			return w.take(), nil
+		}
+	}
+}
+
+func (w *watcher) pending() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.changed) > 0
+}
+
+// take returns the changed paths without duplicates and resets them.
+func (w *watcher) take() (changed []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	seen := map[string]bool{}
+	for _, path := range w.changed {
+		if !seen[path] {
+			seen[path] = true
+			changed = append(changed, path)
+		}
+	}
+	w.changed = nil
+	return changed
+}
+
+// watchCreatedDir adds watches for a newly created directory and its
+// subdirectories, fsnotify isn't recursive. Files created in there
+// before the watch was added are returned as changed.
+func (w *watcher) watchCreatedDir(dir string) (files []string) {
+	info, err := os.Lstat(dir)
+	if err != nil || !info.IsDir() {
+		return nil
+	}
+
+	_ = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		path, err := filepath.Rel(w.workspace, p)
+		if err != nil || !w.index.relevant(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !d.IsDir() {
+			files = append(files, path)
+			return nil
+		}
+		err = w.fs.Add(p)
+		if err != nil {
+			boblog.Log.V(3).Info(fmt.Sprintf("Failed to watch %s: %s", path, err.Error()))
+			return nil
+		}
+		w.watched[path] = true
+		return nil
+	})
+
+	return files
+}
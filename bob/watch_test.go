@@ -0,0 +1,110 @@
+package bob
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/benchkram/bob/bob/playbook"
+)
+
+func TestWatchIndexAffected(t *testing.T) {
+	before := &watchIndex{
+		inputs: map[string][]string{
+			"lib/a.go":  {"lib"},
+			"shared.go": {"lib", "app"},
+		},
+		bobfiles: map[string]bool{"bob.yaml": true},
+		targets:  []string{"build"},
+	}
+	after := &watchIndex{
+		inputs: map[string][]string{
+			"shared.go": {"lib", "app"},
+			"app/b.go":  {"app"},
+		},
+		bobfiles: map[string]bool{"bob.yaml": true},
+	}
+
+	tasks, reload := before.affected([]string{"lib/a.go"}, after)
+	assert.False(t, reload)
+	assert.Equal(t, []string{"lib"}, tasks)
+
+	tasks, reload = before.affected([]string{"shared.go", "app/b.go"}, after)
+	assert.False(t, reload)
+	assert.Equal(t, []string{"app", "lib"}, tasks)
+
+	tasks, reload = before.affected([]string{"notes.txt"}, after)
+	assert.False(t, reload)
+	assert.Empty(t, tasks)
+
+	_, reload = before.affected([]string{"lib/a.go", "bob.yaml"}, after)
+	assert.True(t, reload)
+
+	assert.False(t, before.relevant("build/app"))
+	assert.False(t, before.relevant(".bobcache/hashes"))
+	assert.False(t, before.relevant(".bob/logs/server/output.log"))
+	assert.True(t, before.relevant("lib/a.go"))
+
+	deps := &watchIndex{dependents: map[string][]string{
+		"gen": {"lib"},
+		"lib": {"app", "build"},
+		"app": {"build"},
+	}}
+	assert.ElementsMatch(t, []string{"gen", "lib", "app", "build"}, deps.withDependents([]string{"gen"}))
+	assert.ElementsMatch(t, []string{"app", "build"}, deps.withDependents([]string{"app"}))
+}
+
+func TestWatcherCreatedDir(t *testing.T) {
+	workspace := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(workspace, "src"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(workspace, "src", "a.txt"), []byte("a"), 0644))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	w, err := newWatcher(ctx, workspace, nil)
+	assert.Nil(t, err)
+	w.setIndex(&watchIndex{
+		workspace: workspace,
+		inputs:    map[string][]string{"src/a.txt": {"build"}},
+		bobfiles:  map[string]bool{},
+	})
+
+	// the file is created in a directory which isn't watched yet
+	assert.Nil(t, os.MkdirAll(filepath.Join(workspace, "src", "new", "deep"), 0755))
+	time.Sleep(50 * time.Millisecond)
+	assert.Nil(t, os.WriteFile(filepath.Join(workspace, "src", "new", "deep", "b.txt"), []byte("b"), 0644))
+
+	changed, err := w.wait(ctx)
+	assert.Nil(t, err)
+	assert.Contains(t, changed, filepath.Join("src", "new", "deep", "b.txt"))
+}
+
+func TestWatcherChangeDuringBuild(t *testing.T) {
+	workspace := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(workspace, "src"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(workspace, "src", "a.txt"), []byte("a"), 0644))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	w, err := newWatcher(ctx, workspace, playbook.NewSucceeded())
+	assert.Nil(t, err)
+	w.setIndex(&watchIndex{
+		workspace: workspace,
+		inputs:    map[string][]string{"src/a.txt": {"build"}},
+		bobfiles:  map[string]bool{},
+	})
+
+	// the file is changed before wait() is called, e.g. during a build
+	assert.Nil(t, os.WriteFile(filepath.Join(workspace, "src", "a.txt"), []byte("b"), 0644))
+	time.Sleep(100 * time.Millisecond)
+
+	changed, err := w.wait(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join("src", "a.txt")}, changed)
+}
@@ -0,0 +1,66 @@
+package bob
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/benchkram/errz"
+
+	"github.com/benchkram/bob/bobtask/buildinfo"
+	"github.com/benchkram/bob/pkg/boberror"
+	"github.com/benchkram/bob/pkg/buildinfostore"
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+// WhyResult explains the input hash change of a task.
+type WhyResult struct {
+	Task string
+
+	// InputHash is the current input hash
+	InputHash string
+
+	// Previous is the most recent build of the task
+	Previous *buildinfo.I
+
+	Diff buildinfo.InputsDiff
+}
+
+// UpToDate is true when the current inputs match the previous build.
+func (w *WhyResult) UpToDate() bool {
+	return w.InputHash == w.Previous.Meta.InputHash
+}
+
+// Why compares the current inputs of a task against
+// the inputs of the most recent build of that task.
+func (b *B) Why(taskName string) (_ *WhyResult, err error) {
+	defer errz.Recover(&err)
+
+	ag, err := b.AggregateWithNixDeps(taskName)
+	errz.Fatal(err)
+
+	task, ok := ag.BTasks[taskName]
+	if !ok {
+		return nil, usererror.Wrap(boberror.ErrTaskDoesNotExistF(taskName))
+	}
+
+	hashIn, err := task.HashIn()
+	errz.Fatal(err)
+
+	inputs, err := task.InputManifest()
+	errz.Fatal(err)
+
+	previous, err := task.LatestBuildInfo()
+	if err != nil {
+		if errors.Is(err, buildinfostore.ErrBuildInfoDoesNotExist) {
+			return nil, usererror.Wrapm(err, fmt.Sprintf("No previous build recorded for task [%s]", taskName))
+		}
+		errz.Fatal(err)
+	}
+
+	return &WhyResult{
+		Task:      taskName,
+		InputHash: hashIn.String(),
+		Previous:  previous,
+		Diff:      inputs.Diff(&previous.Inputs),
+	}, nil
+}
@@ -2,12 +2,10 @@ package bobrun
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/benchkram/bob/pkg/composectl"
 	"github.com/benchkram/bob/pkg/composeutil"
 	"github.com/benchkram/bob/pkg/ctl"
-	"github.com/benchkram/bob/pkg/usererror"
 	"github.com/benchkram/errz"
 )
 
@@ -15,26 +13,21 @@ const composeFileDefault = "docker-compose.yml"
 
 func (r *Run) composeCommand(ctx context.Context) (_ ctl.Command, err error) {
 	defer errz.Recover(&err)
-	path := r.Path
-	if path == "" {
-		path = composeFileDefault
-	}
 
-	p, err := composeutil.ProjectFromConfig(path)
+	p, err := r.composeProject()
 	errz.Fatal(err)
 
-	cfgs := composeutil.ProjectPortConfigs(p)
+	// conflicts are usually resolved together with
+	// other run tasks, see RunMap.ResolvePorts()
+	portConflicts := r.portConflicts
+	portMapping := r.portMapping
 
-	portConflicts := ""
-	portMapping := ""
+	cfgs := composeutil.ProjectPortConfigs(p)
 	if composeutil.HasPortConflicts(cfgs) {
 		conflicts := composeutil.PortConflicts(cfgs)
 
 		portConflicts = conflicts.String()
 
-		// TODO: disable once we also resolve binaries' ports
-		errz.Fatal(usererror.Wrap(fmt.Errorf(fmt.Sprint("conflicting ports detected:\n", conflicts))))
-
 		resolved, err := composeutil.ResolvePortConflicts(conflicts)
 		errz.Fatal(err)
 
@@ -0,0 +1,155 @@
+package bobrun
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"mvdan.cc/sh/expand"
+	"mvdan.cc/sh/interp"
+	"mvdan.cc/sh/syntax"
+
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+var ErrInvalidHealthcheck = fmt.Errorf("invalid healthcheck")
+
+const (
+	defaultHealthcheckInterval = time.Second
+	defaultHealthcheckTimeout  = time.Second
+	defaultHealthcheckRetries  = 30
+)
+
+// Healthcheck probes a run task after it started. Dependent run tasks
+// are only started after the probe succeeded. Exactly one of TCP, HTTP
+// and Cmd must be set.
+type Healthcheck struct {
+	// TCP is a port or host:port accepting connections.
+	TCP string `yaml:"tcp"`
+
+	// HTTP is an url answering GET requests with a status below 400.
+	HTTP string `yaml:"http"`
+
+	// Cmd is a shell command exiting with 0.
+	Cmd string `yaml:"cmd"`
+
+	// IntervalDirty between two probes, defaults to 1s.
+	IntervalDirty string `yaml:"interval"`
+	interval      time.Duration
+
+	// TimeoutDirty of a single probe, defaults to 1s.
+	TimeoutDirty string `yaml:"timeout"`
+	timeout      time.Duration
+
+	// Retries is the number of failed probes before
+	// a run task is considered unhealthy, defaults to 30.
+	Retries int `yaml:"retries"`
+}
+
+// sanitizeHealthcheck parses durations and applies defaults.
+func (r *Run) sanitizeHealthcheck() (err error) {
+	hc := r.Healthcheck
+	if hc == nil {
+		return nil
+	}
+
+	probes := 0
+	for _, probe := range []string{hc.TCP, hc.HTTP, hc.Cmd} {
+		if probe != "" {
+			probes++
+		}
+	}
+	if probes != 1 {
+		return usererror.Wrap(fmt.Errorf("%w in run task `%s`, set exactly one of [tcp, http, cmd]", ErrInvalidHealthcheck, r.name))
+	}
+
+	hc.interval, err = parseHealthcheckDuration(hc.IntervalDirty, defaultHealthcheckInterval)
+	if err != nil {
+		return usererror.Wrap(fmt.Errorf("%w in run task `%s`, interval: %s", ErrInvalidHealthcheck, r.name, err))
+	}
+	hc.timeout, err = parseHealthcheckDuration(hc.TimeoutDirty, defaultHealthcheckTimeout)
+	if err != nil {
+		return usererror.Wrap(fmt.Errorf("%w in run task `%s`, timeout: %s", ErrInvalidHealthcheck, r.name, err))
+	}
+
+	switch {
+	case hc.Retries < 0:
+		return usererror.Wrap(fmt.Errorf("%w in run task `%s`, retries must not be negative", ErrInvalidHealthcheck, r.name))
+	case hc.Retries == 0:
+		hc.Retries = defaultHealthcheckRetries
+	}
+
+	return nil
+}
+
+func parseHealthcheckDuration(s string, def time.Duration) (time.Duration, error) {
+	if s == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be greater than 0")
+	}
+	return d, nil
+}
+
+// probe runs the healthcheck once.
+func (hc *Healthcheck) probe(ctx context.Context, dir string, env []string) error {
+	ctx, cancel := context.WithTimeout(ctx, hc.timeout)
+	defer cancel()
+
+	switch {
+	case hc.TCP != "":
+		addr := hc.TCP
+		if !strings.Contains(addr, ":") {
+			addr = "localhost:" + addr
+		}
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	case hc.HTTP != "":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, hc.HTTP, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("GET %s returned %s", hc.HTTP, resp.Status)
+		}
+		return nil
+	default:
+		p, err := syntax.NewParser().Parse(strings.NewReader(hc.Cmd), "")
+		if err != nil {
+			return err
+		}
+		devnull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+		if err != nil {
+			return err
+		}
+		defer devnull.Close()
+		r, err := interp.New(
+			interp.Params("-e"),
+			interp.Dir(dir),
+			interp.Env(expand.ListEnviron(env...)),
+			interp.StdIO(devnull, devnull, devnull),
+		)
+		if err != nil {
+			return err
+		}
+		return r.Run(ctx, p)
+	}
+}
@@ -0,0 +1,74 @@
+package bobrun
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSanitizeHealthcheck(t *testing.T) {
+	var r Run
+	err := yaml.Unmarshal([]byte(`
+type: binary
+healthcheck:
+  tcp: 8080
+  interval: 500ms
+`), &r)
+	assert.Nil(t, err)
+
+	err = r.sanitizeHealthcheck()
+	assert.Nil(t, err)
+	assert.Equal(t, "8080", r.Healthcheck.TCP)
+	assert.Equal(t, 500*time.Millisecond, r.Healthcheck.interval)
+	assert.Equal(t, defaultHealthcheckTimeout, r.Healthcheck.timeout)
+	assert.Equal(t, defaultHealthcheckRetries, r.Healthcheck.Retries)
+
+	invalid := []*Healthcheck{
+		{},
+		{TCP: "8080", HTTP: "http://localhost:8080"},
+		{Cmd: "true", IntervalDirty: "soon"},
+		{Cmd: "true", TimeoutDirty: "-1s"},
+		{Cmd: "true", Retries: -1},
+	}
+	for _, hc := range invalid {
+		r := Run{Healthcheck: hc}
+		err := r.sanitizeHealthcheck()
+		assert.True(t, errors.Is(err, ErrInvalidHealthcheck), "%+v", hc)
+	}
+}
+
+func TestHealthcheckProbe(t *testing.T) {
+	ctx := context.Background()
+	probe := func(hc Healthcheck) error {
+		hc.timeout = time.Second
+		return hc.probe(ctx, ".", nil)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	addr := l.Addr().String()
+	assert.Nil(t, probe(Healthcheck{TCP: addr}))
+	l.Close()
+	assert.NotNil(t, probe(Healthcheck{TCP: addr}))
+
+	healthy := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	assert.Nil(t, probe(Healthcheck{HTTP: server.URL}))
+	healthy = false
+	assert.NotNil(t, probe(Healthcheck{HTTP: server.URL}))
+
+	assert.Nil(t, probe(Healthcheck{Cmd: "true"}))
+	assert.NotNil(t, probe(Healthcheck{Cmd: "exit 1"}))
+}
@@ -40,6 +40,13 @@ func (rm RunMap) Sanitize() (err error) {
 	for key, task := range rm {
 		task.init = multilinecmd.Split(task.InitDirty)
 		task.initOnce = multilinecmd.Split(task.InitOnceDirty)
+
+		err = task.sanitizeHealthcheck()
+		errz.Fatal(err)
+
+		err = task.sanitizePorts()
+		errz.Fatal(err)
+
 		rm[key] = task
 	}
 
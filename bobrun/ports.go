@@ -0,0 +1,331 @@
+package bobrun
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/benchkram/errz"
+	"github.com/compose-spec/compose-go/types"
+
+	"github.com/benchkram/bob/pkg/boblog"
+	"github.com/benchkram/bob/pkg/composeutil"
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+var ErrInvalidPort = fmt.Errorf("invalid port")
+
+// Port used by a binary run task in the form `port[/protocol]`,
+// the protocol defaults to tcp.
+type port struct {
+	port     string
+	protocol string
+}
+
+func (p port) String() string {
+	return p.port + "/" + p.protocol
+}
+
+func (r *Run) sanitizePorts() error {
+	r.ports = nil
+	for _, p := range r.PortsDirty {
+		number, protocol, found := strings.Cut(strings.TrimSpace(p), "/")
+		if !found {
+			protocol = "tcp"
+		}
+		n, err := strconv.Atoi(number)
+		if err != nil || n < 1 || n > 65535 || (protocol != "tcp" && protocol != "udp") {
+			return usererror.Wrap(fmt.Errorf("%w `%s` in run task `%s`, use `port[/tcp|udp]`", ErrInvalidPort, p, r.name))
+		}
+		if r.Type != RunTypeBinary {
+			return usererror.Wrap(fmt.Errorf("%w in run task `%s`, ports can only be declared by binaries, compose ports are read from the compose file", ErrInvalidPort, r.name))
+		}
+		r.ports = append(r.ports, port{port: strconv.Itoa(n), protocol: protocol})
+	}
+	return nil
+}
+
+// ResolvePorts checks the ports of the given run tasks for conflicts
+// with each other and with the host. Conflicting ports are remapped to
+// free ports. Binaries receive their ports through `$PORT` (the first
+// port) and `$PORT_<port>`. Run tasks depending on another run task
+// receive its ports through `$<TASK>_PORT_<port>` for binaries and
+// `$<TASK>_<SERVICE>_PORT_<port>` for compose services.
+func (rm RunMap) ResolvePorts(runTasks []string) (err error) {
+	defer errz.Recover(&err)
+
+	names := append([]string{}, runTasks...)
+	sort.Strings(names)
+
+	// services are the run task name for binaries
+	// and `task/service` for compose services.
+	services := map[string][]port{}
+	var serviceNames []string
+	for _, name := range names {
+		r, ok := rm[name]
+		if !ok {
+			continue
+		}
+
+		switch r.Type {
+		case RunTypeBinary:
+			if len(r.ports) > 0 {
+				services[name] = r.ports
+				serviceNames = append(serviceNames, name)
+			}
+		case RunTypeCompose:
+			p, err := r.composeProject()
+			errz.Fatal(err)
+
+			sort.Slice(p.Services, func(i, j int) bool {
+				return p.Services[i].Name < p.Services[j].Name
+			})
+			for _, s := range p.Services {
+				service := name + "/" + s.Name
+				for _, sp := range s.Ports {
+					if sp.Published == "" {
+						continue
+					}
+					services[service] = append(services[service], port{port: sp.Published, protocol: sp.Protocol})
+				}
+				if len(services[service]) > 0 {
+					serviceNames = append(serviceNames, service)
+				}
+			}
+		}
+	}
+
+	cfgs := portConfigs(serviceNames, services)
+
+	// mapping of service to original to resolved port
+	mapping := map[string]map[string]string{}
+	if composeutil.HasPortConflicts(cfgs) {
+		conflicts := composeutil.PortConflicts(cfgs)
+
+		resolved, err := composeutil.ResolvePortConflicts(conflicts)
+		errz.Fatal(err)
+
+		boblog.Log.Info(fmt.Sprintf("Conflicting ports detected:\n%s", conflicts))
+		boblog.Log.Info(fmt.Sprintf("Resolved port mapping:\n%s", resolved))
+
+		for _, cfg := range resolved {
+			service := cfg.Services[0]
+			if mapping[service] == nil {
+				mapping[service] = map[string]string{}
+			}
+			mapping[service][port{port: cfg.OriginalPort, protocol: cfg.Protocol}.String()] = cfg.Port
+		}
+
+		for _, name := range names {
+			r, ok := rm[name]
+			if !ok || r.Type != RunTypeCompose {
+				continue
+			}
+			r.portConflicts = conflicts.String()
+			r.portMapping = resolved.String()
+			composeutil.ApplyPortMapping(r.project, composeMapping(name, resolved))
+		}
+	}
+
+	// resolved ports by service
+	resolvedPorts := map[string][]port{}
+	for _, service := range serviceNames {
+		for _, p := range services[service] {
+			if mapped, ok := mapping[service][p.String()]; ok {
+				resolvedPorts[service] = append(resolvedPorts[service], port{port: mapped, protocol: p.protocol})
+			} else {
+				resolvedPorts[service] = append(resolvedPorts[service], p)
+			}
+		}
+	}
+
+	for _, name := range names {
+		r, ok := rm[name]
+		if !ok {
+			continue
+		}
+
+		var env []string
+		if r.Type == RunTypeBinary {
+			env = append(env, portEnv("PORT", r.ports, resolvedPorts[name])...)
+			r.resolveHealthcheckPort(r.ports, resolvedPorts[name])
+		}
+		for _, dependency := range rm.runDependencies(name) {
+			for _, service := range serviceNames {
+				if service != dependency && !strings.HasPrefix(service, dependency+"/") {
+					continue
+				}
+				env = append(env, portEnv(envName(service)+"_PORT", services[service], resolvedPorts[service])...)
+			}
+		}
+		r.env = append(r.env, env...)
+	}
+
+	return nil
+}
+
+// portConfigs associates ports with the services using them.
+// The host is listed first for ports already in use.
+func portConfigs(serviceNames []string, services map[string][]port) composeutil.PortConfigs {
+	users := map[string][]string{}
+	var keys []port
+	for _, service := range serviceNames {
+		for _, p := range services[service] {
+			key := p.String()
+			if len(users[key]) == 0 {
+				keys = append(keys, p)
+				if !composeutil.PortAvailable(p.port, p.protocol) {
+					users[key] = append(users[key], "host")
+				}
+			}
+			users[key] = append(users[key], service)
+		}
+	}
+
+	cfgs := composeutil.PortConfigs{}
+	for _, p := range keys {
+		cfgs = append(cfgs, composeutil.PortConfig{
+			Port:         p.port,
+			OriginalPort: p.port,
+			Protocol:     p.protocol,
+			Services:     users[p.String()],
+		})
+	}
+	return cfgs
+}
+
+// composeMapping returns the mapping of a compose run task
+// with the service names used in the compose file.
+func composeMapping(name string, resolved composeutil.PortConfigs) composeutil.PortConfigs {
+	var mapping composeutil.PortConfigs
+	for _, cfg := range resolved {
+		service, ok := strings.CutPrefix(cfg.Services[0], name+"/")
+		if !ok {
+			continue
+		}
+		cfg.Services = []string{service}
+		mapping = append(mapping, cfg)
+	}
+	return mapping
+}
+
+// runDependencies returns the run tasks a run task depends on,
+// directly or through other run tasks.
+func (rm RunMap) runDependencies(name string) (dependencies []string) {
+	seen := map[string]bool{}
+	var walk func(name string)
+	walk = func(name string) {
+		r, ok := rm[name]
+		if !ok {
+			return
+		}
+		for _, dependency := range r.DependsOn {
+			if _, ok := rm[dependency]; !ok || seen[dependency] {
+				continue
+			}
+			seen[dependency] = true
+			dependencies = append(dependencies, dependency)
+			walk(dependency)
+		}
+	}
+	walk(name)
+	sort.Strings(dependencies)
+	return dependencies
+}
+
+// resolveHealthcheckPort points a tcp or http healthcheck on a declared
+// port, e.g. `8080`, `localhost:8080` or `http://localhost:8080/health`,
+// to the resolved port.
+func (r *Run) resolveHealthcheckPort(ports, resolved []port) {
+	if r.Healthcheck == nil {
+		return
+	}
+
+	mapping := map[string]string{}
+	for i, p := range ports {
+		if p.protocol == "tcp" {
+			mapping[p.port] = resolved[i].port
+		}
+	}
+
+	if r.Healthcheck.TCP != "" {
+		r.Healthcheck.TCP = remapAddress(r.Healthcheck.TCP, mapping)
+	}
+	if r.Healthcheck.HTTP != "" {
+		r.Healthcheck.HTTP = remapURL(r.Healthcheck.HTTP, mapping)
+	}
+}
+
+// remapAddress remaps the port of `port` or `host:port`.
+func remapAddress(addr string, mapping map[string]string) string {
+	if mapped, ok := mapping[addr]; ok {
+		return mapped
+	}
+	host, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	if mapped, ok := mapping[p]; ok {
+		return net.JoinHostPort(host, mapped)
+	}
+	return addr
+}
+
+// remapURL remaps the port of an url, urls without
+// an explicit port are returned unchanged.
+func remapURL(rawURL string, mapping map[string]string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	mapped, ok := mapping[u.Port()]
+	if !ok {
+		return rawURL
+	}
+	u.Host = net.JoinHostPort(u.Hostname(), mapped)
+	return u.String()
+}
+
+// portEnv returns `<prefix>=<first port>` and
+// `<prefix>_<original port>=<resolved port>`.
+func portEnv(prefix string, ports, resolved []port) []string {
+	if len(ports) == 0 {
+		return nil
+	}
+	env := []string{prefix + "=" + resolved[0].port}
+	for i, p := range ports {
+		env = append(env, fmt.Sprintf("%s_%s=%s", prefix, p.port, resolved[i].port))
+	}
+	return env
+}
+
+var envNameInvalidChars = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// envName converts a service name to a variable name,
+// e.g. `database/postgres` => `DATABASE_POSTGRES`.
+func envName(service string) string {
+	return envNameInvalidChars.ReplaceAllString(strings.ToUpper(service), "_")
+}
+
+// composeProject loads the compose file once.
+func (r *Run) composeProject() (*types.Project, error) {
+	if r.project != nil {
+		return r.project, nil
+	}
+
+	path := r.Path
+	if path == "" {
+		path = composeFileDefault
+	}
+
+	p, err := composeutil.ProjectFromConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	r.project = p
+	return p, nil
+}
@@ -0,0 +1,109 @@
+package bobrun
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newPortsRun(name string, ports []string, dependsOn ...string) *Run {
+	r := &Run{Type: RunTypeBinary, PortsDirty: ports, DependsOn: dependsOn}
+	r.SetName(name)
+	return r
+}
+
+func TestResolvePorts(t *testing.T) {
+	// a port used by the host
+	l, err := net.Listen("tcp", ":0")
+	assert.Nil(t, err)
+	defer l.Close()
+	hostPort := strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+
+	// a free port
+	l2, err := net.Listen("tcp", ":0")
+	assert.Nil(t, err)
+	freePort := strconv.Itoa(l2.Addr().(*net.TCPAddr).Port)
+	l2.Close()
+
+	rm := RunMap{
+		"api": newPortsRun("api", []string{freePort}),
+		"web": newPortsRun("web", []string{freePort, hostPort}),
+		"app": newPortsRun("app", nil, "web"),
+	}
+	for _, r := range rm {
+		assert.Nil(t, r.sanitizePorts())
+	}
+
+	err = rm.ResolvePorts([]string{"app", "web", "api"})
+	assert.Nil(t, err)
+
+	// api keeps the port, both ports of web are remapped
+	assert.Contains(t, rm["api"].Env(), "PORT="+freePort)
+	env := envMap(rm["web"].Env())
+	assert.NotEqual(t, freePort, env["PORT"])
+	assert.Equal(t, env["PORT"], env["PORT_"+freePort])
+	assert.NotEqual(t, hostPort, env["PORT_"+hostPort])
+
+	// dependents receive the resolved ports
+	assert.Equal(t, []string{
+		"WEB_PORT=" + env["PORT"],
+		fmt.Sprintf("WEB_PORT_%s=%s", freePort, env["PORT"]),
+		fmt.Sprintf("WEB_PORT_%s=%s", hostPort, env["PORT_"+hostPort]),
+	}, rm["app"].Env())
+}
+
+func TestSanitizePorts(t *testing.T) {
+	r := newPortsRun("api", []string{"8080", "53/udp"})
+	assert.Nil(t, r.sanitizePorts())
+	assert.Equal(t, []port{{"8080", "tcp"}, {"53", "udp"}}, r.ports)
+
+	for _, p := range []string{"http", "0", "70000", "8080/sctp"} {
+		r := newPortsRun("api", []string{p})
+		assert.True(t, errors.Is(r.sanitizePorts(), ErrInvalidPort), p)
+	}
+
+	r = newPortsRun("db", []string{"5432"})
+	r.Type = RunTypeCompose
+	assert.True(t, errors.Is(r.sanitizePorts(), ErrInvalidPort))
+}
+
+func TestResolveHealthcheckPort(t *testing.T) {
+	ports := []port{{"8080", "tcp"}, {"53", "udp"}}
+	resolved := []port{{"8081", "tcp"}, {"54", "udp"}}
+
+	tests := []struct {
+		healthcheck Healthcheck
+		want        Healthcheck
+	}{
+		{Healthcheck{TCP: "8080"}, Healthcheck{TCP: "8081"}},
+		{Healthcheck{TCP: "localhost:8080"}, Healthcheck{TCP: "localhost:8081"}},
+		{Healthcheck{TCP: "[::1]:8080"}, Healthcheck{TCP: "[::1]:8081"}},
+		{Healthcheck{TCP: "localhost:9090"}, Healthcheck{TCP: "localhost:9090"}},
+		{Healthcheck{TCP: "53"}, Healthcheck{TCP: "53"}},
+		{Healthcheck{HTTP: "http://localhost:8080/health?ready=1"}, Healthcheck{HTTP: "http://localhost:8081/health?ready=1"}},
+		{Healthcheck{HTTP: "https://127.0.0.1:8080"}, Healthcheck{HTTP: "https://127.0.0.1:8081"}},
+		{Healthcheck{HTTP: "http://localhost/health"}, Healthcheck{HTTP: "http://localhost/health"}},
+		{Healthcheck{HTTP: "http://localhost:9090/health"}, Healthcheck{HTTP: "http://localhost:9090/health"}},
+		{Healthcheck{Cmd: "curl localhost:8080"}, Healthcheck{Cmd: "curl localhost:8080"}},
+	}
+	for _, tt := range tests {
+		hc := tt.healthcheck
+		r := &Run{Healthcheck: &hc}
+		r.resolveHealthcheckPort(ports, resolved)
+		assert.Equal(t, tt.want, *r.Healthcheck)
+	}
+}
+
+func envMap(env []string) map[string]string {
+	m := map[string]string{}
+	for _, v := range env {
+		key, value, _ := strings.Cut(v, "=")
+		m[key] = value
+	}
+	return m
+}
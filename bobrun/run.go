@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	"github.com/benchkram/errz"
+	"github.com/compose-spec/compose-go/types"
 	"gopkg.in/yaml.v3"
 
 	"github.com/benchkram/bob/pkg/ctl"
@@ -37,6 +38,21 @@ type Run struct {
 	// initOnce see InitOnceDirty
 	initOnce []string
 
+	// PortsDirty used by a binary, e.g. `8080` or `8080/udp`.
+	PortsDirty []string `yaml:"ports"`
+	// ports see PortsDirty
+	ports []port
+
+	// project is the loaded compose file
+	project *types.Project
+	// portConflicts and portMapping of compose
+	// ports resolved by RunMap.ResolvePorts()
+	portConflicts string
+	portMapping   string
+
+	// Healthcheck gates the start of dependent run tasks.
+	Healthcheck *Healthcheck `yaml:"healthcheck"`
+
 	// DependenciesDirty read from the bobfile
 	DependenciesDirty []string `yaml:"dependencies"`
 
@@ -50,6 +66,10 @@ type Run struct {
 
 	dir string
 
+	// logDir is the base directory of the log files of run tasks,
+	// logs are not written when empty.
+	logDir string
+
 	// env holds key=value pairs passed to the environment
 	// when the task is executed.
 	env []string
@@ -85,6 +105,10 @@ func (r *Run) SetDir(dir string) {
 	r.dir = dir
 }
 
+func (r *Run) SetLogDir(dir string) {
+	r.logDir = dir
+}
+
 func (r *Run) Dependencies() []nix.Dependency {
 	return r.dependencies
 }
@@ -153,5 +177,14 @@ func (r *Run) Command(ctx context.Context) (rc ctl.Command, err error) {
 	rc, err = r.WrapWithInit(ctx, rc)
 	errz.Fatal(err)
 
+	if r.logDir != "" {
+		rc, err = r.WrapWithLogs(ctx, rc)
+		errz.Fatal(err)
+	}
+
+	if r.Healthcheck != nil {
+		rc = r.WrapWithHealthcheck(ctx, rc)
+	}
+
 	return rc, nil
 }
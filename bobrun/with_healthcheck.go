@@ -0,0 +1,202 @@
+package bobrun
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/benchkram/errz"
+
+	"github.com/benchkram/bob/pkg/boblog"
+	"github.com/benchkram/bob/pkg/ctl"
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+var _ ctl.HealthReporter = (*WithHealthcheck)(nil)
+
+// WithHealthcheck wraps a run-task to block on start
+// until the healthcheck of the run-task succeeded.
+// Afterwards the health is monitored in the background.
+type WithHealthcheck struct {
+	// inner is the wrapped command
+	inner ctl.Command
+
+	// run is the run cmd to get the healthcheck from.
+	run *Run
+
+	// ctx is the outer context to listen to
+	ctx context.Context
+
+	// mux protects the fields below
+	mux    sync.Mutex
+	health ctl.HealthState
+	// cancel stops probing.
+	cancel context.CancelFunc
+}
+
+// WrapWithHealthcheck takes a ctl to add the healthcheck defined in the run task.
+func (r *Run) WrapWithHealthcheck(ctx context.Context, rc ctl.Command) ctl.Command {
+	return &WithHealthcheck{
+		inner:  rc,
+		run:    r,
+		ctx:    ctx,
+		health: ctl.HealthStopped,
+		cancel: func() {},
+	}
+}
+
+func (rh *WithHealthcheck) Name() string {
+	return rh.inner.Name()
+}
+
+func (rh *WithHealthcheck) Health() ctl.HealthState {
+	rh.mux.Lock()
+	defer rh.mux.Unlock()
+	return rh.health
+}
+
+func (rh *WithHealthcheck) setHealth(health ctl.HealthState) {
+	rh.mux.Lock()
+	defer rh.mux.Unlock()
+	rh.health = health
+}
+
+// Start the inner command and wait for it to become healthy.
+func (rh *WithHealthcheck) Start() (err error) {
+	defer errz.Recover(&err)
+
+	rh.stopProbing()
+
+	err = rh.inner.Start()
+	errz.Fatal(err)
+
+	return rh.waitHealthy()
+}
+
+// Restart the inner command and wait for it to become healthy.
+func (rh *WithHealthcheck) Restart() (err error) {
+	defer errz.Recover(&err)
+
+	rh.stopProbing()
+
+	err = rh.inner.Restart()
+	errz.Fatal(err)
+
+	return rh.waitHealthy()
+}
+
+func (rh *WithHealthcheck) Stop() error {
+	rh.stopProbing()
+	rh.setHealth(ctl.HealthStopped)
+	return rh.inner.Stop()
+}
+
+func (rh *WithHealthcheck) Shutdown() error {
+	rh.stopProbing()
+	rh.setHealth(ctl.HealthStopped)
+	return rh.inner.Shutdown()
+}
+
+func (rh *WithHealthcheck) Running() bool {
+	return rh.inner.Running()
+}
+
+func (rh *WithHealthcheck) Done() <-chan struct{} {
+	return rh.inner.Done()
+}
+
+func (rh *WithHealthcheck) Exited() <-chan error {
+	if en, ok := rh.inner.(ctl.ExitNotifier); ok {
+		return en.Exited()
+	}
+	return nil
+}
+
+func (rh *WithHealthcheck) Stdout() io.Reader {
+	return rh.inner.Stdout()
+}
+func (rh *WithHealthcheck) Stderr() io.Reader {
+	return rh.inner.Stderr()
+}
+func (rh *WithHealthcheck) Stdin() io.Writer {
+	return rh.inner.Stdin()
+}
+
+func (rh *WithHealthcheck) stopProbing() {
+	rh.mux.Lock()
+	defer rh.mux.Unlock()
+	rh.cancel()
+}
+
+// waitHealthy probes until the healthcheck succeeds or all retries failed.
+// On success the health is monitored in the background until the
+// command is stopped.
+func (rh *WithHealthcheck) waitHealthy() error {
+	hc := rh.run.Healthcheck
+
+	ctx, cancel := context.WithCancel(rh.ctx)
+	rh.mux.Lock()
+	rh.cancel = cancel
+	rh.health = ctl.HealthStarting
+	rh.mux.Unlock()
+
+	boblog.Log.Info(fmt.Sprintf("Waiting for [%s] to become healthy", rh.Name()))
+
+	var err error
+	for i := 0; i < hc.Retries; i++ {
+		err = hc.probe(ctx, rh.run.dir, rh.run.Env())
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		boblog.Log.V(3).Info(fmt.Sprintf("Healthcheck [%s] failed: %s", rh.Name(), err.Error()))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(hc.interval):
+		}
+	}
+	if err != nil {
+		rh.setHealth(ctl.HealthUnhealthy)
+		return usererror.Wrapm(err, fmt.Sprintf("run task [%s] is unhealthy after %d retries", rh.Name(), hc.Retries))
+	}
+
+	rh.setHealth(ctl.HealthHealthy)
+	boblog.Log.Info(fmt.Sprintf("[%s] is healthy", rh.Name()))
+
+	go rh.monitor(ctx)
+
+	return nil
+}
+
+// monitor updates the health state until ctx is canceled.
+func (rh *WithHealthcheck) monitor(ctx context.Context) {
+	hc := rh.run.Healthcheck
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(hc.interval):
+		}
+
+		err := hc.probe(ctx, rh.run.dir, rh.run.Env())
+
+		health := ctl.HealthHealthy
+		if err != nil {
+			health = ctl.HealthUnhealthy
+		}
+
+		rh.mux.Lock()
+		// the command might have been stopped in the meantime
+		if ctx.Err() == nil && health != rh.health {
+			boblog.Log.Info(fmt.Sprintf("[%s] is %s", rh.Name(), health))
+			rh.health = health
+		}
+		rh.mux.Unlock()
+	}
+}
@@ -143,6 +143,13 @@ func (rw *WithInit) Shutdown() (err error) {
 	return rw.inner.Shutdown()
 }
 
+func (rw *WithInit) Exited() <-chan error {
+	if en, ok := rw.inner.(ctl.ExitNotifier); ok {
+		return en.Exited()
+	}
+	return nil
+}
+
 func (rw *WithInit) Done() <-chan struct{} {
 	return rw.done
 }
@@ -0,0 +1,132 @@
+package bobrun
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/benchkram/errz"
+
+	"github.com/benchkram/bob/pkg/boblog"
+	"github.com/benchkram/bob/pkg/ctl"
+	"github.com/benchkram/bob/pkg/runlog"
+)
+
+var _ ctl.ExitNotifier = (*WithLogs)(nil)
+
+// WithLogs wraps a run-task to write its output
+// to rotating log files in the log dir of the run-task.
+type WithLogs struct {
+	// inner is the wrapped command
+	inner ctl.Command
+
+	log *runlog.Writer
+
+	stdout pipe
+	stderr pipe
+}
+
+// WrapWithLogs takes a ctl to write its output to the log dir of the run task.
+// The log file is closed once ctx is canceled and the command is done.
+func (r *Run) WrapWithLogs(ctx context.Context, rc ctl.Command) (_ ctl.Command, err error) {
+	defer errz.Recover(&err)
+
+	log, err := runlog.NewWriter(runlog.Dir(r.logDir, r.name))
+	errz.Fatal(err)
+
+	rl := &WithLogs{
+		inner: rc,
+		log:   log,
+	}
+
+	rl.stdout.r, rl.stdout.w, err = os.Pipe()
+	errz.Fatal(err)
+
+	rl.stderr.r, rl.stderr.w, err = os.Pipe()
+	errz.Fatal(err)
+
+	go rl.copy(rc.Stdout(), rl.stdout.w)
+	go rl.copy(rc.Stderr(), rl.stderr.w)
+
+	go func() {
+		<-ctx.Done()
+		<-rc.Done()
+		_ = log.Close()
+	}()
+
+	return rl, nil
+}
+
+// copy lines from r to w and to the log file.
+func (rl *WithLogs) copy(r io.Reader, w io.Writer) {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			_, _ = w.Write(line)
+			rl.logf("%s", line)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// logf writes a timestamped line to the log file,
+// secrets must never show up in the logs.
+func (rl *WithLogs) logf(format string, a ...interface{}) {
+	line := boblog.Redact(fmt.Sprintf(format, a...))
+	if len(line) == 0 || line[len(line)-1] != '\n' {
+		line += "\n"
+	}
+	_, _ = fmt.Fprintf(rl.log, "%s %s", time.Now().Format(time.RFC3339), line)
+}
+
+func (rl *WithLogs) Name() string {
+	return rl.inner.Name()
+}
+
+func (rl *WithLogs) Start() error {
+	return rl.inner.Start()
+}
+
+func (rl *WithLogs) Restart() error {
+	rl.logf("--- restarting %s ---", rl.Name())
+	return rl.inner.Restart()
+}
+
+func (rl *WithLogs) Stop() error {
+	return rl.inner.Stop()
+}
+
+func (rl *WithLogs) Shutdown() error {
+	return rl.inner.Shutdown()
+}
+
+func (rl *WithLogs) Running() bool {
+	return rl.inner.Running()
+}
+
+func (rl *WithLogs) Done() <-chan struct{} {
+	return rl.inner.Done()
+}
+
+func (rl *WithLogs) Exited() <-chan error {
+	if en, ok := rl.inner.(ctl.ExitNotifier); ok {
+		return en.Exited()
+	}
+	return nil
+}
+
+func (rl *WithLogs) Stdout() io.Reader {
+	return rl.stdout.r
+}
+func (rl *WithLogs) Stderr() io.Reader {
+	return rl.stderr.r
+}
+func (rl *WithLogs) Stdin() io.Writer {
+	return rl.inner.Stdin()
+}
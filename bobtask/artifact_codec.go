@@ -0,0 +1,103 @@
+package bobtask
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/mholt/archiver/v3"
+)
+
+// Codec is the compression applied to an artifact archive.
+type Codec string
+
+const (
+	CodecGzip   Codec = "gzip"
+	CodecZstd   Codec = "zstd"
+	CodecBrotli Codec = "brotli"
+	CodecNone   Codec = "none"
+
+	DefaultCodec = CodecGzip
+)
+
+var Codecs = []Codec{CodecGzip, CodecZstd, CodecBrotli, CodecNone}
+
+var ErrInvalidCodec = fmt.Errorf("invalid compression codec")
+
+// ParseCodec validates a codec name. An empty string results in the default codec.
+func ParseCodec(s string) (Codec, error) {
+	if s == "" {
+		return DefaultCodec, nil
+	}
+	for _, c := range Codecs {
+		if string(c) == s {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("%w [%s], expected one of %v", ErrInvalidCodec, s, Codecs)
+}
+
+func newArchiveWriter(codec Codec) archiver.Writer {
+	switch codec {
+	case CodecZstd:
+		return archiver.NewTarZstd()
+	case CodecBrotli:
+		return archiver.NewTarBrotli()
+	case CodecNone:
+		return archiver.NewTar()
+	default:
+		return archiver.NewTarGz()
+	}
+}
+
+func newArchiveReader(codec Codec) archiver.Reader {
+	switch codec {
+	case CodecZstd:
+		return archiver.NewTarZstd()
+	case CodecBrotli:
+		return archiver.NewTarBrotli()
+	case CodecNone:
+		return archiver.NewTar()
+	default:
+		return archiver.NewTarGz()
+	}
+}
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	tarMagic  = []byte("ustar")
+)
+
+// tarMagicOffset is the position of the magic field in a tar header.
+const tarMagicOffset = 257
+
+// openArchive detects the codec of an artifact from its leading bytes and
+// returns a reader ready to iterate the archive. Brotli has no magic number
+// and is assumed when no other codec matches.
+func openArchive(r io.Reader) (_ archiver.Reader, _ Codec, err error) {
+	br := bufio.NewReader(r)
+
+	codec := CodecBrotli
+	head, err := br.Peek(tarMagicOffset + len(tarMagic))
+	if err != nil && err != io.EOF {
+		return nil, "", err
+	}
+	switch {
+	case bytes.HasPrefix(head, gzipMagic):
+		codec = CodecGzip
+	case bytes.HasPrefix(head, zstdMagic):
+		codec = CodecZstd
+	case len(head) >= tarMagicOffset+len(tarMagic) && bytes.Equal(head[tarMagicOffset:], tarMagic):
+		codec = CodecNone
+	}
+
+	archiveReader := newArchiveReader(codec)
+	err = archiveReader.Open(br, 0)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return archiveReader, codec, nil
+}
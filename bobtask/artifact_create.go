@@ -8,6 +8,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,25 +17,22 @@ import (
 	"github.com/mholt/archiver/v3"
 	"gopkg.in/yaml.v3"
 
+	"github.com/benchkram/bob/bobtask/buildinfo"
 	"github.com/benchkram/bob/bobtask/hash"
 	"github.com/benchkram/bob/pkg/boblog"
+	"github.com/benchkram/bob/pkg/ocilayout"
+	"github.com/benchkram/bob/pkg/store"
+	"github.com/benchkram/bob/pkg/usererror"
 )
 
 const __targetsFilesystem = "targets/filesystem"
 const __targetsDocker = "targets/docker"
+const __targetsOCI = "targets/oci"
 const __metadata = "__metadata"
+const __manifest = "__manifest"
 
 var ErrInvalidTarHeaderType = fmt.Errorf("invalid tar header type")
 
-type archiveIO interface {
-	archiver.Writer
-	archiver.Reader
-}
-
-func newArchive() archiveIO             { return archiver.NewTarGz() } // TODO: use brotli
-func newArchiveWriter() archiver.Writer { return newArchive() }
-func newArchiveReader() archiver.Reader { return newArchive() }
-
 // ArtifactCreate create an archive for one or multiple targets
 func (t *Task) ArtifactCreate(artifactName hash.In) (err error) {
 	defer errz.Recover(&err)
@@ -48,6 +46,7 @@ func (t *Task) ArtifactCreate(artifactName hash.In) (err error) {
 	tt, err := t.Target()
 	errz.Fatal(err)
 	buildInfo, err := tt.BuildInfo()
+	errz.Fatal(err)
 
 	dockerTargets := []string{}
 	tempdir := ""
@@ -64,11 +63,17 @@ func (t *Task) ArtifactCreate(artifactName hash.In) (err error) {
 		defer func(dst string) { _ = os.Remove(dst) }(target)
 	}
 
+	// Regular files are stored as content addressable blobs in case the
+	// local store supports it. The artifact then only references them
+	// through its manifest, which avoids storing the same file twice.
+	blobStore, useBlobs := t.local.(store.BlobStore)
+	manifest := NewArtifactManifest()
+
 	artifact, err := t.local.NewArtifact(context.TODO(), artifactName.String(), 0)
 	errz.Fatal(err)
 	defer artifact.Close()
 
-	archiveWriter := newArchiveWriter()
+	archiveWriter := newArchiveWriter(t.Codec())
 	err = archiveWriter.Create(artifact)
 	errz.Fatal(err)
 	defer archiveWriter.Close()
@@ -76,7 +81,7 @@ func (t *Task) ArtifactCreate(artifactName hash.In) (err error) {
 	boblog.Log.V(3).Info(fmt.Sprintf("[task:%s] file in buildinfo %d", t.name, len(buildInfo.Filesystem.Files)))
 
 	// targets filesystem
-	for fname := range buildInfo.Filesystem.Files {
+	for fname, fileBuildInfo := range buildInfo.Filesystem.Files {
 		if target.ShouldIgnore(fname) {
 			continue
 		}
@@ -95,6 +100,19 @@ func (t *Task) ArtifactCreate(artifactName hash.In) (err error) {
 		internalName = strings.TrimPrefix(internalName, tempdir)
 		internalName = strings.TrimPrefix(internalName, "/")
 
+		if useBlobs && info.Mode().IsRegular() {
+			err = blobCreate(context.TODO(), blobStore, fileBuildInfo.Hash, fname, info.Size())
+			errz.Fatal(err)
+
+			manifest.Files = append(manifest.Files, ArtifactManifestFile{
+				Path: internalName,
+				Hash: fileBuildInfo.Hash,
+				Size: info.Size(),
+				Mode: info.Mode(),
+			})
+			continue
+		}
+
 		// archiver needs the source path in case of a symlink,
 		// so it can call `os.Readlink(source)`.
 		var source string
@@ -122,6 +140,23 @@ func (t *Task) ArtifactCreate(artifactName hash.In) (err error) {
 		errz.Fatal(err)
 	}
 
+	if len(manifest.Files) > 0 {
+		sort.Slice(manifest.Files, func(i, j int) bool {
+			return manifest.Files[i].Path < manifest.Files[j].Path
+		})
+		bin, err := yaml.Marshal(manifest)
+		errz.Fatal(err)
+
+		err = archiveWriter.Write(archiver.File{
+			FileInfo: fileInfo{
+				name: __manifest,
+				data: bin,
+			},
+			ReadCloser: io.NopCloser(bytes.NewBuffer(bin)),
+		})
+		errz.Fatal(err)
+	}
+
 	// targets docker
 	for _, fname := range dockerTargets {
 		info, err := os.Lstat(fname)
@@ -162,10 +197,17 @@ func (t *Task) ArtifactCreate(artifactName hash.In) (err error) {
 		errz.Fatal(err)
 	}
 
+	// targets oci
+	if image := tt.OCIImage(); image != nil {
+		err = t.ociLayoutCreate(archiveWriter, buildInfo.Filesystem.Files, image)
+		errz.Fatal(err)
+	}
+
 	metadata := NewArtifactMetadata()
 	metadata.Taskname = t.name
 	metadata.Project = t.Project()
 	metadata.InputHash = artifactName.String()
+	metadata.Codec = t.Codec()
 	bin, err := yaml.Marshal(metadata)
 	errz.Fatal(err)
 
@@ -181,6 +223,87 @@ func (t *Task) ArtifactCreate(artifactName hash.In) (err error) {
 	return nil
 }
 
+// blobCreate stores the file at path as blob in the given store.
+// Does nothing in case the blob already exists.
+func blobCreate(ctx context.Context, blobStore store.BlobStore, id, path string, size int64) (err error) {
+	defer errz.Recover(&err)
+
+	if blobStore.BlobExists(ctx, id) {
+		return nil
+	}
+
+	src, err := os.Open(path)
+	errz.Fatal(err)
+	defer src.Close()
+
+	dst, err := blobStore.NewBlob(ctx, id, size)
+	errz.Fatal(err)
+
+	_, err = io.Copy(dst, src)
+	if err != nil {
+		_ = dst.Close()
+		errz.Fatal(err)
+	}
+
+	return dst.Close()
+}
+
+// ociLayoutCreate creates an oci image layout from the filesystem targets
+// and adds it to the artifact. Files are placed in the image relative
+// to the task's directory.
+func (t *Task) ociLayoutCreate(archiveWriter archiver.Writer, files map[string]buildinfo.BuildInfoFile, image *ocilayout.Config) (err error) {
+	defer errz.Recover(&err)
+
+	layerFiles := map[string]string{}
+	for fname, info := range files {
+		// directories are created as parents of their files
+		if info.Size == -1 || target.ShouldIgnore(fname) {
+			continue
+		}
+		rel, err := filepath.Rel(t.dir, fname)
+		errz.Fatal(err)
+		layerFiles[rel] = fname
+	}
+
+	dir, err := os.MkdirTemp("", "bob-oci-")
+	errz.Fatal(err)
+	defer os.RemoveAll(dir)
+
+	boblog.Log.V(2).Info(fmt.Sprintf("[task:%s] creating oci image layout", t.name))
+	err = ocilayout.Create(dir, layerFiles, *image)
+	if err != nil {
+		return usererror.Wrapm(err, fmt.Sprintf("[task:%s] failed to create oci image", t.name))
+	}
+
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		return archiveWriter.Write(archiver.File{
+			FileInfo: archiver.FileInfo{
+				FileInfo:   info,
+				CustomName: filepath.Join(__targetsOCI, rel),
+			},
+			ReadCloser: file,
+		})
+	})
+}
+
 // saveDockerImageTargets calls `docker save` and returns a path to the tar archive.
 func (t *Task) saveDockerImageTargets(in []string) ([]string, error) {
 	targets := []string{}
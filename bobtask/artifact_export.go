@@ -0,0 +1,74 @@
+package bobtask
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/benchkram/errz"
+
+	"github.com/benchkram/bob/bobtask/hash"
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+var ErrNoOCIImage = fmt.Errorf("artifact does not contain an oci image")
+
+// ArtifactExportOCI writes the oci image layout stored
+// in the artifact to dst, existing files are overwritten.
+func (t *Task) ArtifactExportOCI(artifactName hash.In, dst string) (err error) {
+	defer errz.Recover(&err)
+
+	artifact, _, err := t.local.GetArtifact(context.TODO(), artifactName.String())
+	errz.Fatal(err)
+	defer artifact.Close()
+
+	archiveReader, _, err := openArchive(artifact)
+	errz.Fatal(err)
+	defer archiveReader.Close()
+
+	var found bool
+	for {
+		archiveFile, err := archiveReader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			errz.Fatal(err)
+		}
+
+		header, ok := archiveFile.Header.(*tar.Header)
+		if !ok {
+			return ErrInvalidTarHeaderType
+		}
+		if !strings.HasPrefix(header.Name, __targetsOCI+"/") {
+			continue
+		}
+		found = true
+
+		name := filepath.Clean(strings.TrimPrefix(header.Name, __targetsOCI+"/"))
+		if strings.HasPrefix(name, "..") || filepath.IsAbs(name) {
+			return fmt.Errorf("invalid path in artifact [%s]", header.Name)
+		}
+		path := filepath.Join(dst, name)
+
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+		errz.Fatal(err)
+
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, os.FileMode(header.Mode))
+		errz.Fatal(err)
+		_, err = io.Copy(f, archiveFile)
+		_ = f.Close()
+		errz.Fatal(err)
+	}
+
+	if !found {
+		return usererror.Wrapm(ErrNoOCIImage, fmt.Sprintf("[task:%s]", t.name))
+	}
+
+	return nil
+}
@@ -14,7 +14,9 @@ import (
 	"github.com/benchkram/bob/bobtask/hash"
 	"github.com/benchkram/bob/bobtask/target"
 	"github.com/benchkram/bob/pkg/boblog"
+	"github.com/benchkram/bob/pkg/store"
 	"github.com/benchkram/errz"
+	"gopkg.in/yaml.v3"
 )
 
 // ArtifactExtract extract an artifact from the localstore if it exists.
@@ -39,8 +41,7 @@ func (t *Task) ArtifactExtract(artifactName hash.In, invalidFiles map[string][]t
 	err = t.CleanTargetsWithReason(invalidFiles)
 	errz.Fatal(err)
 
-	archiveReader := newArchiveReader()
-	err = archiveReader.Open(artifact, 0)
+	archiveReader, _, err := openArchive(artifact)
 	errz.Fatal(err)
 	defer archiveReader.Close()
 
@@ -58,6 +59,23 @@ func (t *Task) ArtifactExtract(artifactName hash.In, invalidFiles map[string][]t
 			return false, ErrInvalidTarHeaderType
 		}
 
+		// targets filesystem stored as blobs
+		if header.Name == __manifest {
+			bin, err := io.ReadAll(archiveFile)
+			errz.Fatal(err)
+
+			manifest := NewArtifactManifest()
+			err = yaml.Unmarshal(bin, manifest)
+			errz.Fatal(err)
+
+			ok, err := t.extractManifest(manifest, invalidFiles)
+			errz.Fatal(err)
+			if !ok {
+				return false, nil
+			}
+			continue
+		}
+
 		// targets filesystem
 		if strings.HasPrefix(header.Name, __targetsFilesystem) {
 			filename := strings.TrimPrefix(header.Name, __targetsFilesystem+"/")
@@ -132,6 +150,72 @@ func (t *Task) ArtifactExtract(artifactName hash.In, invalidFiles map[string][]t
 	return true, nil
 }
 
+// extractManifest restores the files listed in the manifest from the blob store.
+// Returns false in case a referenced blob is not available.
+func (t *Task) extractManifest(manifest *ArtifactManifest, invalidFiles map[string][]target.Reason) (_ bool, err error) {
+	defer errz.Recover(&err)
+
+	blobStore, ok := t.local.(store.BlobStore)
+	if !ok {
+		return false, ErrBlobStoreRequired
+	}
+
+	for _, id := range manifest.Blobs() {
+		if !blobStore.BlobExists(context.TODO(), id) {
+			boblog.Log.V(2).Info(fmt.Sprintf("[task:%s] blob [%s] does not exist in localstore", t.name, id))
+			return false, nil
+		}
+	}
+
+	for _, f := range manifest.Files {
+		if !shouldFetchFromCache(f.Path, invalidFiles) {
+			continue
+		}
+
+		// create directory structure
+		dir := filepath.Dir(f.Path)
+		if dir != "." && dir != "/" {
+			err = os.MkdirAll(filepath.Join(t.dir, dir), 0775)
+			errz.Fatal(err)
+		}
+
+		dst := filepath.Join(t.dir, f.Path)
+
+		blob, _, err := blobStore.GetBlob(context.TODO(), f.Hash)
+		errz.Fatal(err)
+
+		err = extractBlob(blob, dst, f.Mode, f.Size)
+		_ = blob.Close()
+		errz.Fatal(err)
+	}
+
+	return true, nil
+}
+
+// extractBlob copies a blob to dst and assures the expected size was written.
+func extractBlob(blob io.Reader, dst string, mode os.FileMode, size int64) error {
+	// remove a possible symlink or read only file before writing.
+	_ = os.Remove(dst)
+
+	f, err := os.OpenFile(dst, os.O_RDWR|os.O_CREATE|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+
+	n, err := io.Copy(f, blob)
+	// closing the file right away to reduce the number of open files
+	_ = f.Close()
+	if err != nil {
+		return err
+	}
+
+	if n != size {
+		return fmt.Errorf("%w [file: %s, expected size: %d, actual size: %d]", ErrCorruptedBlob, dst, size, n)
+	}
+
+	return nil
+}
+
 // shouldFetchFromCache checks if a file should be brought back from cache inside the target
 // A file will be brought back from cache if it's missing or was changed
 func shouldFetchFromCache(filename string, invalidFiles map[string][]target.Reason) bool {
@@ -10,6 +10,7 @@ import (
 
 type ArtifactInfo interface {
 	Metadata() *ArtifactMetadata
+	Manifest() *ArtifactManifest
 	String() string
 	Types() []targettype.T
 }
@@ -23,13 +24,21 @@ type artifactInfo struct {
 	// targetsDocker stored in a local docker registry
 	targetsDocker []string
 
+	// targetsOCI are the files of an oci image layout
+	targetsOCI []string
+
 	metadata *ArtifactMetadata
+
+	// manifest lists filesystem targets stored as blobs
+	manifest *ArtifactManifest
 }
 
 func newArtifactInfo() *artifactInfo {
 	ai := &artifactInfo{
 		targetsFilesystem: []string{},
 		targetsDocker:     []string{},
+		targetsOCI:        []string{},
+		manifest:          NewArtifactManifest(),
 	}
 	return ai
 }
@@ -38,6 +47,10 @@ func (ai *artifactInfo) Metadata() *ArtifactMetadata {
 	return ai.metadata
 }
 
+func (ai *artifactInfo) Manifest() *ArtifactManifest {
+	return ai.manifest
+}
+
 func (ai *artifactInfo) Types() []targettype.T {
 	ts := []targettype.T{}
 
@@ -47,6 +60,9 @@ func (ai *artifactInfo) Types() []targettype.T {
 	if len(ai.targetsDocker) > 0 {
 		ts = append(ts, targettype.Docker)
 	}
+	if len(ai.targetsOCI) > 0 {
+		ts = append(ts, targettype.OCI)
+	}
 	return ts
 }
 
@@ -68,6 +84,22 @@ func (ai *artifactInfo) String() string {
 		fmt.Fprintf(buf, "%s%s\n", i, t)
 	}
 
+	if len(ai.targetsOCI) > 0 {
+		fmt.Fprintf(buf, "%s%s\n", indent, "oci targets:")
+		i = indent + "  "
+		for _, t := range ai.targetsOCI {
+			fmt.Fprintf(buf, "%s%s\n", i, t)
+		}
+	}
+
+	if blobs := ai.manifest.Blobs(); len(blobs) > 0 {
+		fmt.Fprintf(buf, "%s%s\n", indent, "blobs:")
+		i = indent + "  "
+		for _, b := range blobs {
+			fmt.Fprintf(buf, "%s%s\n", i, b)
+		}
+	}
+
 	fmt.Fprintf(buf, "%s%s\n", indent, "metadata:")
 	i = indent + "  "
 	if ai.metadata != nil {
@@ -75,6 +107,12 @@ func (ai *artifactInfo) String() string {
 		fmt.Fprintf(buf, "%s%s%s\n", i, "inputHash: ", ai.metadata.InputHash)
 		fmt.Fprintf(buf, "%s%s%s\n", i, "project: ", ai.metadata.Project)
 		fmt.Fprintf(buf, "%s%s%s\n", i, "createdAt: ", ai.metadata.CreatedAt.Format(time.RFC822Z))
+		// artifacts created before codecs were selectable are gzip compressed
+		codec := ai.metadata.Codec
+		if codec == "" {
+			codec = CodecGzip
+		}
+		fmt.Fprintf(buf, "%s%s%s\n", i, "codec: ", codec)
 	}
 
 	return buf.String()
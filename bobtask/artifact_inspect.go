@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"path/filepath"
 	"strings"
 
 	"github.com/benchkram/bob/bobtask/hash"
@@ -38,8 +39,7 @@ func (t *Task) ArtifactInspect(artifactNames ...hash.In) (ai ArtifactInfo, err e
 	}
 	defer artifact.Close()
 
-	archiveReader := newArchiveReader()
-	err = archiveReader.Open(artifact, 0)
+	archiveReader, _, err := openArchive(artifact)
 	errz.Fatal(err)
 	defer archiveReader.Close()
 
@@ -54,8 +54,7 @@ func (t *Task) ArtifactInspect(artifactNames ...hash.In) (ai ArtifactInfo, err e
 func ArtifactInspectFromReader(reader io.ReadCloser) (_ ArtifactInfo, err error) {
 	defer errz.Recover(&err)
 
-	archiveReader := newArchiveReader()
-	err = archiveReader.Open(reader, 0)
+	archiveReader, _, err := openArchive(reader)
 	errz.Fatal(err)
 	defer archiveReader.Close()
 
@@ -84,6 +83,20 @@ func artifactInspect(archiveReader archiver.Reader) (_ *artifactInfo, err error)
 			info.targetsFilesystem = append(info.targetsFilesystem, header.Name)
 		} else if strings.HasPrefix(header.Name, __targetsDocker) {
 			info.targetsDocker = append(info.targetsDocker, header.Name)
+		} else if strings.HasPrefix(header.Name, __targetsOCI) {
+			info.targetsOCI = append(info.targetsOCI, header.Name)
+		} else if header.Name == __manifest {
+			bin, err := io.ReadAll(archiveFile)
+			errz.Fatal(err)
+
+			manifest := NewArtifactManifest()
+			err = yaml.Unmarshal(bin, manifest)
+			errz.Fatal(err)
+
+			for _, f := range manifest.Files {
+				info.targetsFilesystem = append(info.targetsFilesystem, filepath.Join(__targetsFilesystem, f.Path))
+			}
+			info.manifest = manifest
 		} else if strings.HasPrefix(header.Name, __metadata) {
 			bin, err := io.ReadAll(archiveFile)
 			errz.Fatal(err)
@@ -0,0 +1,48 @@
+package bobtask
+
+import (
+	"os"
+	"sort"
+)
+
+// ArtifactManifest lists the regular files of a filesystem target.
+// The content of each file is stored only once as a blob in a
+// content addressable store, indexed by its content hash.
+type ArtifactManifest struct {
+	Files []ArtifactManifestFile `yaml:"files"`
+}
+
+type ArtifactManifestFile struct {
+	// Path of the file relative to the task directory
+	Path string `yaml:"path"`
+
+	// Hash of the file content, used as blob id
+	Hash string `yaml:"hash"`
+
+	// Size of the file
+	Size int64 `yaml:"size"`
+
+	// Mode of the file
+	Mode os.FileMode `yaml:"mode"`
+}
+
+func NewArtifactManifest() *ArtifactManifest {
+	return &ArtifactManifest{
+		Files: []ArtifactManifestFile{},
+	}
+}
+
+// Blobs returns the unique blob ids referenced by the manifest.
+func (m *ArtifactManifest) Blobs() []string {
+	blobs := []string{}
+	seen := make(map[string]struct{})
+	for _, f := range m.Files {
+		if _, ok := seen[f.Hash]; ok {
+			continue
+		}
+		seen[f.Hash] = struct{}{}
+		blobs = append(blobs, f.Hash)
+	}
+	sort.Strings(blobs)
+	return blobs
+}
@@ -16,6 +16,9 @@ type ArtifactMetadata struct {
 
 	// CreatedAt timestamp the artifact was created
 	CreatedAt time.Time `yaml:"created_at,omitempty"`
+
+	// Codec used to compress the artifact
+	Codec Codec `yaml:"codec,omitempty"`
 }
 
 func NewArtifactMetadata() *ArtifactMetadata {
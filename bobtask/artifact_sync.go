@@ -0,0 +1,227 @@
+package bobtask
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/benchkram/bob/pkg/store"
+	"github.com/benchkram/errz"
+	"github.com/mholt/archiver/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// ArtifactSync syncs an artifact and the blobs it references from the src to the dst store.
+//
+// In case both stores are able to store blobs, missing blobs are synced
+// before the artifact itself. In case dst can't store blobs a self-contained
+// artifact is packed which contains the content of the referenced blobs.
+//
+// Error handling is the same as for store.Sync().
+func ArtifactSync(ctx context.Context, src, dst store.Store, id string, ignoreAlreadyExists bool) (err error) {
+	defer errz.Recover(&err)
+
+	srcBlobs, ok := src.(store.BlobStore)
+	if !ok {
+		// artifacts of a store without blobs are self-contained.
+		return store.Sync(ctx, src, dst, id, ignoreAlreadyExists)
+	}
+
+	found, err := store.Exists(ctx, src, id)
+	errz.Fatal(err)
+	if !found {
+		return store.ErrArtifactNotFoundinSrc
+	}
+
+	if !ignoreAlreadyExists {
+		found, err = store.Exists(ctx, dst, id)
+		errz.Fatal(err)
+		if found {
+			return store.ErrArtifactAlreadyExists
+		}
+	}
+
+	// the artifact is downloaded once to read its manifest and to write it to dst
+	artifact, err := os.CreateTemp("", "bob-artifact-*")
+	errz.Fatal(err)
+	defer func() {
+		_ = artifact.Close()
+		_ = os.Remove(artifact.Name())
+	}()
+
+	err = artifactDownload(ctx, src, id, artifact)
+	errz.Fatal(err)
+
+	info, err := ArtifactInspectFromReader(artifact)
+	errz.Fatal(err)
+	manifest := info.Manifest()
+
+	_, err = artifact.Seek(0, io.SeekStart)
+	errz.Fatal(err)
+
+	if dstBlobs, ok := dst.(store.BlobStore); ok || len(manifest.Files) == 0 {
+		for _, blob := range manifest.Blobs() {
+			err = store.SyncBlob(ctx, srcBlobs, dstBlobs, blob)
+			errz.Fatal(err)
+		}
+		err = artifactUpload(ctx, dst, id, artifact)
+		errz.Fatal(err)
+		return src.Done()
+	}
+
+	// Pack the artifact to a temporary file first,
+	// as the size of the artifact must be known upfront.
+	tmp, err := os.CreateTemp("", "bob-artifact-*")
+	errz.Fatal(err)
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	err = artifactPack(ctx, artifact, srcBlobs, tmp)
+	errz.Fatal(err)
+
+	_, err = tmp.Seek(0, io.SeekStart)
+	errz.Fatal(err)
+	err = artifactUpload(ctx, dst, id, tmp)
+	errz.Fatal(err)
+
+	return src.Done()
+}
+
+// artifactDownload copies an artifact from the store to f
+// and rewinds f to its beginning.
+func artifactDownload(ctx context.Context, s store.Store, id string, f *os.File) (err error) {
+	defer errz.Recover(&err)
+
+	artifact, _, err := s.GetArtifact(ctx, id)
+	errz.Fatal(err)
+	defer artifact.Close()
+
+	_, err = io.Copy(f, artifact)
+	errz.Fatal(err)
+
+	_, err = f.Seek(0, io.SeekStart)
+	return err
+}
+
+// artifactUpload writes the content of f, starting
+// at its current offset, as artifact to the store.
+func artifactUpload(ctx context.Context, s store.Store, id string, f *os.File) (err error) {
+	defer errz.Recover(&err)
+
+	info, err := f.Stat()
+	errz.Fatal(err)
+	offset, err := f.Seek(0, io.SeekCurrent)
+	errz.Fatal(err)
+
+	w, err := s.NewArtifact(ctx, id, info.Size()-offset)
+	errz.Fatal(err)
+
+	_, err = io.Copy(w, f)
+	if err != nil {
+		_ = w.Close()
+		errz.Fatal(err)
+	}
+	return w.Close()
+}
+
+// artifactPack writes a self-contained version of the artifact to w.
+// Files referenced through the manifest are inlined from the blob store.
+func artifactPack(ctx context.Context, artifact io.Reader, blobs store.BlobStore, w io.Writer) (err error) {
+	defer errz.Recover(&err)
+
+	// the packed artifact keeps the codec of the original
+	archiveReader, codec, err := openArchive(artifact)
+	errz.Fatal(err)
+	defer archiveReader.Close()
+
+	archiveWriter := newArchiveWriter(codec)
+	err = archiveWriter.Create(w)
+	errz.Fatal(err)
+	defer archiveWriter.Close()
+
+	// symlinks can only be written by the archiver
+	// when they exist on the filesystem.
+	symlinkDir, err := os.MkdirTemp("", "bob-artifact-symlinks-*")
+	errz.Fatal(err)
+	defer os.RemoveAll(symlinkDir)
+
+	for {
+		archiveFile, err := archiveReader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			errz.Fatal(err)
+		}
+
+		header, ok := archiveFile.Header.(*tar.Header)
+		if !ok {
+			return ErrInvalidTarHeaderType
+		}
+
+		if header.Name == __manifest {
+			bin, err := io.ReadAll(archiveFile)
+			errz.Fatal(err)
+
+			manifest := NewArtifactManifest()
+			err = yaml.Unmarshal(bin, manifest)
+			errz.Fatal(err)
+
+			for _, f := range manifest.Files {
+				blob, _, err := blobs.GetBlob(ctx, f.Hash)
+				errz.Fatal(err)
+
+				err = archiveWriter.Write(archiver.File{
+					FileInfo: blobFileInfo{
+						name: filepath.Join(__targetsFilesystem, f.Path),
+						size: f.Size,
+						mode: f.Mode,
+					},
+					ReadCloser: blob,
+				})
+				_ = blob.Close()
+				errz.Fatal(err)
+			}
+			continue
+		}
+
+		var source string
+		if header.Typeflag == tar.TypeSymlink {
+			source = filepath.Join(symlinkDir, filepath.Base(header.Name))
+			_ = os.Remove(source)
+			err = os.Symlink(header.Linkname, source)
+			errz.Fatal(err)
+		}
+
+		err = archiveWriter.Write(archiver.File{
+			FileInfo: archiver.FileInfo{
+				FileInfo:   archiveFile.FileInfo,
+				CustomName: header.Name,
+				SourcePath: source,
+			},
+			ReadCloser: archiveFile.ReadCloser,
+		})
+		errz.Fatal(err)
+	}
+
+	return nil
+}
+
+type blobFileInfo struct {
+	name string
+	size int64
+	mode os.FileMode
+}
+
+func (bfi blobFileInfo) Name() string       { return bfi.name }
+func (bfi blobFileInfo) Size() int64        { return bfi.size }
+func (bfi blobFileInfo) Mode() os.FileMode  { return bfi.mode }
+func (bfi blobFileInfo) ModTime() time.Time { return time.Now() }
+func (bfi blobFileInfo) IsDir() bool        { return false }
+func (bfi blobFileInfo) Sys() interface{}   { return nil }
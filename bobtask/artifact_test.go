@@ -1,12 +1,15 @@
 package bobtask
 
 import (
+	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/benchkram/bob/pkg/buildinfostore"
 	"github.com/benchkram/bob/pkg/file"
+	"github.com/benchkram/bob/pkg/store"
 	"github.com/benchkram/bob/pkg/store/filestore"
 	"github.com/benchkram/errz"
 	"github.com/stretchr/testify/assert"
@@ -64,3 +67,235 @@ func TestArtifactCreateAndExtract(t *testing.T) {
 	_, err = tsk.ArtifactInspect("aaa")
 	assert.Nil(t, err)
 }
+
+func TestArtifactDeduplication(t *testing.T) {
+	testdir, err := os.MkdirTemp("", "test-artifact-deduplication")
+	assert.Nil(t, err)
+	storage, err := os.MkdirTemp("", "test-artifact-deduplication-store")
+	assert.Nil(t, err)
+	buildinfoStorage, err := os.MkdirTemp("", "test-artifact-deduplication-buildinfo-store")
+	assert.Nil(t, err)
+	defer func() {
+		os.RemoveAll(testdir)
+		os.RemoveAll(storage)
+		os.RemoveAll(buildinfoStorage)
+	}()
+
+	artifactStore := filestore.New(storage)
+	blobStore := artifactStore.(store.BlobStore)
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(testdir, ".bbuild"), 0774))
+	assert.Nil(t, os.WriteFile(filepath.Join(testdir, ".bbuild/fileone"), []byte("fileone"), 0774))
+	assert.Nil(t, os.WriteFile(filepath.Join(testdir, ".bbuild/filetwo"), []byte("filetwo"), 0774))
+
+	tsk := Make()
+	tsk.dir = testdir
+	tsk.local = artifactStore
+	tsk.buildInfoStore = buildinfostore.NewProtoStore(buildinfoStorage)
+	tsk.name = "mytaskname"
+	tsk.TargetDirty = ".bbuild/"
+	assert.Nil(t, tsk.parseTargets())
+
+	assert.Nil(t, tsk.ArtifactCreate("aaa"))
+	blobs, err := blobStore.ListBlobs(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(blobs))
+
+	// change a single file, only one additional blob must be stored.
+	assert.Nil(t, os.WriteFile(filepath.Join(testdir, ".bbuild/filetwo"), []byte("filetwo-changed"), 0774))
+	assert.Nil(t, tsk.ArtifactCreate("bbb"))
+	blobs, err = blobStore.ListBlobs(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(blobs))
+
+	info, err := tsk.ArtifactInspect("aaa")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(info.Manifest().Files))
+
+	// extract the first artifact and assure the original content is restored.
+	assert.Nil(t, os.RemoveAll(filepath.Join(testdir, ".bbuild")))
+	success, err := tsk.ArtifactExtract("aaa", nil)
+	assert.Nil(t, err)
+	assert.True(t, success)
+
+	content, err := os.ReadFile(filepath.Join(testdir, ".bbuild/filetwo"))
+	assert.Nil(t, err)
+	assert.Equal(t, "filetwo", string(content))
+}
+
+// blobless hides the blob capabilities of a store.
+type blobless struct {
+	store.Store
+}
+
+func TestArtifactSyncToStoreWithoutBlobs(t *testing.T) {
+	testdir, err := os.MkdirTemp("", "test-artifact-sync")
+	assert.Nil(t, err)
+	storage, err := os.MkdirTemp("", "test-artifact-sync-store")
+	assert.Nil(t, err)
+	remoteStorage, err := os.MkdirTemp("", "test-artifact-sync-remote-store")
+	assert.Nil(t, err)
+	buildinfoStorage, err := os.MkdirTemp("", "test-artifact-sync-buildinfo-store")
+	assert.Nil(t, err)
+	defer func() {
+		os.RemoveAll(testdir)
+		os.RemoveAll(storage)
+		os.RemoveAll(remoteStorage)
+		os.RemoveAll(buildinfoStorage)
+	}()
+
+	local := filestore.New(storage)
+	remote := blobless{filestore.New(remoteStorage)}
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(testdir, ".bbuild"), 0774))
+	assert.Nil(t, os.WriteFile(filepath.Join(testdir, ".bbuild/fileone"), []byte("fileone"), 0774))
+	assert.Nil(t, os.Symlink("fileone", filepath.Join(testdir, ".bbuild/link")))
+
+	tsk := Make()
+	tsk.dir = testdir
+	tsk.local = local
+	tsk.buildInfoStore = buildinfostore.NewProtoStore(buildinfoStorage)
+	tsk.name = "mytaskname"
+	tsk.TargetDirty = ".bbuild/"
+	assert.Nil(t, tsk.parseTargets())
+
+	assert.Nil(t, tsk.ArtifactCreate("aaa"))
+
+	// push to a store without blobs packs the artifact
+	assert.Nil(t, ArtifactSync(context.Background(), local, remote, "aaa", false))
+	assert.ErrorIs(t, ArtifactSync(context.Background(), local, remote, "aaa", false), store.ErrArtifactAlreadyExists)
+
+	artifact, _, err := remote.GetArtifact(context.Background(), "aaa")
+	assert.Nil(t, err)
+	info, err := ArtifactInspectFromReader(artifact)
+	assert.Nil(t, err)
+	assert.Nil(t, artifact.Close())
+	assert.Equal(t, 0, len(info.Manifest().Files))
+
+	// extract the packed artifact with a task using the blobless store.
+	assert.Nil(t, os.RemoveAll(filepath.Join(testdir, ".bbuild")))
+	tsk.local = remote
+	success, err := tsk.ArtifactExtract("aaa", nil)
+	assert.Nil(t, err)
+	assert.True(t, success)
+
+	content, err := os.ReadFile(filepath.Join(testdir, ".bbuild/link"))
+	assert.Nil(t, err)
+	assert.Equal(t, "fileone", string(content))
+}
+
+// downloadCounter counts the artifacts read from a store.
+type downloadCounter struct {
+	store.Store
+	store.BlobStore
+	downloads int
+}
+
+func (c *downloadCounter) GetArtifact(ctx context.Context, id string) (io.ReadCloser, int64, error) {
+	c.downloads++
+	return c.Store.GetArtifact(ctx, id)
+}
+
+func TestArtifactSyncDownloadsOnce(t *testing.T) {
+	testdir, err := os.MkdirTemp("", "test-artifact-sync-once")
+	assert.Nil(t, err)
+	storage, err := os.MkdirTemp("", "test-artifact-sync-once-store")
+	assert.Nil(t, err)
+	remoteStorage, err := os.MkdirTemp("", "test-artifact-sync-once-remote-store")
+	assert.Nil(t, err)
+	packedStorage, err := os.MkdirTemp("", "test-artifact-sync-once-packed-store")
+	assert.Nil(t, err)
+	buildinfoStorage, err := os.MkdirTemp("", "test-artifact-sync-once-buildinfo-store")
+	assert.Nil(t, err)
+	defer func() {
+		os.RemoveAll(testdir)
+		os.RemoveAll(storage)
+		os.RemoveAll(remoteStorage)
+		os.RemoveAll(packedStorage)
+		os.RemoveAll(buildinfoStorage)
+	}()
+
+	local := filestore.New(storage)
+	remote := filestore.New(remoteStorage)
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(testdir, ".bbuild"), 0774))
+	assert.Nil(t, os.WriteFile(filepath.Join(testdir, ".bbuild/fileone"), []byte("fileone"), 0774))
+
+	tsk := Make()
+	tsk.dir = testdir
+	tsk.local = local
+	tsk.buildInfoStore = buildinfostore.NewProtoStore(buildinfoStorage)
+	tsk.name = "mytaskname"
+	tsk.TargetDirty = ".bbuild/"
+	assert.Nil(t, tsk.parseTargets())
+
+	assert.Nil(t, tsk.ArtifactCreate("aaa"))
+
+	src := &downloadCounter{Store: local, BlobStore: local.(store.BlobStore)}
+	assert.Nil(t, ArtifactSync(context.Background(), src, remote, "aaa", false))
+	assert.Equal(t, 1, src.downloads)
+
+	// packing for a store without blobs reuses the download as well
+	src.downloads = 0
+	assert.Nil(t, ArtifactSync(context.Background(), src, blobless{filestore.New(packedStorage)}, "aaa", false))
+	assert.Equal(t, 1, src.downloads)
+}
+
+func TestArtifactCodecs(t *testing.T) {
+	for _, codec := range Codecs {
+		t.Run(string(codec), func(t *testing.T) {
+			testdir, err := os.MkdirTemp("", "test-artifact-codecs")
+			assert.Nil(t, err)
+			storage, err := os.MkdirTemp("", "test-artifact-codecs-store")
+			assert.Nil(t, err)
+			buildinfoStorage, err := os.MkdirTemp("", "test-artifact-codecs-buildinfo-store")
+			assert.Nil(t, err)
+			defer func() {
+				os.RemoveAll(testdir)
+				os.RemoveAll(storage)
+				os.RemoveAll(buildinfoStorage)
+			}()
+
+			// use a store without blobs so the files end up in the archive
+			assert.Nil(t, os.MkdirAll(filepath.Join(testdir, ".bbuild"), 0774))
+			assert.Nil(t, os.WriteFile(filepath.Join(testdir, ".bbuild/fileone"), []byte("fileone"), 0774))
+
+			tsk := Make()
+			tsk.dir = testdir
+			tsk.local = blobless{filestore.New(storage)}
+			tsk.buildInfoStore = buildinfostore.NewProtoStore(buildinfoStorage)
+			tsk.name = "mytaskname"
+			tsk.TargetDirty = ".bbuild/"
+			tsk.SetCodec(codec)
+			assert.Nil(t, tsk.parseTargets())
+
+			assert.Nil(t, tsk.ArtifactCreate("aaa"))
+
+			info, err := tsk.ArtifactInspect("aaa")
+			assert.Nil(t, err)
+			assert.Equal(t, codec, info.Metadata().Codec)
+
+			assert.Nil(t, os.RemoveAll(filepath.Join(testdir, ".bbuild")))
+			success, err := tsk.ArtifactExtract("aaa", nil)
+			assert.Nil(t, err)
+			assert.True(t, success)
+
+			content, err := os.ReadFile(filepath.Join(testdir, ".bbuild/fileone"))
+			assert.Nil(t, err)
+			assert.Equal(t, "fileone", string(content))
+		})
+	}
+}
+
+func TestParseCodec(t *testing.T) {
+	codec, err := ParseCodec("")
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultCodec, codec)
+
+	codec, err = ParseCodec("zstd")
+	assert.Nil(t, err)
+	assert.Equal(t, CodecZstd, codec)
+
+	_, err = ParseCodec("lzma")
+	assert.ErrorIs(t, err, ErrInvalidCodec)
+}
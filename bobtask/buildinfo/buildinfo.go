@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/benchkram/bob/bobtask/buildinfo/protos"
 )
@@ -14,11 +15,15 @@ type I struct {
 
 	// Target aggregates buildinfos of multiple files or docker images
 	Target Targets
+
+	// Inputs the target was created from
+	Inputs Inputs
 }
 
 func New() *I {
 	return &I{
 		Target: MakeTargets(),
+		Inputs: MakeInputs(),
 	}
 }
 
@@ -28,6 +33,9 @@ func (i *I) Describe() string {
 	fmt.Fprintln(buf, "Meta:")
 	fmt.Fprintln(buf, "\ttask:", i.Meta.Task)
 	fmt.Fprintln(buf, "\tinput hash", i.Meta.InputHash)
+	if i.Meta.CreatedAt != 0 {
+		fmt.Fprintln(buf, "\tcreated at", time.Unix(0, i.Meta.CreatedAt).Format(time.RFC3339))
+	}
 
 	fmt.Fprintln(buf, "Filesystem-Targets:")
 	fmt.Fprintln(buf, "\thash of all files", i.Target.Filesystem.Hash)
@@ -45,6 +53,8 @@ func (i *I) Describe() string {
 		fmt.Fprintln(buf, "\t", filename, v.Size, v.Hash)
 	}
 
+	i.Inputs.describe(buf)
+
 	return buf.String()
 }
 
@@ -98,6 +108,9 @@ type Meta struct {
 
 	// InputHash used for target creation
 	InputHash string `yaml:"input_hash"`
+
+	// CreatedAt is the creation time in unix nanoseconds
+	CreatedAt int64 `yaml:"created_at"`
 }
 
 func (i *I) ToProto(inputHash string) *protos.BuildInfo {
@@ -118,11 +131,13 @@ func (i *I) ToProto(inputHash string) *protos.BuildInfo {
 		Meta: &protos.Meta{
 			Task:      i.Meta.Task,
 			InputHash: inputHash,
+			CreatedAt: i.Meta.CreatedAt,
 		},
 		Target: &protos.Targets{
 			Filesystem: filesystem,
 			Docker:     docker,
 		},
+		Inputs: i.Inputs.toProto(),
 	}
 }
 
@@ -136,6 +151,11 @@ func FromProto(p *protos.BuildInfo) *I {
 	if p.Meta != nil {
 		bi.Meta.Task = p.Meta.Task
 		bi.Meta.InputHash = p.Meta.InputHash
+		bi.Meta.CreatedAt = p.Meta.CreatedAt
+	}
+
+	if p.Inputs != nil {
+		bi.Inputs = inputsFromProto(p.Inputs)
 	}
 
 	if p.Target != nil {
@@ -0,0 +1,191 @@
+package buildinfo
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/benchkram/bob/bobtask/buildinfo/protos"
+)
+
+// Inputs is a manifest of everything influencing the input hash of a task.
+type Inputs struct {
+	// Files maps input files to the hash of their content
+	Files map[string]string `yaml:"files"`
+	// Env maps environment variables to the hash of their value.
+	// Values are not stored to avoid leaking secrets.
+	Env map[string]string `yaml:"env"`
+
+	Cmds         []string `yaml:"cmds"`
+	Dependencies []string `yaml:"dependencies"`
+	Nixpkgs      string   `yaml:"nixpkgs"`
+	Targets      []string `yaml:"targets"`
+}
+
+func NewInputs() *Inputs {
+	return &Inputs{
+		Files:        make(map[string]string),
+		Env:          make(map[string]string),
+		Cmds:         []string{},
+		Dependencies: []string{},
+		Targets:      []string{},
+	}
+}
+
+func MakeInputs() Inputs {
+	return *NewInputs()
+}
+
+// Empty is true for build infos created before inputs were recorded.
+func (i *Inputs) Empty() bool {
+	return len(i.Files) == 0 && len(i.Env) == 0 && len(i.Cmds) == 0 &&
+		len(i.Dependencies) == 0 && i.Nixpkgs == "" && len(i.Targets) == 0
+}
+
+// Changes lists the differences of a single kind of input.
+type Changes struct {
+	Added    []string
+	Removed  []string
+	Modified []string
+}
+
+func (c *Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0
+}
+
+// InputsDiff describes how inputs changed in comparison to a previous build.
+type InputsDiff struct {
+	Files        Changes
+	Env          Changes
+	Cmds         Changes
+	Dependencies Changes
+	Targets      Changes
+
+	// Nixpkgs is set when the nixpkgs url changed
+	Nixpkgs *[2]string
+}
+
+func (d *InputsDiff) Empty() bool {
+	return d.Files.Empty() && d.Env.Empty() && d.Cmds.Empty() &&
+		d.Dependencies.Empty() && d.Targets.Empty() && d.Nixpkgs == nil
+}
+
+// Diff compares the inputs against the inputs of a previous build.
+func (i *Inputs) Diff(previous *Inputs) InputsDiff {
+	d := InputsDiff{
+		Files:        diffMaps(previous.Files, i.Files),
+		Env:          diffMaps(previous.Env, i.Env),
+		Cmds:         diffLists(previous.Cmds, i.Cmds),
+		Dependencies: diffLists(previous.Dependencies, i.Dependencies),
+		Targets:      diffLists(previous.Targets, i.Targets),
+	}
+	if previous.Nixpkgs != i.Nixpkgs {
+		d.Nixpkgs = &[2]string{previous.Nixpkgs, i.Nixpkgs}
+	}
+	return d
+}
+
+func diffMaps(old, new map[string]string) (c Changes) {
+	for k, v := range new {
+		ov, ok := old[k]
+		if !ok {
+			c.Added = append(c.Added, k)
+		} else if ov != v {
+			c.Modified = append(c.Modified, k)
+		}
+	}
+	for k := range old {
+		if _, ok := new[k]; !ok {
+			c.Removed = append(c.Removed, k)
+		}
+	}
+
+	sort.Strings(c.Added)
+	sort.Strings(c.Removed)
+	sort.Strings(c.Modified)
+	return c
+}
+
+// diffLists compares two lists as multisets. Order
+// changes are not reported.
+func diffLists(old, new []string) (c Changes) {
+	count := make(map[string]int, len(old))
+	for _, v := range old {
+		count[v]++
+	}
+	for _, v := range new {
+		if count[v] > 0 {
+			count[v]--
+			continue
+		}
+		c.Added = append(c.Added, v)
+	}
+	for _, v := range old {
+		if count[v] > 0 {
+			count[v]--
+			c.Removed = append(c.Removed, v)
+		}
+	}
+	return c
+}
+
+func (i *Inputs) describe(w io.Writer) {
+	fmt.Fprintln(w, "Inputs:")
+	fmt.Fprintln(w, "\t# of files", len(i.Files))
+	fmt.Fprintln(w, "\tfiles:")
+	for _, k := range sortedKeys(i.Files) {
+		fmt.Fprintln(w, "\t", k, i.Files[k])
+	}
+	fmt.Fprintln(w, "\tenv:")
+	for _, k := range sortedKeys(i.Env) {
+		fmt.Fprintln(w, "\t", k, i.Env[k])
+	}
+	fmt.Fprintln(w, "\tcmds:")
+	for _, v := range i.Cmds {
+		fmt.Fprintln(w, "\t", v)
+	}
+	fmt.Fprintln(w, "\tdependencies:")
+	for _, v := range i.Dependencies {
+		fmt.Fprintln(w, "\t", v)
+	}
+	fmt.Fprintln(w, "\tnixpkgs", i.Nixpkgs)
+	fmt.Fprintln(w, "\ttargets:")
+	for _, v := range i.Targets {
+		fmt.Fprintln(w, "\t", v)
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (i *Inputs) toProto() *protos.Inputs {
+	return &protos.Inputs{
+		Files:        i.Files,
+		Env:          i.Env,
+		Cmds:         i.Cmds,
+		Dependencies: i.Dependencies,
+		Nixpkgs:      i.Nixpkgs,
+		Targets:      i.Targets,
+	}
+}
+
+func inputsFromProto(p *protos.Inputs) Inputs {
+	i := MakeInputs()
+	for k, v := range p.Files {
+		i.Files[k] = v
+	}
+	for k, v := range p.Env {
+		i.Env[k] = v
+	}
+	i.Cmds = append(i.Cmds, p.Cmds...)
+	i.Dependencies = append(i.Dependencies, p.Dependencies...)
+	i.Nixpkgs = p.Nixpkgs
+	i.Targets = append(i.Targets, p.Targets...)
+	return i
+}
@@ -0,0 +1,61 @@
+package buildinfo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInputsDiff(t *testing.T) {
+	previous := &Inputs{
+		Files:        map[string]string{"a": "1", "b": "2", "c": "3"},
+		Env:          map[string]string{"HOME": "h1", "PATH": "p1"},
+		Cmds:         []string{"go build", "echo done"},
+		Dependencies: []string{"go_1_18"},
+		Nixpkgs:      "old",
+		Targets:      []string{"run"},
+	}
+	current := &Inputs{
+		Files:        map[string]string{"a": "1", "b": "changed", "d": "4"},
+		Env:          map[string]string{"HOME": "h1", "PATH": "p2", "GOOS": "linux"},
+		Cmds:         []string{"echo done", "go build -v"},
+		Dependencies: []string{"go_1_18"},
+		Nixpkgs:      "new",
+		Targets:      []string{"run"},
+	}
+
+	d := current.Diff(previous)
+	assert.False(t, d.Empty())
+
+	assert.Equal(t, []string{"d"}, d.Files.Added)
+	assert.Equal(t, []string{"c"}, d.Files.Removed)
+	assert.Equal(t, []string{"b"}, d.Files.Modified)
+
+	assert.Equal(t, []string{"GOOS"}, d.Env.Added)
+	assert.Empty(t, d.Env.Removed)
+	assert.Equal(t, []string{"PATH"}, d.Env.Modified)
+
+	assert.Equal(t, []string{"go build -v"}, d.Cmds.Added)
+	assert.Equal(t, []string{"go build"}, d.Cmds.Removed)
+
+	assert.True(t, d.Dependencies.Empty())
+	assert.True(t, d.Targets.Empty())
+	assert.Equal(t, &[2]string{"old", "new"}, d.Nixpkgs)
+
+	same := current.Diff(current)
+	assert.True(t, same.Empty())
+}
+
+func TestInputsProtoRoundtrip(t *testing.T) {
+	bi := New()
+	bi.Meta.Task = "build"
+	bi.Meta.CreatedAt = 42
+	bi.Inputs.Files["main.go"] = "abc"
+	bi.Inputs.Env["PATH"] = "def"
+	bi.Inputs.Cmds = []string{"go build"}
+	bi.Inputs.Nixpkgs = "nixpkgs"
+
+	got := FromProto(bi.ToProto("hash"))
+	assert.Equal(t, int64(42), got.Meta.CreatedAt)
+	assert.Equal(t, bi.Inputs, got.Inputs)
+}
@@ -2,6 +2,7 @@ package bobtask
 
 import (
 	"github.com/benchkram/bob/bobtask/buildinfo"
+	"github.com/benchkram/bob/pkg/buildinfostore"
 	"github.com/benchkram/errz"
 )
 
@@ -38,3 +39,32 @@ func (t *Task) WriteBuildinfo(buildinfo *buildinfo.I) (err error) {
 
 	return nil
 }
+
+// LatestBuildInfo returns the most recent build info of the task
+// independent of the current input hash. Build infos without
+// recorded inputs are ignored.
+func (t *Task) LatestBuildInfo() (latest *buildinfo.I, err error) {
+	defer errz.Recover(&err)
+
+	if t.buildInfoStore == nil {
+		return nil, ErrBuildinfostoreIsNil
+	}
+
+	infos, err := t.buildInfoStore.GetBuildInfos()
+	errz.Fatal(err)
+
+	for _, bi := range infos {
+		if bi.Meta.Task != t.name || bi.Inputs.Empty() {
+			continue
+		}
+		if latest == nil || bi.Meta.CreatedAt > latest.Meta.CreatedAt {
+			latest = bi
+		}
+	}
+
+	if latest == nil {
+		return nil, buildinfostore.ErrBuildInfoDoesNotExist
+	}
+
+	return latest, nil
+}
@@ -86,7 +86,10 @@ func (t *Task) Clean(verbose ...bool) error {
 			return fmt.Errorf("task dir not set")
 		}
 
-		files := t.target.FilesystemEntriesRaw()
+		files, err := t.target.Matches()
+		if err != nil {
+			return err
+		}
 		for _, filename := range files {
 			if vb {
 				fmt.Printf(" %s ", filename)
@@ -0,0 +1,86 @@
+package bobtask
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/benchkram/bob/pkg/envutil"
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+var ErrInvalidEnv = fmt.Errorf("invalid env")
+
+var envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// DeclaresEnv is true when the task lists the variables visible to it
+// in an `env` block. Entries are either a name, passing the variable
+// through, or `NAME=value` setting a fixed value.
+func (t *Task) DeclaresEnv() bool {
+	return t.EnvDirty != nil
+}
+
+// ResolveEnv returns the variables declared in the task's env block.
+// Pass-through variables are looked up in overrides (`--env`), the host
+// environment and defaults (bobfile variables) in that order. Undefined
+// variables are omitted.
+func (t *Task) ResolveEnv(overrides, host, defaults []string) []string {
+	sources := []map[string]string{
+		envMap(overrides),
+		envMap(host),
+		envMap(defaults),
+	}
+
+	env := []string{}
+	for _, entry := range t.EnvDirty {
+		name, value, fixed := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if fixed {
+			env = append(env, name+"="+value)
+			continue
+		}
+
+		for _, source := range sources {
+			if v, ok := source[name]; ok {
+				env = append(env, name+"="+v)
+				break
+			}
+		}
+	}
+	return env
+}
+
+// Environment returns the environment the task's commands run with,
+// the nix environment merged with the task's variables.
+func (t *Task) Environment() ([]string, error) {
+	nixEnv, ok := t.envStore[t.envID]
+	if !ok {
+		return nil, fmt.Errorf("missing nix environment in envStore")
+	}
+	return envutil.Merge(nixEnv, t.env), nil
+}
+
+func (t *Task) verifyEnv() error {
+	seen := map[string]bool{}
+	for _, entry := range t.EnvDirty {
+		name, _, _ := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !envNameRegex.MatchString(name) {
+			return usererror.Wrap(fmt.Errorf("%w, `%s` is not a valid variable name in task `%s`", ErrInvalidEnv, name, t.name))
+		}
+		if seen[name] {
+			return usererror.Wrap(fmt.Errorf("%w, variable `%s` is declared twice in task `%s`", ErrInvalidEnv, name, t.name))
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+func envMap(env []string) map[string]string {
+	m := make(map[string]string, len(env))
+	for _, v := range env {
+		key, value, _ := strings.Cut(v, "=")
+		m[key] = value
+	}
+	return m
+}
@@ -0,0 +1,38 @@
+package bobtask
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveEnv(t *testing.T) {
+	task := Make()
+	assert.False(t, task.DeclaresEnv())
+
+	task.EnvDirty = []string{"GOFLAGS", "NODE_ENV=production", "FROM_VARS", "UNSET"}
+	assert.True(t, task.DeclaresEnv())
+	assert.Nil(t, task.verifyEnv())
+
+	env := task.ResolveEnv(
+		[]string{"NODE_ENV=development"},
+		[]string{"GOFLAGS=-mod=mod", "HOME=/home/user"},
+		[]string{"FROM_VARS=vars", "GOFLAGS=-v"},
+	)
+	assert.Equal(t, []string{"GOFLAGS=-mod=mod", "NODE_ENV=production", "FROM_VARS=vars"}, env)
+
+	// overrides take precedence over the host
+	env = task.ResolveEnv([]string{"GOFLAGS=-x"}, []string{"GOFLAGS=-mod=mod"}, nil)
+	assert.Equal(t, []string{"GOFLAGS=-x", "NODE_ENV=production"}, env)
+}
+
+func TestVerifyEnv(t *testing.T) {
+	task := Make()
+
+	task.EnvDirty = []string{"GOFLAGS", "GOFLAGS=-v"}
+	assert.True(t, errors.Is(task.verifyEnv(), ErrInvalidEnv))
+
+	task.EnvDirty = []string{"NOT-VALID"}
+	assert.True(t, errors.Is(task.verifyEnv(), ErrInvalidEnv))
+}
@@ -10,8 +10,12 @@ var (
 	ErrInvalidInput           = fmt.Errorf("invalid input")
 	ErrBuildinfostoreIsNil    = fmt.Errorf("buildinfostore is nil")
 
-	ErrInvalidTargetDefinition  = fmt.Errorf("invalid target definition, can't find 'path' or 'image' directive")
-	ErrAmbigousTargetDefinition = fmt.Errorf("ambigous target definition, can't have 'path' and 'image' directive on same target")
+	ErrInvalidTargetDefinition  = fmt.Errorf("invalid target definition, can't find 'path', 'image' or 'oci' directive")
+	ErrAmbigousTargetDefinition = fmt.Errorf("ambigous target definition, can't have 'path', 'image' or 'oci' directive on same target")
+	ErrInvalidOCITarget         = fmt.Errorf("invalid oci target")
 
 	ErrAmbigousTargets = fmt.Errorf("ambigous targets detected")
+
+	ErrBlobStoreRequired = fmt.Errorf("artifact references blobs but the store does not support blobs")
+	ErrCorruptedBlob     = fmt.Errorf("corrupted blob")
 )
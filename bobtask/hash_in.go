@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/benchkram/bob/bobtask/buildinfo"
 	"github.com/benchkram/bob/bobtask/hash"
 	"github.com/benchkram/bob/pkg/boblog"
 	"github.com/benchkram/bob/pkg/filehash"
@@ -29,10 +30,11 @@ func (t *Task) HashIn() (taskHash hash.In, err error) {
 // computeInputHash computes a hash containing inputs, environment and the task description.
 func (t *Task) computeInputHash() (taskHash hash.In, err error) {
 	h := filehash.New()
+	manifest := t.inputManifestBase()
 
 	// Hash content of input files
 	for _, f := range t.inputs {
-		err = h.AddFile(f)
+		sum, err := h.AddFileWithSum(f)
 		if err != nil {
 			if errors.Is(err, os.ErrPermission) {
 				t.addToSkippedInputs(f)
@@ -41,6 +43,7 @@ func (t *Task) computeInputHash() (taskHash hash.In, err error) {
 				return taskHash, fmt.Errorf("failed to hash file %q: %w", f, err)
 			}
 		}
+		manifest.Files[f] = hex.EncodeToString(sum)
 	}
 
 	// Hash the task description
@@ -53,8 +56,56 @@ func (t *Task) computeInputHash() (taskHash hash.In, err error) {
 
 	// store hash for reuse
 	t.hashIn = &hashIn
+	t.inputManifest = manifest
 
 	boblog.Log.V(4).Info(fmt.Sprintf("Computed hash [h: %s] for task [t: %s], using [inputs:%d] input files ", t.hashIn.String(), t.Name(), len(t.inputs)))
 
 	return hashIn, nil
 }
+
+// InputManifest returns the inputs the input hash was computed from.
+func (t *Task) InputManifest() (_ *buildinfo.Inputs, err error) {
+	if t.inputManifest == nil {
+		_, err = t.computeInputHash()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return t.inputManifest, nil
+}
+
+// inputManifestBase collects all inputs from the task description.
+// Files are added while hashing.
+func (t *Task) inputManifestBase() *buildinfo.Inputs {
+	manifest := buildinfo.NewInputs()
+
+	manifest.Cmds = append(manifest.Cmds, t.cmds...)
+	manifest.Nixpkgs = t.nixpkgs
+
+	for _, v := range t.env {
+		if isIrreproducibleEnv(v) || t.isSecret(v) {
+			continue
+		}
+		key, value, _ := strings.Cut(v, "=")
+		sum, err := filehash.HashBytes(strings.NewReader(value))
+		if err != nil {
+			continue
+		}
+		manifest.Env[key] = hex.EncodeToString(sum)
+	}
+
+	for _, d := range t.dependencies {
+		if d.Nixpkgs == "" {
+			manifest.Dependencies = append(manifest.Dependencies, d.Name)
+		} else {
+			manifest.Dependencies = append(manifest.Dependencies, d.Name+"@"+d.Nixpkgs)
+		}
+	}
+
+	if t.target != nil {
+		manifest.Targets = append(manifest.Targets, t.target.DockerImages()...)
+		manifest.Targets = append(manifest.Targets, t.target.FilesystemEntriesRaw()...)
+	}
+
+	return manifest
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/benchkram/bob/bob/global"
+	"github.com/benchkram/bob/bobtask/target"
 	"github.com/benchkram/bob/pkg/filepathutil"
 	"github.com/benchkram/bob/pkg/usererror"
 	"github.com/benchkram/errz"
@@ -24,9 +25,10 @@ func (t *Task) SetInputs(inputs []string) {
 }
 
 var (
-	defaultIgnores = fmt.Sprintf("!%s\n!%s",
+	defaultIgnores = fmt.Sprintf("!%s\n!%s\n!%s",
 		global.BobWorkspaceFile,
 		filepath.Join(global.BobCacheDir, "*"),
+		filepath.Join(global.BobLogsDir, "*"),
 	)
 )
 
@@ -89,7 +91,13 @@ func (t *Task) FilteredInputs(projectRoot string) (_ []string, err error) {
 	}
 
 	// Ignore file & dir targets stored in the same directory
-	if t.target != nil {
+	if t.target != nil && t.target.PerFile() {
+		matches, err := t.target.Matches()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list target: %w", err)
+		}
+		ignores = append(ignores, matches...)
+	} else if t.target != nil {
 		for _, path := range rooted(t.target.FilesystemEntriesRawPlain(), t.dir) {
 			info, err := os.Lstat(path)
 			if err != nil {
@@ -115,6 +123,15 @@ func (t *Task) FilteredInputs(projectRoot string) (_ []string, err error) {
 	// Usually the targets of child tasks which are already
 	// relative to the umbrella Bobfile.
 	for _, path := range t.InputAdditionalIgnores {
+		if target.IsPattern(path) {
+			list, err := filepathutil.ListRecursive(path, projectRoot)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list input: %w", err)
+			}
+			ignores = append(ignores, list...)
+			continue
+		}
+
 		info, err := os.Lstat(path)
 		if err != nil {
 			if errors.Is(err, fs.ErrNotExist) {
@@ -118,6 +118,7 @@ func (tm Map) Sanitize() (err error) {
 
 		task.cmds = multilinecmd.Split(task.CmdDirty)
 		task.rebuild = task.sanitizeRebuild(task.RebuildDirty)
+		task.sanitizeResources()
 
 		tm[key] = task
 	}
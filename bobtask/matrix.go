@@ -0,0 +1,204 @@
+package bobtask
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+var ErrInvalidMatrix = fmt.Errorf("invalid matrix")
+
+var (
+	matrixKeyPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	// matrixRefPattern matches `${matrix.key}`
+	matrixRefPattern = regexp.MustCompile(`\$\{\s*matrix\.([^}\s]*)\s*\}`)
+)
+
+// Matrix expands a task into one task per combination of its values, e.g.
+//
+//	matrix:
+//	  os: [linux, darwin]
+//	  arch: [amd64, arm64]
+//
+// Values are referenced by `${matrix.os}`.
+// The order of the keys is kept to name the expanded tasks.
+type Matrix struct {
+	keys   []string
+	values map[string][]string
+}
+
+func (m *Matrix) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("%w near line %d, matrix must map keys to lists of values", ErrInvalidMatrix, value.Line)
+	}
+
+	m.keys = nil
+	m.values = map[string][]string{}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key := value.Content[i].Value
+
+		var values []string
+		err := value.Content[i+1].Decode(&values)
+		if err != nil {
+			return fmt.Errorf("%w near line %d, values of `%s` must be a list", ErrInvalidMatrix, value.Content[i+1].Line, key)
+		}
+
+		m.keys = append(m.keys, key)
+		m.values[key] = values
+	}
+
+	return nil
+}
+
+// combinations returns all combinations of the matrix values
+// with the values of the first key changing slowest.
+func (m *Matrix) combinations() []map[string]string {
+	combinations := []map[string]string{{}}
+	for _, key := range m.keys {
+		var next []map[string]string
+		for _, c := range combinations {
+			for _, value := range m.values[key] {
+				combination := map[string]string{key: value}
+				for k, v := range c {
+					combination[k] = v
+				}
+				next = append(next, combination)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// instanceName e.g. `build[linux-amd64]`.
+func (m *Matrix) instanceName(name string, combination map[string]string) string {
+	values := make([]string, 0, len(m.keys))
+	for _, key := range m.keys {
+		values = append(values, combination[key])
+	}
+	return fmt.Sprintf("%s[%s]", name, strings.Join(values, "-"))
+}
+
+func (m *Matrix) verify(name string) error {
+	if len(m.keys) == 0 {
+		return usererror.Wrap(fmt.Errorf("%w in task `%s`, matrix is empty", ErrInvalidMatrix, name))
+	}
+	if strings.ContainsRune(name, TaskPathSeparator) {
+		return usererror.Wrap(fmt.Errorf("%w in task `%s`, decorations can't use a matrix", ErrInvalidMatrix, name))
+	}
+	for _, key := range m.keys {
+		if !matrixKeyPattern.MatchString(key) {
+			return usererror.Wrap(fmt.Errorf("%w in task `%s`, invalid key `%s`", ErrInvalidMatrix, name, key))
+		}
+		if len(m.values[key]) == 0 {
+			return usererror.Wrap(fmt.Errorf("%w in task `%s`, no values for `%s`", ErrInvalidMatrix, name, key))
+		}
+		for _, value := range m.values[key] {
+			if value == "" || strings.ContainsAny(value, "/[]") {
+				return usererror.Wrap(fmt.Errorf("%w in task `%s`, value `%s` of `%s` must not be empty or contain `/`, `[` or `]`", ErrInvalidMatrix, name, value, key))
+			}
+		}
+	}
+	return nil
+}
+
+// ExpandMatrices replaces each task with a matrix by one task per
+// combination of the matrix values. Values are substituted into `cmd`,
+// `input`, `target` and `dependsOn`. The task itself is kept without
+// a matrix and depends on all of its instances, so it can still be
+// built and depended on.
+func (tm Map) ExpandMatrices() error {
+	names := make([]string, 0, len(tm))
+	for name := range tm {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		task := tm[name]
+		if task.Matrix == nil {
+			continue
+		}
+
+		m := task.Matrix
+		err := m.verify(name)
+		if err != nil {
+			return err
+		}
+
+		var instances []string
+		for _, combination := range m.combinations() {
+			instance, err := task.matrixInstance(combination)
+			if err != nil {
+				return usererror.Wrap(fmt.Errorf("%w in task `%s`: %s", ErrInvalidMatrix, name, err.Error()))
+			}
+
+			instanceName := m.instanceName(name, combination)
+			if _, exists := tm[instanceName]; exists {
+				return usererror.Wrap(fmt.Errorf("%w in task `%s`, `%s` already exists", ErrInvalidMatrix, name, instanceName))
+			}
+			tm[instanceName] = instance
+			instances = append(instances, instanceName)
+		}
+
+		tm[name] = Task{DependsOn: instances}
+	}
+
+	return nil
+}
+
+// matrixInstance returns a copy of the task
+// with the values of combination substituted.
+func (t *Task) matrixInstance(combination map[string]string) (_ Task, err error) {
+	instance := *t
+	instance.Matrix = nil
+
+	substitute := func(s string) string {
+		return matrixRefPattern.ReplaceAllStringFunc(s, func(ref string) string {
+			key := matrixRefPattern.FindStringSubmatch(ref)[1]
+			value, ok := combination[key]
+			if !ok && err == nil {
+				err = fmt.Errorf("unknown matrix reference `%s`", ref)
+			}
+			return value
+		})
+	}
+
+	instance.CmdDirty = substitute(t.CmdDirty)
+	instance.InputDirty = substitute(t.InputDirty)
+	instance.TargetDirty = substituteTarget(t.TargetDirty, substitute)
+
+	instance.DependsOn = make([]string, 0, len(t.DependsOn))
+	for _, dependency := range t.DependsOn {
+		instance.DependsOn = append(instance.DependsOn, substitute(dependency))
+	}
+
+	return instance, err
+}
+
+// substituteTarget substitutes all strings of a target definition.
+func substituteTarget(target TargetEntry, substitute func(string) string) TargetEntry {
+	switch t := target.(type) {
+	case string:
+		return substitute(t)
+	case []interface{}:
+		substituted := make([]interface{}, 0, len(t))
+		for _, v := range t {
+			substituted = append(substituted, substituteTarget(v, substitute))
+		}
+		return substituted
+	case map[string]interface{}:
+		substituted := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			substituted[k] = substituteTarget(v, substitute)
+		}
+		return substituted
+	default:
+		return target
+	}
+}
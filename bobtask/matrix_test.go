@@ -0,0 +1,92 @@
+package bobtask
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+var withMatrix = `
+build:
+  input: ./cmd/${matrix.service}
+  cmd: GOOS=${matrix.os} GOARCH=${matrix.arch} go build -o bin/${matrix.service}-${matrix.os}-${matrix.arch} ./cmd/${matrix.service}
+  target: bin/${matrix.service}-${matrix.os}-${matrix.arch}
+  dependsOn:
+    - generate[${matrix.service}]
+  matrix:
+    service: [api]
+    os: [linux, darwin]
+    arch: [amd64, arm64]
+generate:
+  cmd: go generate ./cmd/${matrix.service}
+  matrix:
+    service: [api]
+`
+
+func TestExpandMatrices(t *testing.T) {
+	var tm Map
+	err := yaml.Unmarshal([]byte(withMatrix), &tm)
+	assert.Nil(t, err)
+
+	err = tm.ExpandMatrices()
+	assert.Nil(t, err)
+
+	// instances plus the umbrella tasks
+	assert.Len(t, tm, 7)
+
+	assert.Equal(t, []string{
+		"build[api-linux-amd64]",
+		"build[api-linux-arm64]",
+		"build[api-darwin-amd64]",
+		"build[api-darwin-arm64]",
+	}, tm["build"].DependsOn)
+	assert.Equal(t, []string{"generate[api]"}, tm["generate"].DependsOn)
+	umbrella := tm["build"]
+	assert.True(t, umbrella.IsValidDecoration(), "umbrella task must only depend on its instances")
+
+	instance := tm["build[api-darwin-arm64]"]
+	assert.Nil(t, instance.Matrix)
+	assert.Equal(t, "./cmd/api", instance.InputDirty)
+	assert.Equal(t, "GOOS=darwin GOARCH=arm64 go build -o bin/api-darwin-arm64 ./cmd/api", instance.CmdDirty)
+	assert.Equal(t, "bin/api-darwin-arm64", instance.TargetDirty)
+	assert.Equal(t, []string{"generate[api]"}, instance.DependsOn)
+
+	assert.Equal(t, "go generate ./cmd/api", tm["generate[api]"].CmdDirty)
+}
+
+func TestExpandMatricesInvalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown reference": `
+build:
+  cmd: echo ${matrix.arch}
+  matrix:
+    os: [linux]
+`,
+		"no values": `
+build:
+  matrix:
+    os: []
+`,
+		"decoration": `
+second/build:
+  matrix:
+    os: [linux]
+`,
+		"path separator in value": `
+build:
+  matrix:
+    os: [linux/amd64]
+`,
+	}
+
+	for name, input := range tests {
+		var tm Map
+		err := yaml.Unmarshal([]byte(input), &tm)
+		assert.Nil(t, err, name)
+
+		err = tm.ExpandMatrices()
+		assert.True(t, errors.Is(err, ErrInvalidMatrix), name)
+	}
+}
@@ -0,0 +1,84 @@
+package bobtask
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+var ErrInvalidResources = fmt.Errorf("invalid resources")
+
+// Resources a task occupies while running.
+type Resources struct {
+	// CPU is the number of cores, defaults to 1.
+	CPU int `yaml:"cpu,omitempty"`
+	// Memory like 512M or 8G.
+	Memory string `yaml:"memory,omitempty"`
+}
+
+// CPU returns the number of cores the task occupies.
+func (t *Task) CPU() int {
+	if t.ResourcesDirty.CPU <= 0 {
+		return 1
+	}
+	return t.ResourcesDirty.CPU
+}
+
+// Memory returns the memory in bytes the task occupies.
+func (t *Task) Memory() uint64 {
+	return t.memory
+}
+
+func (t *Task) sanitizeResources() {
+	// invalid values are reported by verify
+	t.memory, _ = ParseMemory(t.ResourcesDirty.Memory)
+}
+
+func (t *Task) verifyResources() error {
+	if t.ResourcesDirty.CPU < 0 {
+		return usererror.Wrap(fmt.Errorf("%w, cpu must not be negative for task `%s`", ErrInvalidResources, t.name))
+	}
+	if _, err := ParseMemory(t.ResourcesDirty.Memory); err != nil {
+		return usererror.Wrap(fmt.Errorf("%w for task `%s`: %s", ErrInvalidResources, t.name, err))
+	}
+	return nil
+}
+
+var memoryUnits = []struct {
+	suffix string
+	factor uint64
+}{
+	// longest suffixes first
+	{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40},
+	{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"TB", 1 << 40},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"T", 1 << 40},
+	{"B", 1},
+}
+
+// ParseMemory parses sizes like 512M, 8G or 1Gi to bytes.
+// Units are binary, a plain number is interpreted as bytes.
+// An empty string returns 0.
+func ParseMemory(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	factor := uint64(1)
+	for _, unit := range memoryUnits {
+		if strings.HasSuffix(strings.ToUpper(s), strings.ToUpper(unit.suffix)) {
+			factor = unit.factor
+			s = strings.TrimSpace(s[:len(s)-len(unit.suffix)])
+			break
+		}
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid memory size [%s]", s)
+	}
+	return uint64(v * float64(factor)), nil
+}
@@ -19,15 +19,22 @@ import (
 )
 
 func (t *Task) Run(ctx context.Context, namePad int) (err error) {
+	return t.run(ctx, namePad, t.dir)
+}
+
+// run executes the task's commands in dir, opts are passed to the interpreter.
+func (t *Task) run(ctx context.Context, namePad int, dir string, opts ...func(*interp.Runner) error) (err error) {
 	defer errz.Recover(&err)
 
-	nixEnv, ok := t.envStore[t.envID]
-	if !ok {
-		return fmt.Errorf("missing nix environment in envStore")
-	}
+	env, err := t.Environment()
+	errz.Fatal(err)
 
-	env := envutil.Merge(nixEnv, t.env)
+	// secrets are only injected at execution time
+	secrets, err := t.resolveSecrets()
+	errz.Fatal(err)
+	env = envutil.Merge(env, secrets)
 
+	t.output = []string{}
 	for _, run := range t.cmds {
 		p, err := syntax.NewParser().Parse(strings.NewReader(run), "")
 		if err != nil {
@@ -51,18 +58,20 @@ func (t *Task) Run(ctx context.Context, namePad int) (err error) {
 					return
 				}
 
-				boblog.Log.V(1).Info(fmt.Sprintf("%-*s\t  %s", namePad, t.ColoredName(), aurora.Faint(s.Text())))
+				line := boblog.Redact(s.Text())
+				t.addOutput(line)
+				boblog.Log.V(1).Info(fmt.Sprintf("%-*s\t  %s", namePad, t.ColoredName(), aurora.Faint(line)))
 			}
 
 			done <- true
 		}()
 
-		r, err := interp.New(
+		r, err := interp.New(append([]func(*interp.Runner) error{
 			interp.Params("-e"),
-			interp.Dir(t.dir),
+			interp.Dir(dir),
 			interp.Env(expand.ListEnviron(env...)),
 			interp.StdIO(os.Stdin, pw, pw),
-		)
+		}, opts...)...)
 
 		errz.Fatal(err)
 
@@ -81,3 +90,19 @@ func (t *Task) Run(ctx context.Context, namePad int) (err error) {
 
 	return nil
 }
+
+// maxOutputLines limits the output kept in memory for a task.
+const maxOutputLines = 1000
+
+func (t *Task) addOutput(line string) {
+	if len(t.output) >= maxOutputLines {
+		t.output = t.output[1:]
+	}
+	t.output = append(t.output, line)
+}
+
+// Output returns the last lines printed by the task's commands
+// during the last run. Must not be called while the task runs.
+func (t *Task) Output() []string {
+	return t.output
+}
@@ -0,0 +1,464 @@
+package bobtask
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benchkram/bob/bobtask/target"
+	"github.com/benchkram/bob/pkg/boblog"
+	"github.com/benchkram/bob/pkg/filepathxx"
+	"github.com/benchkram/bob/pkg/proctrace"
+	"github.com/benchkram/bob/pkg/usererror"
+	"github.com/benchkram/errz"
+	"mvdan.cc/sh/interp"
+)
+
+var ErrSandboxViolation = fmt.Errorf("sandbox violation")
+
+// RunSandboxed runs the task inside a temporary tree only containing the
+// declared inputs and the targets of dependencies. Paths are relative to
+// the workspace root. Declared targets are copied back to the workspace
+// after a successful run.
+//
+// Files read or written by the commands outside of the declared set fail
+// the task, including reads of files missing in the sandbox and accesses
+// to files outside of the workspace, see systemPaths. Accesses of child
+// processes are traced where proctrace is supported, other platforms fall
+// back to checking the arguments of executed programs. Redirects of the
+// shell are always checked, writes also by comparing the sandbox before
+// and after the run.
+func (t *Task) RunSandboxed(ctx context.Context, namePad int, dependencyTargets []string) (err error) {
+	defer errz.Recover(&err)
+
+	workspace, err := filepath.Abs(".")
+	errz.Fatal(err)
+	// traced paths are resolved
+	workspace, err = filepath.EvalSymlinks(workspace)
+	errz.Fatal(err)
+
+	root, err := os.MkdirTemp("", "bob-sandbox-")
+	errz.Fatal(err)
+	defer os.RemoveAll(root)
+	// resolve symlinks (e.g. /tmp on macOS), paths seen by the shell are resolved
+	root, err = filepath.EvalSymlinks(root)
+	errz.Fatal(err)
+
+	s := newSandbox(workspace, root)
+	for _, path := range append(append([]string{}, t.inputs...), dependencyTargets...) {
+		err = s.add(path)
+		errz.Fatal(err)
+	}
+
+	dir := filepath.Join(root, t.dir)
+	err = os.MkdirAll(dir, 0775)
+	errz.Fatal(err)
+
+	err = s.snapshot()
+	errz.Fatal(err)
+
+	boblog.Log.V(3).Info(fmt.Sprintf("Running task %s in sandbox %s", t.name, root))
+	pt := proctrace.New()
+	exec := tracedExec(pt)
+	if !proctrace.Supported() {
+		exec = s.exec(exec)
+	}
+	runErr := t.run(ctx, namePad, dir,
+		interp.Module(exec),
+		interp.Module(s.open(interp.OpenDevImpls(interp.DefaultOpen))),
+	)
+
+	read, written := pt.Files()
+	for _, path := range read {
+		s.check("", path, "read")
+	}
+	for _, path := range written {
+		s.check("", path, "write")
+	}
+
+	targets := t.TargetPaths()
+	violations, err := s.violations(t.isTargetPath)
+	errz.Fatal(err)
+	if len(violations) > 0 {
+		return usererror.Wrap(fmt.Errorf("%w, task `%s` accessed undeclared paths:\n  %s\ndeclare them as input or target",
+			ErrSandboxViolation, t.name, strings.Join(violations, "\n  ")))
+	}
+	if runErr != nil {
+		return runErr
+	}
+
+	// copy declared targets back to the workspace
+	if t.target.PerFile() {
+		return s.copyBack(t.isTargetPath)
+	}
+	for _, target := range targets {
+		src := filepath.Join(root, target)
+		if _, err := os.Lstat(src); err != nil {
+			// missing targets are reported by target verification
+			continue
+		}
+		dst := filepath.Join(workspace, target)
+		err = os.RemoveAll(dst)
+		errz.Fatal(err)
+		err = copyPath(src, dst)
+		errz.Fatal(err)
+	}
+
+	return nil
+}
+
+// TargetPaths returns the filesystem targets
+// relative to the workspace root. Entries can be glob patterns.
+func (t *Task) TargetPaths() []string {
+	if t.target == nil {
+		return nil
+	}
+	return t.target.FilesystemEntriesRaw()
+}
+
+// isTargetPath checks if a path relative to the
+// workspace root belongs to the tasks target.
+func (t *Task) isTargetPath(path string) bool {
+	if t.target == nil {
+		return false
+	}
+	return t.target.Match(path)
+}
+
+type sandbox struct {
+	workspace string
+	root      string
+
+	// files in the sandbox before the run
+	before map[string]fileState
+
+	mu sync.Mutex
+	// accessed holds undeclared paths accessed
+	// by the shell, mapped to the kind of access.
+	accessed map[string]string
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+func newSandbox(workspace, root string) *sandbox {
+	return &sandbox{
+		workspace: workspace,
+		root:      root,
+		before:    map[string]fileState{},
+		accessed:  map[string]string{},
+	}
+}
+
+// add copies a path relative to the workspace into the sandbox.
+func (s *sandbox) add(path string) error {
+	if target.IsPattern(path) {
+		matches, err := filepathxx.Glob(filepath.Join(s.workspace, path))
+		if err != nil {
+			return err
+		}
+		for _, m := range matches {
+			rel, err := filepath.Rel(s.workspace, m)
+			if err != nil {
+				return err
+			}
+			if err := s.add(rel); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path = filepath.Clean(path)
+	src := filepath.Join(s.workspace, path)
+	if _, err := os.Lstat(src); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	dst := filepath.Join(s.root, path)
+	if info, err := os.Lstat(dst); err == nil && !info.IsDir() {
+		// already added as part of a directory
+		return nil
+	}
+	return copyPath(src, dst)
+}
+
+func (s *sandbox) snapshot() error {
+	return filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		s.before[path] = fileState{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+}
+
+// check records an access to path which is either
+// absolute or relative to dir.
+func (s *sandbox) check(dir, path, access string) {
+	if path == "" {
+		return
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	path = filepath.Clean(path)
+
+	var rel string
+	escaped := false
+	switch {
+	case isWithin(s.root, path):
+		rel, _ = filepath.Rel(s.root, path)
+	case isWithin(s.workspace, path):
+		rel, _ = filepath.Rel(s.workspace, path)
+		escaped = true
+	default:
+		s.checkOutside(path, access)
+		return
+	}
+
+	if !escaped {
+		if _, err := os.Lstat(path); err == nil {
+			// exists in the sandbox
+			return
+		}
+	}
+	if _, err := os.Lstat(filepath.Join(s.workspace, rel)); err != nil {
+		// neither exists in the workspace, likely not a path
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if escaped {
+		access += " outside sandbox"
+	}
+	s.accessed[rel] = access
+}
+
+// nixStore holds the dependencies of a task, which are
+// part of its environment and never have to be declared.
+const nixStore = "/nix/store"
+
+// systemPaths outside of the workspace can be accessed by tasks,
+// e.g. shared libraries and configuration of the system.
+var systemPaths = []string{
+	nixStore,
+	"/bin", "/dev", "/etc", "/lib", "/lib32", "/lib64", "/opt", "/proc", "/run", "/sbin", "/sys", "/usr", "/var",
+	// macOS
+	"/Library", "/System", "/private",
+}
+
+// checkOutside records an access to an existing file outside of the workspace
+// unless it's located in the nix store, a system path or the temp dir.
+func (s *sandbox) checkOutside(path, access string) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		// not a path or doesn't exist
+		return
+	}
+	if resolved != path && (isWithin(s.root, resolved) || isWithin(s.workspace, resolved)) {
+		s.check("", resolved, access)
+		return
+	}
+	if info, err := os.Stat(resolved); err != nil || info.IsDir() {
+		return
+	}
+
+	allowed := append([]string{filepath.Clean(os.TempDir())}, systemPaths...)
+	if tmp, err := filepath.EvalSymlinks(os.TempDir()); err == nil {
+		allowed = append(allowed, tmp)
+	}
+	for _, dir := range allowed {
+		if isWithin(dir, path) || isWithin(dir, resolved) {
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accessed[path] = access + " outside workspace"
+}
+
+func (s *sandbox) exec(next interp.ModuleExec) interp.ModuleExec {
+	return func(ctx context.Context, path string, args []string) error {
+		mc, _ := interp.FromModuleContext(ctx)
+		for _, arg := range pathArgs(args) {
+			s.check(mc.Dir, arg, "read")
+		}
+		return next(ctx, path, args)
+	}
+}
+
+// pathArgs returns the arguments of a command line which might be paths,
+// including values of `--flag=value`. The program name is skipped.
+func pathArgs(args []string) (paths []string) {
+	for _, arg := range args[1:] {
+		if strings.HasPrefix(arg, "-") {
+			i := strings.Index(arg, "=")
+			if i < 0 {
+				continue
+			}
+			arg = arg[i+1:]
+		}
+		paths = append(paths, arg)
+	}
+	return paths
+}
+
+func (s *sandbox) open(next interp.ModuleOpen) interp.ModuleOpen {
+	return func(ctx context.Context, path string, flag int, perm os.FileMode) (io.ReadWriteCloser, error) {
+		mc, _ := interp.FromModuleContext(ctx)
+		access := "read"
+		if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_APPEND|os.O_TRUNC) != 0 {
+			access = "write"
+		}
+		s.check(mc.Dir, path, access)
+		return next(ctx, path, flag, perm)
+	}
+}
+
+// violations returns the undeclared paths accessed
+// during the run, including files written outside of targets.
+func (s *sandbox) violations(isTarget func(string) bool) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if before, ok := s.before[path]; ok && before.size == info.Size() && before.modTime.Equal(info.ModTime()) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+		if !isTarget(rel) {
+			s.accessed[rel] = "write"
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	violations := make([]string, 0, len(s.accessed))
+	for path, access := range s.accessed {
+		if isTarget(path) && !strings.HasSuffix(access, "outside sandbox") {
+			continue
+		}
+		violations = append(violations, fmt.Sprintf("%s (%s)", path, access))
+	}
+	sort.Strings(violations)
+	return violations, nil
+}
+
+// isWithin returns true if path is a child of dir.
+func isWithin(dir, path string) bool {
+	if dir == "." {
+		return !filepath.IsAbs(path) && !strings.HasPrefix(path, "..")
+	}
+	return strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// copyBack copies the files matching isTarget back to the workspace,
+// used for per file targets.
+func (s *sandbox) copyBack(isTarget func(string) bool) error {
+	return filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+		if !isTarget(rel) {
+			return nil
+		}
+		dst := filepath.Join(s.workspace, rel)
+		err = os.RemoveAll(dst)
+		if err != nil {
+			return err
+		}
+		return copyPath(path, dst)
+	})
+}
+
+// copyPath copies a file, symlink or directory tree
+// including parent directories.
+func copyPath(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(dst), 0775)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		link, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		_ = os.Remove(dst)
+		return os.Symlink(link, dst)
+	case info.IsDir():
+		err = os.MkdirAll(dst, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			err = copyPath(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name()))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		in, err := os.Open(src)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, in)
+		if err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	}
+}
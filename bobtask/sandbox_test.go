@@ -0,0 +1,84 @@
+package bobtask
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/benchkram/bob/pkg/envutil"
+	"github.com/benchkram/bob/pkg/proctrace"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunSandboxed(t *testing.T) {
+	base, err := os.MkdirTemp("", "test-run-sandboxed")
+	assert.Nil(t, err)
+	defer os.RemoveAll(base)
+
+	// files in the temp dir can be accessed by the task, move
+	// it to check accesses of files outside of the workspace.
+	t.Setenv("TMPDIR", filepath.Join(base, "tmp"))
+	assert.Nil(t, os.Mkdir(filepath.Join(base, "tmp"), 0775))
+	outside := filepath.Join(base, "outside")
+	assert.Nil(t, os.WriteFile(outside, []byte("outside"), 0664))
+
+	testdir := filepath.Join(base, "workspace")
+	assert.Nil(t, os.Mkdir(testdir, 0775))
+
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(testdir))
+	defer func() { _ = os.Chdir(wd) }()
+
+	assert.Nil(t, os.WriteFile("input", []byte("input"), 0664))
+	assert.Nil(t, os.WriteFile("undeclared", []byte("undeclared"), 0664))
+
+	newTask := func(cmd string) Task {
+		tsk := Make()
+		tsk.dir = "."
+		tsk.name = "sandboxed"
+		tsk.inputs = []string{"input"}
+		tsk.cmds = []string{cmd}
+		tsk.envStore = envutil.Store{"": os.Environ()}
+		tsk.TargetDirty = "target"
+		assert.Nil(t, tsk.parseTargets())
+		return tsk
+	}
+
+	// declared inputs are available, targets are copied back
+	tsk := newTask("cp input target")
+	err = tsk.RunSandboxed(context.Background(), 0, nil)
+	assert.Nil(t, err)
+	b, err := os.ReadFile("target")
+	assert.Nil(t, err)
+	assert.Equal(t, "input", string(b))
+
+	// undeclared reads and writes are reported
+	tsk = newTask("echo stray > stray; cat undeclared > target")
+	err = tsk.RunSandboxed(context.Background(), 0, nil)
+	assert.True(t, errors.Is(err, ErrSandboxViolation), err)
+	assert.Contains(t, err.Error(), "undeclared (read)")
+	assert.Contains(t, err.Error(), "stray (write)")
+
+	_, err = os.Stat(filepath.Join(testdir, "stray"))
+	assert.True(t, os.IsNotExist(err))
+
+	if !proctrace.Supported() {
+		return
+	}
+
+	// files read by child processes are reported, even if they are missing
+	tsk = newTask("sh -c 'cat undeclared > target'")
+	err = tsk.RunSandboxed(context.Background(), 0, nil)
+	assert.True(t, errors.Is(err, ErrSandboxViolation), err)
+	assert.Contains(t, err.Error(), "undeclared (read)")
+
+	// as well as files outside of the workspace, unless they are system files
+	tsk = newTask("sh -c 'cat /etc/passwd " + outside + " > target'")
+	err = tsk.RunSandboxed(context.Background(), 0, nil)
+	assert.True(t, errors.Is(err, ErrSandboxViolation), err)
+	assert.Contains(t, err.Error(), outside+" (read outside workspace)")
+	assert.NotContains(t, err.Error(), "/etc/passwd")
+}
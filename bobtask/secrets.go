@@ -0,0 +1,64 @@
+package bobtask
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/benchkram/bob/pkg/boblog"
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+var (
+	ErrSecretNotFound = fmt.Errorf("secret not found")
+	ErrInvalidSecret  = fmt.Errorf("invalid secret")
+)
+
+// resolveSecrets reads the task's secrets in the `key=value` format.
+// A secret is read from the environment variable of the same name,
+// otherwise from the file of that name in the secrets directory.
+// Values are registered to be masked in log output.
+func (t *Task) resolveSecrets() ([]string, error) {
+	secrets := make([]string, 0, len(t.Secrets))
+	for _, name := range t.Secrets {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			b, err := os.ReadFile(filepath.Join(t.secretsDir, name))
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					return nil, usererror.Wrap(fmt.Errorf("%w, `%s` of task `%s` is neither set as environment variable nor stored in %s",
+						ErrSecretNotFound, name, t.name, t.secretsDir))
+				}
+				return nil, err
+			}
+			value = strings.TrimRight(string(b), "\r\n")
+		}
+
+		boblog.AddSecrets(value)
+		secrets = append(secrets, name+"="+value)
+	}
+	return secrets, nil
+}
+
+// isSecret is true for `key=value` pairs of variables declared as secret,
+// those are never part of the input hash.
+func (t *Task) isSecret(v string) bool {
+	key, _, _ := strings.Cut(v, "=")
+	for _, name := range t.Secrets {
+		if name == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Task) verifySecrets() error {
+	for _, name := range t.Secrets {
+		if !envNameRegex.MatchString(name) {
+			return usererror.Wrap(fmt.Errorf("%w, `%s` is not a valid variable name in task `%s`", ErrInvalidSecret, name, t.name))
+		}
+	}
+	return nil
+}
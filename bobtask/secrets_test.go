@@ -0,0 +1,39 @@
+package bobtask
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/benchkram/bob/pkg/boblog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	dir, err := os.MkdirTemp("", "test-secrets")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "FILE_SECRET"), []byte("from-file\n"), 0600))
+	t.Setenv("ENV_SECRET", "from-env")
+
+	task := Make()
+	task.WithSecretsDir(dir)
+	task.Secrets = []string{"ENV_SECRET", "FILE_SECRET"}
+	task.env = []string{"ENV_SECRET=from-flag", "OTHER=value"}
+
+	secrets, err := task.resolveSecrets()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ENV_SECRET=from-env", "FILE_SECRET=from-file"}, secrets)
+
+	// values are masked and never part of the hash
+	assert.Equal(t, "token *** ***", boblog.Redact("token from-env from-file"))
+	assert.False(t, strings.Contains(task.description(), "from-flag"))
+	assert.True(t, strings.Contains(task.description(), "OTHER=value"))
+
+	task.Secrets = []string{"MISSING_SECRET"}
+	_, err = task.resolveSecrets()
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+}
@@ -47,6 +47,10 @@ func (t *T) buildinfoFiles(paths []string) (bi buildinfo.BuildInfoFiles, _ error
 	// is computed in a consistent order.
 	sort.Strings(paths)
 
+	if t.PerFile() {
+		return t.buildinfoMatchedFiles(paths)
+	}
+
 	for _, path := range paths {
 		path = filepath.Join(t.dir, path)
 
@@ -114,3 +118,45 @@ func (t *T) buildinfoFiles(paths []string) (bi buildinfo.BuildInfoFiles, _ error
 
 	return bi, nil
 }
+
+// buildinfoMatchedFiles only adds the files matched by a per file target.
+// Patterns are allowed to match no files.
+func (t *T) buildinfoMatchedFiles(paths []string) (bi buildinfo.BuildInfoFiles, _ error) {
+	bi = *buildinfo.NewBuildInfoFiles()
+
+	for _, path := range paths {
+		if IsPattern(path) {
+			continue
+		}
+		path = filepath.Join(t.dir, path)
+		if !file.Exists(path) {
+			return buildinfo.BuildInfoFiles{}, usererror.Wrapm(ErrTargetDoesNotExist, fmt.Sprintf("[path: %q]", path))
+		}
+	}
+
+	files, err := t.matchedFiles()
+	if err != nil {
+		return buildinfo.BuildInfoFiles{}, err
+	}
+
+	h := filehash.New()
+	for _, path := range files {
+		info, err := os.Lstat(path)
+		if err != nil {
+			return buildinfo.BuildInfoFiles{}, fmt.Errorf("failed to get file info %q: %w", path, err)
+		}
+		err = h.AddFile(path)
+		if err != nil {
+			return buildinfo.BuildInfoFiles{}, fmt.Errorf("failed to hash target %q: %w", path, err)
+		}
+		contentHash, err := filehash.HashOfFile(path)
+		if err != nil {
+			return buildinfo.BuildInfoFiles{}, fmt.Errorf("failed to get file hash %q: %w", path, err)
+		}
+		bi.Files[path] = buildinfo.BuildInfoFile{Size: info.Size(), Hash: contentHash}
+	}
+
+	bi.Hash = hex.EncodeToString(h.Sum())
+
+	return bi, nil
+}
@@ -0,0 +1,220 @@
+package target
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/benchkram/bob/pkg/filepathxx"
+)
+
+// IsPattern is true for target entries containing glob syntax,
+// e.g. `dist/**/*.js`.
+func IsPattern(entry string) bool {
+	return strings.ContainsAny(entry, "*?[")
+}
+
+// PerFile is true when the target uses glob patterns or excludes.
+// Only matched files belong to the target then, directories
+// are neither tracked nor deleted as a whole.
+func (t *T) PerFile() bool {
+	if len(t.filesystemExcludesRaw) > 0 {
+		return true
+	}
+	for _, entry := range t.filesystemEntriesRaw {
+		if IsPattern(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilesystemExcludesRaw returns the excluded entries (`!dist/cache/`)
+// without the leading `!` relative to the umbrella bobfile.
+func (t *T) FilesystemExcludesRaw() []string {
+	var pathsWithDir []string
+	for _, v := range t.filesystemExcludesRaw {
+		pathsWithDir = append(pathsWithDir, filepath.Join(t.dir, v))
+	}
+	return pathsWithDir
+}
+
+// Match checks if a path relative to the umbrella bobfile belongs
+// to the target. The path doesn't need to exist.
+func (t *T) Match(path string) bool {
+	path = filepath.Clean(path)
+
+	var included bool
+	for _, entry := range t.FilesystemEntriesRaw() {
+		if MatchPath(entry, path) {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return false
+	}
+
+	for _, exclude := range t.FilesystemExcludesRaw() {
+		if MatchPath(exclude, path) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches returns the paths to be deleted when cleaning the target.
+// Those are the entries as defined by the user or, for a per file
+// target, the matched files.
+func (t *T) Matches() ([]string, error) {
+	if !t.PerFile() {
+		return t.FilesystemEntriesRaw(), nil
+	}
+	return t.matchedFiles()
+}
+
+// matchedFiles expands the entries of a per file target
+// to the files existing on the filesystem.
+func (t *T) matchedFiles() ([]string, error) {
+	seen := map[string]bool{}
+	files := []string{}
+
+	add := func(path string) error {
+		info, err := os.Lstat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		if !info.IsDir() {
+			if !seen[path] && !ShouldIgnore(path) && t.Match(path) {
+				seen[path] = true
+				files = append(files, path)
+			}
+			return nil
+		}
+
+		return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || seen[p] || ShouldIgnore(p) || !t.Match(p) {
+				return nil
+			}
+			seen[p] = true
+			files = append(files, p)
+			return nil
+		})
+	}
+
+	for _, entry := range t.FilesystemEntriesRaw() {
+		if !IsPattern(entry) {
+			if err := add(entry); err != nil {
+				return nil, fmt.Errorf("failed to resolve target %q: %w", entry, err)
+			}
+			continue
+		}
+
+		matches, err := filepathxx.Glob(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to glob %q: %w", entry, err)
+		}
+		for _, m := range matches {
+			if err := add(m); err != nil {
+				return nil, fmt.Errorf("failed to resolve target %q: %w", entry, err)
+			}
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// MatchPath checks if path equals entry, is located inside of entry or
+// matches the glob pattern of entry. `**` matches any number of directories.
+func MatchPath(entry, path string) bool {
+	entry = filepath.Clean(entry)
+	path = filepath.Clean(path)
+
+	if path == entry || strings.HasPrefix(path, entry+string(filepath.Separator)) {
+		return true
+	}
+	if !IsPattern(entry) {
+		return false
+	}
+
+	re, err := globRegexp(entry)
+	if err != nil {
+		return false
+	}
+	// the path itself or one of its parent directories
+	for p := path; p != "." && p != string(filepath.Separator); p = filepath.Dir(p) {
+		if re.MatchString(p) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	globRegexpsMu sync.Mutex
+	globRegexps   = map[string]*regexp.Regexp{}
+)
+
+// globRegexp translates a glob pattern to a regular expression.
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	globRegexpsMu.Lock()
+	defer globRegexpsMu.Unlock()
+
+	if re, ok := globRegexps[pattern]; ok {
+		return re, nil
+	}
+
+	sep := regexp.QuoteMeta(string(filepath.Separator))
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**"+string(filepath.Separator)):
+			sb.WriteString("(.*" + sep + ")?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^" + sep + "]*")
+		case c == '?':
+			sb.WriteString("[^" + sep + "]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid pattern %q", pattern)
+			}
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, err
+	}
+	globRegexps[pattern] = re
+	return re, nil
+}
@@ -0,0 +1,89 @@
+package target
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		entry string
+		path  string
+		match bool
+	}{
+		{"dist", "dist", true},
+		{"dist", "dist/app.js", true},
+		{"dist", "distribution/app.js", false},
+		{"dist/*.js", "dist/app.js", true},
+		{"dist/*.js", "dist/lib/app.js", false},
+		{"dist/**/*.js", "dist/app.js", true},
+		{"dist/**/*.js", "dist/lib/deep/app.js", true},
+		{"dist/**/*.js", "dist/app.css", false},
+		{"dist/**", "dist/lib/app.js", true},
+		{"dist/app.?s", "dist/app.js", true},
+		{"dist/[ab].js", "dist/a.js", true},
+		{"dist/[!ab].js", "dist/a.js", false},
+		// children of a matched directory
+		{"*/cache", "dist/cache/file", true},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.match, MatchPath(test.entry, test.path), "%s %s", test.entry, test.path)
+	}
+}
+
+func TestPerFileTarget(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{
+		"dist/app.js",
+		"dist/app.js.map",
+		"dist/lib/util.js",
+		"dist/cache/chunk.js",
+		"dist/index.html",
+	} {
+		path := filepath.Join(dir, f)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(t, os.WriteFile(path, []byte(f), 0644))
+	}
+
+	target := New(
+		WithDir(dir),
+		WithFilesystemEntries([]string{"dist/**/*.js", "dist/index.html", "!dist/cache"}),
+	)
+	assert.True(t, target.PerFile())
+	assert.Equal(t, []string{"dist/**/*.js", "dist/index.html", "!dist/cache"}, target.FilesystemEntriesRawPlain())
+
+	assert.True(t, target.Match(filepath.Join(dir, "dist/lib/util.js")))
+	assert.False(t, target.Match(filepath.Join(dir, "dist/app.js.map")))
+	assert.False(t, target.Match(filepath.Join(dir, "dist/cache/chunk.js")))
+
+	expected := []string{
+		filepath.Join(dir, "dist/app.js"),
+		filepath.Join(dir, "dist/index.html"),
+		filepath.Join(dir, "dist/lib/util.js"),
+	}
+
+	matches, err := target.Matches()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, matches)
+
+	assert.Nil(t, target.Resolve())
+	assert.Equal(t, expected, target.FilesystemEntries())
+
+	bi, err := target.BuildInfo()
+	assert.Nil(t, err)
+	assert.Len(t, bi.Filesystem.Files, 3)
+	for _, path := range expected {
+		assert.Contains(t, bi.Filesystem.Files, path)
+	}
+
+	// a plain directory target keeps tracking the directory itself
+	plain := New(WithDir(dir), WithFilesystemEntries([]string{"dist"}))
+	assert.False(t, plain.PerFile())
+	matches, err = plain.Matches()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "dist")}, matches)
+}
@@ -1,5 +1,11 @@
 package target
 
+import (
+	"strings"
+
+	"github.com/benchkram/bob/pkg/ocilayout"
+)
+
 type Option func(t *T)
 
 func WithDir(dir string) Option {
@@ -8,9 +14,25 @@ func WithDir(dir string) Option {
 	}
 }
 
+// WithFilesystemEntries sets files, directories or glob patterns.
+// Entries starting with `!` are excluded from the target.
 func WithFilesystemEntries(entries []string) Option {
 	return func(t *T) {
-		t.filesystemEntriesRaw = entries
+		t.filesystemEntriesRaw = nil
+		t.filesystemExcludesRaw = nil
+		for _, entry := range entries {
+			if strings.HasPrefix(entry, "!") {
+				t.filesystemExcludesRaw = append(t.filesystemExcludesRaw, strings.TrimPrefix(entry, "!"))
+				continue
+			}
+			t.filesystemEntriesRaw = append(t.filesystemEntriesRaw, entry)
+		}
+	}
+}
+
+func WithOCIImage(image *ocilayout.Config) Option {
+	return func(t *T) {
+		t.ociImage = image
 	}
 }
 
@@ -10,6 +10,8 @@ import (
 )
 
 // Resolve filesystem entries based on filesystemEntriesRaw.
+// Directories are resolved including all of their children,
+// per file targets only resolve to the matched files.
 // Becomes a noop after the first call.
 func (t *T) Resolve() error {
 
@@ -17,6 +19,15 @@ func (t *T) Resolve() error {
 		return nil
 	}
 
+	if t.PerFile() {
+		resolved, err := t.matchedFiles()
+		if err != nil {
+			return err
+		}
+		t.filesystemEntries = &resolved
+		return nil
+	}
+
 	resolved := []string{}
 	for _, path := range t.FilesystemEntriesRaw() {
 
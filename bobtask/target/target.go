@@ -5,6 +5,7 @@ import (
 
 	"github.com/benchkram/bob/bobtask/buildinfo"
 	"github.com/benchkram/bob/pkg/dockermobyutil"
+	"github.com/benchkram/bob/pkg/ocilayout"
 )
 
 type Target interface {
@@ -20,6 +21,7 @@ type Target interface {
 
 	WithExpected(*buildinfo.Targets)
 	DockerImages() []string
+	OCIImage() *ocilayout.Config
 
 	// AsInvalidFiles returns all FilesystemEntriesRaw as invalid with the specified reason
 	AsInvalidFiles(reason Reason) map[string][]Reason
@@ -49,12 +51,19 @@ type T struct {
 	// Usually the first required when IgnoreChildtargets() is called
 	// on aggregate level.
 	filesystemEntries *[]string
-	// filesystemEntriesRaw is an array of files or directories,
-	// as defined by the user.
+	// filesystemEntriesRaw is an array of files, directories or
+	// glob patterns, as defined by the user.
 	//
 	// Used to verify that targets are created
 	// without verifying against expected buildinfo.
 	filesystemEntriesRaw []string
+	// filesystemExcludesRaw are entries defined with a leading `!`
+	// which are excluded from the target.
+	filesystemExcludesRaw []string
+
+	// ociImage is created from the filesystem entries
+	// when the artifact is created. Can be nil.
+	ociImage *ocilayout.Config
 }
 
 func New(opts ...Option) *T {
@@ -89,8 +98,14 @@ func (t *T) FilesystemEntriesRaw() []string {
 	return pathsWithDir
 }
 
+// FilesystemEntriesRawPlain returns the entries as defined by the
+// user including excludes with a leading `!`.
 func (t *T) FilesystemEntriesRawPlain() []string {
-	return append([]string{}, t.filesystemEntriesRaw...)
+	entries := append([]string{}, t.filesystemEntriesRaw...)
+	for _, v := range t.filesystemExcludesRaw {
+		entries = append(entries, "!"+v)
+	}
+	return entries
 }
 
 func (t *T) WithExpected(expected *buildinfo.Targets) {
@@ -105,6 +120,20 @@ func (t *T) DockerImages() []string {
 	return append([]string{}, t.dockerImages...)
 }
 
+// OCIImage returns the image to create from the filesystem entries,
+// nil if no image is defined. The path of the base image layout
+// is relative to the umbrella bobfile.
+func (t *T) OCIImage() *ocilayout.Config {
+	if t.ociImage == nil {
+		return nil
+	}
+	image := *t.ociImage
+	if image.Base != "" && !filepath.IsAbs(image.Base) {
+		image.Base = filepath.Join(t.dir, image.Base)
+	}
+	return &image
+}
+
 // AsInvalidFiles returns all FilesystemEntriesRaw as invalid with the specified reason
 func (t *T) AsInvalidFiles(reason Reason) map[string][]Reason {
 	invalidFiles := make(map[string][]Reason)
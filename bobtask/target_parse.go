@@ -4,9 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/benchkram/bob/bobtask/target"
 	"github.com/benchkram/bob/bobtask/targettype"
+	"github.com/benchkram/bob/pkg/ocilayout"
 	"github.com/benchkram/bob/pkg/usererror"
 	"github.com/benchkram/errz"
 	"gopkg.in/yaml.v3"
@@ -15,6 +17,7 @@ import (
 const (
 	pathSelector  string = "path"
 	imageSelector string = "image"
+	ociSelector   string = "oci"
 )
 
 // parseTargets parses target definitions from yaml.
@@ -43,16 +46,37 @@ const (
 //	  image: |-
 //			docker-image-name
 //			docker-image2-name
+//
+// target:
+//
+//	oci:
+//	  path: bin/server
+//	  base: images/alpine:3.19
+//	  entrypoint: [/bin/server]
+//	  env: [PORT=8080]
+//	  tag: server:latest
 func (t *Task) parseTargets() error {
 
 	var filesystemEntries []string
 	var dockerImages []string
+	var ociImage *ocilayout.Config
 	var err error
 
 	switch td := t.TargetDirty.(type) {
 	case string:
 		filesystemEntries, err = parseTargetPath(td)
 	case map[string]interface{}:
+		if oci, ok := td[ociSelector]; ok {
+			if len(td) > 1 {
+				return usererror.Wrapm(ErrAmbigousTargetDefinition, fmt.Sprintf("[task:%s]", t.name))
+			}
+			filesystemEntries, ociImage, err = parseTargetOCI(oci)
+			if err != nil {
+				return usererror.Wrapm(err, fmt.Sprintf("[task:%s]", t.name))
+			}
+			break
+		}
+
 		targets, targetType, err := parseTargetMap(td)
 		if err != nil {
 			return usererror.Wrapm(err, fmt.Sprintf("[task:%s]", t.name))
@@ -74,6 +98,7 @@ func (t *Task) parseTargets() error {
 		t.target = target.New(
 			target.WithFilesystemEntries(filesystemEntries),
 			target.WithDockerImages(dockerImages),
+			target.WithOCIImage(ociImage),
 			target.WithDir(t.dir),
 		)
 	}
@@ -106,6 +131,91 @@ func parseTargetMap(tm map[string]interface{}) ([]string, targettype.T, error) {
 	return parseTargetImage(images.(string)), targettype.Docker, nil
 }
 
+// parseTargetOCI parses an image to be created from filesystem targets.
+func parseTargetOCI(v interface{}) (_ []string, _ *ocilayout.Config, err error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("%w, expected a map", ErrInvalidOCITarget)
+	}
+
+	image := &ocilayout.Config{}
+	var paths []string
+	for key, value := range m {
+		switch key {
+		case pathSelector:
+			s, ok := value.(string)
+			if !ok {
+				return nil, nil, fmt.Errorf("%w, `%s` must be a string", ErrInvalidOCITarget, key)
+			}
+			paths, err = parseTargetPath(s)
+		case "base":
+			image.Base, err = stringValue(key, value)
+		case "tag":
+			image.Tag, err = stringValue(key, value)
+		case "workdir":
+			image.WorkingDir, err = stringValue(key, value)
+		case "platform":
+			image.Platform, err = stringValue(key, value)
+			if err == nil {
+				_, err = ocilayout.ParsePlatform(image.Platform)
+				if err != nil {
+					err = fmt.Errorf("%w, %s", ErrInvalidOCITarget, err.Error())
+				}
+			}
+		case "entrypoint":
+			image.Entrypoint, err = stringListValue(key, value)
+		case "cmd":
+			image.Cmd, err = stringListValue(key, value)
+		case "env":
+			image.Env, err = stringListValue(key, value)
+			for _, kv := range image.Env {
+				if !strings.Contains(kv, "=") {
+					return nil, nil, fmt.Errorf("%w, env `%s` must be in the form `key=value`", ErrInvalidOCITarget, kv)
+				}
+			}
+		default:
+			return nil, nil, fmt.Errorf("%w, unknown key `%s`", ErrInvalidOCITarget, key)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(paths) == 0 {
+		return nil, nil, fmt.Errorf("%w, `path` is required", ErrInvalidOCITarget)
+	}
+
+	return paths, image, nil
+}
+
+func stringValue(key string, v interface{}) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%w, `%s` must be a string", ErrInvalidOCITarget, key)
+	}
+	return s, nil
+}
+
+// stringListValue accepts a single string or a list of strings.
+func stringListValue(key string, v interface{}) ([]string, error) {
+	switch l := v.(type) {
+	case string:
+		return []string{l}, nil
+	case []interface{}:
+		list := make([]string, 0, len(l))
+		for _, e := range l {
+			s, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w, `%s` must be a list of strings", ErrInvalidOCITarget, key)
+			}
+			list = append(list, s)
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("%w, `%s` must be a list of strings", ErrInvalidOCITarget, key)
+	}
+}
+
 func parseTargetPath(p string) ([]string, error) {
 	targets := []string{}
 	if p == "" {
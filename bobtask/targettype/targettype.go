@@ -5,4 +5,6 @@ type T string
 const (
 	Path   T = "path"
 	Docker T = "docker"
+	// OCI is an image layout created from filesystem targets
+	OCI T = "oci"
 )
@@ -1,6 +1,7 @@
 package bobtask
 
 import (
+	"fmt"
 	"sort"
 	"strings"
 
@@ -8,10 +9,12 @@ import (
 	"github.com/benchkram/bob/pkg/nix"
 	"github.com/logrusorgru/aurora"
 
+	"github.com/benchkram/bob/bobtask/buildinfo"
 	"github.com/benchkram/bob/bobtask/hash"
 	"github.com/benchkram/bob/bobtask/target"
 	"github.com/benchkram/bob/pkg/buildinfostore"
 	"github.com/benchkram/bob/pkg/dockermobyutil"
+	"github.com/benchkram/bob/pkg/ocilayout"
 	"github.com/benchkram/bob/pkg/store"
 )
 
@@ -81,6 +84,9 @@ type Task struct {
 	// hashIn stores the `In` has for reuse
 	hashIn *hash.In
 
+	// inputManifest lists the inputs hashIn was computed from
+	inputManifest *buildinfo.Inputs
+
 	// local store for artifacts
 	local store.Store
 
@@ -103,6 +109,9 @@ type Task struct {
 	// skippedInputs is a lists of skipped input files
 	skippedInputs []string
 
+	// output holds the last lines printed by the task's commands
+	output []string
+
 	// DependenciesDirty read from the bobfile
 	DependenciesDirty []string `yaml:"dependencies,omitempty"`
 
@@ -113,6 +122,36 @@ type Task struct {
 
 	// URL of nixpkgs used. If empty, will use local <nixpkgs> channel
 	nixpkgs string
+
+	// codec used to compress artifacts
+	codec Codec
+
+	// ResourcesDirty the task occupies while running
+	ResourcesDirty Resources `yaml:"resources,omitempty"`
+	// memory parsed from ResourcesDirty in bytes
+	memory uint64
+
+	// Pool limits the number of tasks of the same
+	// pool running in parallel. Pools are declared in the Bobfile.
+	Pool string `yaml:"pool,omitempty"`
+
+	// EnvDirty lists the variables visible to the task,
+	// see DeclaresEnv(). Nil when the task has no env block.
+	EnvDirty []string `yaml:"env,omitempty"`
+
+	// Secrets are injected as environment variables when the
+	// task runs. Values are never hashed and masked in logs.
+	Secrets []string `yaml:"secrets,omitempty"`
+	// secretsDir contains files named after secrets
+	secretsDir string
+
+	// Sandbox runs the task in a temporary tree only
+	// containing its inputs and the targets of dependencies.
+	Sandbox bool `yaml:"sandbox,omitempty"`
+
+	// Matrix expands the task into one task per combination
+	// of values, see Map.ExpandMatrices().
+	Matrix *Matrix `yaml:"matrix,omitempty"`
 }
 
 type TargetEntry interface{}
@@ -186,6 +225,24 @@ func (t *Task) IsValidDecoration() bool {
 	if t.TargetDirty != nil {
 		return false
 	}
+	if t.ResourcesDirty != (Resources{}) {
+		return false
+	}
+	if t.Pool != "" {
+		return false
+	}
+	if t.Sandbox {
+		return false
+	}
+	if t.EnvDirty != nil {
+		return false
+	}
+	if len(t.Secrets) > 0 {
+		return false
+	}
+	if t.Matrix != nil {
+		return false
+	}
 	return true
 }
 
@@ -211,11 +268,7 @@ func (t *Task) description() string {
 	// env is influenced by t.dependencies, so no need to hash t.dependencies
 	sort.Strings(t.env)
 	for _, v := range t.env {
-		// ignore buildCommandPath and SHLVL due to non-reproducibility
-		if strings.Contains(v, "buildCommandPath=") {
-			continue
-		}
-		if strings.Contains(v, "shlvl=") {
+		if isIrreproducibleEnv(v) || t.isSecret(v) {
 			continue
 		}
 		sb.WriteString(v)
@@ -228,7 +281,23 @@ func (t *Task) description() string {
 		for _, v := range t.target.FilesystemEntriesRaw() {
 			sb.WriteString(v)
 		}
+		for _, v := range t.target.FilesystemExcludesRaw() {
+			sb.WriteString("!" + v)
+		}
+		if image := t.target.OCIImage(); image != nil {
+			sb.WriteString(fmt.Sprintf("%v", *image))
+			if image.Platform == "" {
+				// the default depends on the host
+				sb.WriteString(ocilayout.DefaultPlatform())
+			}
+		}
 	}
 
 	return sb.String()
 }
+
+// isIrreproducibleEnv is true for buildCommandPath and SHLVL,
+// those are ignored due to non-reproducibility.
+func isIrreproducibleEnv(v string) bool {
+	return strings.Contains(v, "buildCommandPath=") || strings.Contains(v, "shlvl=")
+}
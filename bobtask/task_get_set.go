@@ -84,6 +84,19 @@ func (t *Task) SetProject(proj string) {
 	t.project = proj
 }
 
+// Codec returns the compression codec used for artifacts
+// defaults to `gzip`.
+func (t *Task) Codec() Codec {
+	if t.codec == "" {
+		return DefaultCodec
+	}
+	return t.codec
+}
+
+func (t *Task) SetCodec(codec Codec) {
+	t.codec = codec
+}
+
 // Set the rebuild strategy for the task
 // defaults to `on-change`.
 func (t *Task) SetRebuildStrategy(rebuild RebuildType) {
@@ -115,3 +128,8 @@ func (t *Task) WithEnvStore(s envutil.Store) *Task {
 	t.envStore = s
 	return t
 }
+
+func (t *Task) WithSecretsDir(dir string) *Task {
+	t.secretsDir = dir
+	return t
+}
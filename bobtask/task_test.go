@@ -1,11 +1,18 @@
 package bobtask
 
 import (
+	"os"
 	"testing"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/benchkram/bob/bobtask/buildinfo"
+	"github.com/benchkram/bob/bobtask/hash"
+	"github.com/benchkram/bob/pkg/buildinfostore"
+	"github.com/benchkram/bob/pkg/ocilayout"
+	"github.com/benchkram/bob/pkg/store/filestore"
 )
 
 var withLowercase = `
@@ -63,3 +70,97 @@ func TestTaskUnmarshalYAMLWithBothDependsOn(t *testing.T) {
 	err := yaml.Unmarshal([]byte(withBoth), &task)
 	assert.EqualError(t, err, "both `dependson` and `dependsOn` nodes detected near line 2")
 }
+
+func TestDidTaskChangeRemoteBuildinfo(t *testing.T) {
+	localDir, err := os.MkdirTemp("", "test-did-task-change-local")
+	assert.Nil(t, err)
+	remoteDir, err := os.MkdirTemp("", "test-did-task-change-remote")
+	assert.Nil(t, err)
+	defer func() {
+		os.RemoveAll(localDir)
+		os.RemoveAll(remoteDir)
+	}()
+
+	local := buildinfostore.NewProtoStore(localDir)
+	remote := buildinfostore.NewRemoteStore(filestore.New(remoteDir))
+
+	// a teammate already built the same input hash
+	hashIn := hash.In("aaa")
+	bi := buildinfo.New()
+	bi.Meta.Task = "build"
+	assert.Nil(t, remote.NewBuildInfo(hashIn.String(), bi))
+
+	tsk := Make()
+	tsk.name = "build"
+	tsk.hashIn = &hashIn
+
+	tsk.WithBuildinfoStore(buildinfostore.NewLayeredStore(local, remote))
+	changed, err := tsk.DidTaskChange()
+	assert.Nil(t, err)
+	assert.True(t, changed, "pulling is disabled")
+
+	tsk.WithBuildinfoStore(buildinfostore.NewLayeredStore(local, remote, buildinfostore.WithPullEnabled(true)))
+	changed, err = tsk.DidTaskChange()
+	assert.Nil(t, err)
+	assert.False(t, changed)
+}
+
+var withOCITarget = `
+cmd: go build -o bin/server
+target:
+  oci:
+    path: bin/server
+    base: images/alpine:3.19
+    entrypoint: [/bin/server]
+    env: [PORT=8080]
+    tag: server:latest
+`
+
+func TestTaskParseOCITarget(t *testing.T) {
+	var task Task
+	err := yaml.Unmarshal([]byte(withOCITarget), &task)
+	assert.Nil(t, err)
+	task.SetDir("service")
+
+	err = task.parseTargets()
+	assert.Nil(t, err)
+
+	image := task.target.OCIImage()
+	assert.NotNil(t, image)
+	assert.Equal(t, "service/images/alpine:3.19", image.Base)
+	assert.Equal(t, []string{"/bin/server"}, image.Entrypoint)
+	assert.Equal(t, []string{"PORT=8080"}, image.Env)
+	assert.Equal(t, "server:latest", image.Tag)
+	assert.Equal(t, []string{"service/bin/server"}, task.target.FilesystemEntriesRaw())
+
+	task.TargetDirty = map[string]interface{}{
+		"oci":  map[string]interface{}{"path": "bin/server"},
+		"path": "bin/server",
+	}
+	assert.ErrorIs(t, task.parseTargets(), ErrAmbigousTargetDefinition)
+
+	task.TargetDirty = map[string]interface{}{
+		"oci": map[string]interface{}{"entrypoint": "/bin/server"},
+	}
+	assert.ErrorIs(t, task.parseTargets(), ErrInvalidOCITarget)
+
+	// the platform is part of the description
+	task.TargetDirty = map[string]interface{}{
+		"oci": map[string]interface{}{"path": "bin/server"},
+	}
+	assert.Nil(t, task.parseTargets())
+	description := task.description()
+	assert.Contains(t, description, ocilayout.DefaultPlatform())
+
+	task.TargetDirty = map[string]interface{}{
+		"oci": map[string]interface{}{"path": "bin/server", "platform": "linux/arm/v7"},
+	}
+	assert.Nil(t, task.parseTargets())
+	assert.Equal(t, "linux/arm/v7", task.target.OCIImage().Platform)
+	assert.NotEqual(t, description, task.description())
+
+	task.TargetDirty = map[string]interface{}{
+		"oci": map[string]interface{}{"path": "bin/server", "platform": "arm64"},
+	}
+	assert.ErrorIs(t, task.parseTargets(), ErrInvalidOCITarget)
+}
@@ -0,0 +1,221 @@
+package bobtask
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benchkram/bob/bob/global"
+	"github.com/benchkram/bob/pkg/proctrace"
+	"github.com/benchkram/errz"
+	"mvdan.cc/sh/expand"
+	"mvdan.cc/sh/interp"
+)
+
+// TraceResult lists workspace files accessed by a traced run,
+// paths are relative to the workspace root.
+type TraceResult struct {
+	Read    []string
+	Written []string
+}
+
+// RunTraced runs the task while recording the files it accesses.
+// Files opened by the shell itself are always recorded. Files opened
+// by child processes are traced where proctrace is supported, other
+// platforms fall back to recording files passed as arguments.
+func (t *Task) RunTraced(ctx context.Context, namePad int) (_ *TraceResult, err error) {
+	defer errz.Recover(&err)
+
+	workspace, err := filepath.Abs(".")
+	errz.Fatal(err)
+	// paths read from /proc are resolved
+	workspace, err = filepath.EvalSymlinks(workspace)
+	errz.Fatal(err)
+
+	tr := &tracer{
+		read:    map[string]struct{}{},
+		written: map[string]struct{}{},
+	}
+
+	pt := proctrace.New()
+	exec := tracedExec(pt)
+	if !proctrace.Supported() {
+		exec = tr.exec(exec)
+	}
+	err = t.run(ctx, namePad, t.dir,
+		interp.Module(exec),
+		interp.Module(tr.open(interp.OpenDevImpls(interp.DefaultOpen))),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	read, written := pt.Files()
+
+	for _, path := range read {
+		tr.read[path] = struct{}{}
+	}
+	for _, path := range written {
+		tr.written[path] = struct{}{}
+	}
+
+	result := &TraceResult{}
+	for path := range tr.written {
+		if rel, ok := workspaceFile(workspace, path); ok {
+			result.Written = append(result.Written, rel)
+		}
+	}
+	for path := range tr.read {
+		if _, ok := tr.written[path]; ok {
+			continue
+		}
+		rel, ok := workspaceFile(workspace, path)
+		if !ok || t.isTargetPath(rel) {
+			continue
+		}
+		result.Read = append(result.Read, rel)
+	}
+	sort.Strings(result.Read)
+	sort.Strings(result.Written)
+
+	return result, nil
+}
+
+// workspaceFile returns the path relative to the workspace if it is a
+// regular file inside the workspace and not part of bob's cache.
+func workspaceFile(workspace, path string) (string, bool) {
+	path, err := filepath.EvalSymlinks(path)
+	if err != nil || !isWithin(workspace, path) {
+		return "", false
+	}
+	rel, err := filepath.Rel(workspace, path)
+	if err != nil {
+		return "", false
+	}
+	if strings.HasPrefix(rel, global.BobCacheDir+string(filepath.Separator)) ||
+		strings.HasPrefix(rel, global.BobLogsDir+string(filepath.Separator)) ||
+		rel == global.BobWorkspaceFile {
+		return "", false
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	return rel, true
+}
+
+type tracer struct {
+	mu      sync.Mutex
+	read    map[string]struct{}
+	written map[string]struct{}
+}
+
+func (tr *tracer) add(dir, path string, write bool) {
+	if path == "" {
+		return
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	path = filepath.Clean(path)
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if write {
+		tr.written[path] = struct{}{}
+	} else {
+		tr.read[path] = struct{}{}
+	}
+}
+
+func (tr *tracer) exec(next interp.ModuleExec) interp.ModuleExec {
+	return func(ctx context.Context, path string, args []string) error {
+		mc, _ := interp.FromModuleContext(ctx)
+		for _, arg := range pathArgs(args) {
+			// arguments which aren't files are dropped later on
+			tr.add(mc.Dir, arg, false)
+		}
+		return next(ctx, path, args)
+	}
+}
+
+func (tr *tracer) open(next interp.ModuleOpen) interp.ModuleOpen {
+	return func(ctx context.Context, path string, flag int, perm os.FileMode) (io.ReadWriteCloser, error) {
+		mc, _ := interp.FromModuleContext(ctx)
+		tr.add(mc.Dir, path, flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_APPEND|os.O_TRUNC) != 0)
+		return next(ctx, path, flag, perm)
+	}
+}
+
+// tracedExec executes commands like interp.DefaultExec while
+// recording the files opened by them and their child processes.
+func tracedExec(pt *proctrace.Tracer) interp.ModuleExec {
+	return func(ctx context.Context, path string, args []string) error {
+		mc, _ := interp.FromModuleContext(ctx)
+		if path == "" {
+			fmt.Fprintf(mc.Stderr, "%q: executable file not found in $PATH\n", args[0])
+			return interp.ExitStatus(127)
+		}
+
+		var env []string
+		mc.Env.Each(func(name string, vr expand.Variable) bool {
+			if vr.Exported {
+				env = append(env, name+"="+vr.String())
+			}
+			return true
+		})
+
+		p, err := pt.Start(&exec.Cmd{
+			Path:   path,
+			Args:   args,
+			Env:    env,
+			Dir:    mc.Dir,
+			Stdin:  mc.Stdin,
+			Stdout: mc.Stdout,
+			Stderr: mc.Stderr,
+		})
+		var execErr *exec.Error
+		if errors.As(err, &execErr) {
+			fmt.Fprintf(mc.Stderr, "%v\n", err)
+			return interp.ExitStatus(127)
+		} else if err != nil {
+			return err
+		}
+
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-done:
+			case <-ctx.Done():
+				if mc.KillTimeout > 0 {
+					_ = p.Signal(os.Interrupt)
+					select {
+					case <-done:
+						return
+					case <-time.After(mc.KillTimeout):
+					}
+				}
+				_ = p.Signal(os.Kill)
+			}
+		}()
+
+		err = p.Wait()
+		var exitErr *proctrace.ExitError
+		if errors.As(err, &exitErr) {
+			if exitErr.Signal != nil && ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return interp.ExitStatus(uint8(exitErr.Code))
+		}
+		return err
+	}
+}
@@ -0,0 +1,46 @@
+package bobtask
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/benchkram/bob/pkg/envutil"
+	"github.com/benchkram/bob/pkg/proctrace"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunTraced(t *testing.T) {
+	if !proctrace.Supported() {
+		t.Skip("tracing not supported")
+	}
+
+	testdir, err := os.MkdirTemp("", "test-run-traced")
+	assert.Nil(t, err)
+	defer os.RemoveAll(testdir)
+
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(testdir))
+	defer func() { _ = os.Chdir(wd) }()
+
+	assert.Nil(t, os.WriteFile("in.txt", []byte("in"), 0664))
+
+	tsk := Make()
+	tsk.dir = "."
+	tsk.name = "traced"
+	// the file is only read by a child process of the shell
+	tsk.cmds = []string{"sh -c 'cat in.txt > out; cat missing.txt 2>/dev/null || true'"}
+	tsk.envStore = envutil.Store{"": os.Environ()}
+
+	result, err := tsk.RunTraced(context.Background(), 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"in.txt"}, result.Read)
+	assert.Equal(t, []string{"out"}, result.Written)
+
+	// failing commands are reported
+	tsk.cmds = []string{"sh -c 'exit 3'"}
+	_, err = tsk.RunTraced(context.Background(), 0)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "exit status 3")
+}
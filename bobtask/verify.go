@@ -26,13 +26,23 @@ func (t *Task) VerifyAfter() error {
 func (t *Task) verifyBefore() (err error) {
 	if t.target != nil {
 		for _, path := range t.target.FilesystemEntriesRawPlain() {
-			if !isValidFilesystemTarget(path) {
+			if !isValidFilesystemTarget(strings.TrimPrefix(path, "!")) {
 				return usererror.Wrap(fmt.Errorf("invalid target `%s` for task `%s`", path, t.name))
 			}
 		}
 	}
 
-	return nil
+	err = t.verifyResources()
+	if err != nil {
+		return err
+	}
+
+	err = t.verifyEnv()
+	if err != nil {
+		return err
+	}
+
+	return t.verifySecrets()
 }
 
 // isValidFilesystemTarget checks if target is a valid path inside
@@ -38,3 +38,30 @@ func TestIsValidSystemTarget(t *testing.T) {
 		}
 	}
 }
+
+func TestParseMemory(t *testing.T) {
+	tests := []struct {
+		input string
+		want  uint64
+	}{
+		{input: "", want: 0},
+		{input: "1024", want: 1024},
+		{input: "512M", want: 512 << 20},
+		{input: "8G", want: 8 << 30},
+		{input: "8g", want: 8 << 30},
+		{input: "1.5Gi", want: 3 << 29},
+		{input: "2GB", want: 2 << 30},
+		{input: "100KiB", want: 100 << 10},
+	}
+
+	for _, tc := range tests {
+		got, err := ParseMemory(tc.input)
+		assert.Nil(t, err, tc.input)
+		assert.Equal(t, tc.want, got, tc.input)
+	}
+
+	for _, input := range []string{"G", "eight", "-1G", "8X"} {
+		_, err := ParseMemory(input)
+		assert.NotNil(t, err, input)
+	}
+}
@@ -2,8 +2,10 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"strings"
@@ -14,6 +16,8 @@ import (
 
 	"github.com/benchkram/bob/bob"
 	"github.com/benchkram/bob/bob/global"
+	"github.com/benchkram/bob/bob/playbook"
+	"github.com/benchkram/bob/bobtask"
 	"github.com/benchkram/bob/pkg/boblog"
 	"github.com/benchkram/bob/pkg/usererror"
 )
@@ -46,12 +50,59 @@ var buildCmd = &cobra.Command{
 		noPull, err := cmd.Flags().GetBool("no-pull")
 		errz.Fatal(err)
 
+		compression, err := cmd.Flags().GetString("compression")
+		errz.Fatal(err)
+		var codec bobtask.Codec
+		if compression != "" {
+			codec, err = bobtask.ParseCodec(compression)
+			if err != nil {
+				boblog.Log.Error(err, "invalid compression")
+				os.Exit(1)
+			}
+		}
+
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		errz.Fatal(err)
+
+		strict, err := cmd.Flags().GetBool("strict")
+		errz.Fatal(err)
+
+		watch, err := cmd.Flags().GetBool("watch")
+		errz.Fatal(err)
+		if watch && dryRun {
+			boblog.Log.Error(fmt.Errorf("--watch can't be combined with --dry-run"), "invalid flags")
+			os.Exit(1)
+		}
+
+		output, err := cmd.Flags().GetString("output")
+		errz.Fatal(err)
+		switch {
+		case output != outputText && output != outputJSON && output != outputJSONL:
+			boblog.Log.Error(fmt.Errorf("unknown output format [%s]", output), "output must be one of [text, json, jsonl]")
+			os.Exit(1)
+		case dryRun && output == outputJSONL:
+			boblog.Log.Error(fmt.Errorf("invalid output format [%s]", output), "--dry-run supports [text, json]")
+			os.Exit(1)
+		case !dryRun && output == outputJSON:
+			boblog.Log.Error(fmt.Errorf("invalid output format [%s]", output), "json output requires --dry-run, use jsonl to stream build events")
+			os.Exit(1)
+		}
+
+		eventsFile, err := cmd.Flags().GetString("events-file")
+		errz.Fatal(err)
+
+		traceFile, err := cmd.Flags().GetString("trace-file")
+		errz.Fatal(err)
+
+		junitFile, err := cmd.Flags().GetString("junit-file")
+		errz.Fatal(err)
+
 		taskname := global.DefaultBuildTask
 		if len(args) > 0 {
 			taskname = args[0]
 		}
 
-		runBuild(taskname, noCache, allowInsecure, enablePush, noPull, flagEnvVars, maxParallel)
+		runBuild(taskname, noCache, allowInsecure, enablePush, noPull, dryRun, strict, watch, output, eventsFile, traceFile, junitFile, codec, flagEnvVars, maxParallel)
 	},
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		tasks, err := getBuildTasks()
@@ -72,20 +123,54 @@ var buildListCmd = &cobra.Command{
 	},
 }
 
-func runBuild(taskname string, noCache, allowInsecure, enablePush, noPull bool, flagEnvVars []string, maxParallel int) {
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputJSONL = "jsonl"
+)
+
+func runBuild(taskname string, noCache, allowInsecure, enablePush, noPull, dryRun, strict, watch bool, output, eventsFile, traceFile, junitFile string, codec bobtask.Codec, flagEnvVars []string, maxParallel int) {
 	var exitCode int
 	defer func() {
 		exit(exitCode)
 	}()
 	defer errz.Recover()
 
+	// results and events are written to stdout
+	var stdout io.Writer = os.Stdout
+	if output == outputJSON || output == outputJSONL {
+		// keep stdout parsable, progress output goes to stderr
+		boblog.SetOutput(os.Stderr)
+		defer boblog.SetOutput(os.Stdout)
+	}
+
+	var eventWriters []io.Writer
+	if output == outputJSONL {
+		eventWriters = append(eventWriters, stdout)
+	}
+	if eventsFile != "" {
+		f, err := os.Create(eventsFile)
+		if err != nil {
+			exitCode = 1
+			errz.Fatal(err)
+		}
+		defer f.Close()
+		eventWriters = append(eventWriters, f)
+	}
+
 	b, err := bob.Bob(
 		bob.WithCachingEnabled(!noCache),
 		bob.WithInsecure(allowInsecure),
 		bob.WithEnvVariables(parseEnvVarsFlag(flagEnvVars)),
+		bob.WithContainerRuntime(flagContainerRuntime, flagContainerHost),
 		bob.WithMaxParallel(maxParallel),
+		bob.WithStrict(strict),
 		bob.WithPushEnabled(enablePush),
 		bob.WithPullEnabled(!noPull),
+		bob.WithCodec(codec),
+		bob.WithEventHandler(eventHandler(eventWriters...)),
+		bob.WithTraceFile(traceFile),
+		bob.WithJUnitFile(junitFile),
 	)
 	if err != nil {
 		exitCode = 1
@@ -103,7 +188,14 @@ func runBuild(taskname string, noCache, allowInsecure, enablePush, noPull bool,
 		cancel()
 	}()
 
-	err = b.Build(ctx, taskname)
+	switch {
+	case dryRun:
+		err = runBuildDryRun(ctx, b, stdout, taskname, output)
+	case watch:
+		err = b.Watch(ctx, taskname)
+	default:
+		err = b.Build(ctx, taskname)
+	}
 	if err != nil {
 		exitCode = 1
 		if errors.As(err, &usererror.Err) {
@@ -114,6 +206,35 @@ func runBuild(taskname string, noCache, allowInsecure, enablePush, noPull bool,
 	}
 }
 
+// runBuildDryRun prints what a build would do to w.
+func runBuildDryRun(ctx context.Context, b *bob.B, w io.Writer, taskname string, output string) (err error) {
+	defer errz.Recover(&err)
+
+	results, err := b.BuildDryRun(ctx, taskname)
+	errz.Fatal(err)
+
+	switch output {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(results)
+		errz.Fatal(err)
+	default:
+		playbook.PrintDryRun(w, results)
+	}
+
+	return nil
+}
+
+// eventHandler writes build events as json lines to all writers.
+// Returns nil when no writer is given.
+func eventHandler(writers ...io.Writer) playbook.EventHandler {
+	if len(writers) == 0 {
+		return nil
+	}
+	return playbook.NewJSONLEventHandler(io.MultiWriter(writers...))
+}
+
 func runBuildList() {
 	b, err := bob.Bob()
 	boblog.Log.Error(err, "Unable to initialize bob")
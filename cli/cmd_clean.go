@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -36,7 +37,8 @@ var cleanSystemCmd = &cobra.Command{
 	Short: "Remove buildinfo and local cache",
 	Long: `Remove all entries from 
   ~/.bobcache/buildinfo 
-  ~/.bobcache/artifacts`,
+  ~/.bobcache/artifacts
+  ~/.bobcache/blobs`,
 	Run: func(cmd *cobra.Command, args []string) {
 		runCleanSystem()
 	},
@@ -55,6 +57,36 @@ func runCleanSystem() {
 	fmt.Println(".nix_cache cleaned")
 }
 
+var cleanArtifactsCmd = &cobra.Command{
+	Use:   "artifacts [artifact-id...]",
+	Short: "Remove artifacts from the local cache",
+	Long: `Remove the given artifacts from the local cache.
+Blobs are reference counted and only removed when no other artifact
+references them. Without arguments only unreferenced blobs are removed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runCleanArtifacts(args)
+	},
+}
+
+func runCleanArtifacts(artifactIDs []string) {
+	b, err := bob.Bob()
+	boblog.Log.Error(err, "Unable to initialise bob")
+
+	err = b.ArtifactRemove(context.TODO(), artifactIDs...)
+	if err != nil {
+		if errors.As(err, &usererror.Err) {
+			boblog.Log.UserError(err)
+		} else {
+			errz.Fatal(err)
+		}
+		os.Exit(1)
+	}
+
+	for _, id := range artifactIDs {
+		fmt.Printf("artifact %s removed\n", id)
+	}
+}
+
 var cleanTargetsCmd = &cobra.Command{
 	Use:   "targets",
 	Short: "Remove targets declared by the current project",
@@ -48,9 +48,6 @@ var composeCmd = &cobra.Command{
 				fmt.Println("Conflicting ports detected:")
 				fmt.Println(portConflicts)
 
-				// TODO: disable once we also resolve binaries' ports
-				errz.Fatal(fmt.Errorf(fmt.Sprint("conflicting ports detected:\n", conflicts)))
-
 				resolved, err := composeutil.ResolvePortConflicts(conflicts)
 				errz.Fatal(err)
 
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/benchkram/errz"
+	"github.com/spf13/cobra"
+
+	"github.com/benchkram/bob/bob"
+	"github.com/benchkram/bob/pkg/boblog"
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [task]",
+	Short: "Build a task and export the image of its oci target",
+	Args:  cobra.ExactArgs(1),
+	Long: `Build a task and export the image of its oci target.
+
+The image is written as OCI image layout, no container daemon is required.
+Load it with e.g. "skopeo copy oci:out/ docker-daemon:server:latest".`,
+	Run: func(cmd *cobra.Command, args []string) {
+		oci, err := cmd.Flags().GetString("oci")
+		errz.Fatal(err)
+		if oci == "" {
+			boblog.Log.Error(fmt.Errorf("--oci is required"), "invalid flags")
+			os.Exit(1)
+		}
+
+		allowInsecure, err := cmd.Flags().GetBool("insecure")
+		errz.Fatal(err)
+
+		runExport(args[0], oci, allowInsecure)
+	},
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		tasks, err := getBuildTasks()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return tasks, cobra.ShellCompDirectiveDefault
+	},
+}
+
+func runExport(taskname, dst string, allowInsecure bool) {
+	var exitCode int
+	defer func() {
+		exit(exitCode)
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+		<-stop
+		cancel()
+	}()
+
+	b, err := bob.Bob(
+		bob.WithInsecure(allowInsecure),
+		bob.WithEnvVariables(parseEnvVarsFlag(flagEnvVars)),
+		bob.WithContainerRuntime(flagContainerRuntime, flagContainerHost),
+	)
+	if err == nil {
+		err = b.ExportOCI(ctx, taskname, dst)
+	}
+	if err != nil {
+		exitCode = 1
+		if errors.As(err, &usererror.Err) {
+			boblog.Log.UserError(err)
+		} else {
+			errz.Log(err)
+		}
+		return
+	}
+
+	fmt.Printf("Exported oci image of %s to %s\n", taskname, dst)
+}
@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"time"
 
 	"github.com/benchkram/bob/bob"
+	"github.com/benchkram/bob/bobtask/buildinfo"
 	"github.com/benchkram/bob/pkg/boblog"
 	"github.com/benchkram/bob/pkg/filehash"
+	"github.com/benchkram/bob/pkg/proctrace"
 	"github.com/benchkram/bob/pkg/usererror"
 	"github.com/benchkram/errz"
 	"github.com/logrusorgru/aurora"
@@ -24,8 +27,10 @@ func init() {
 	inspectArtifactCmd.Flags().StringVarP(&inspectArtifactId, "id", "",
 		inspectArtifactId, "inspect artifact with id")
 
+	inputCmd.Flags().Bool("trace", false, "Run the task once and suggest inputs based on the files it reads")
 	inspectCmd.AddCommand(inputCmd)
 	inspectCmd.AddCommand(envCmd)
+	inspectCmd.AddCommand(inspectWhyCmd)
 	// artifact
 	inspectArtifactCmd.AddCommand(inspectArtifactListCmd)
 	inspectCmd.AddCommand(inspectArtifactCmd)
@@ -46,7 +51,7 @@ var inspectCmd = &cobra.Command{
 
 var envCmd = &cobra.Command{
 	Use:   "env",
-	Short: "List environment for a task",
+	Short: "List the environment a task's commands run with",
 	Args:  cobra.ExactArgs(1),
 	Long:  ``,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -67,7 +72,7 @@ func runEnv(taskname string) {
 	b, err := bob.Bob()
 	boblog.Log.Error(err, "Unable to initialise bob")
 
-	bobfile, err := b.Aggregate()
+	bobfile, err := b.AggregateWithNixDeps(taskname)
 	boblog.Log.Error(err, "Unable to aggregate bob file")
 
 	task, ok := bobfile.BTasks[taskname]
@@ -75,13 +80,13 @@ func runEnv(taskname string) {
 		fmt.Printf("%s\n", aurora.Red("Task does not exists"))
 		exit(1)
 	}
-	task = bobfile.BTasks[taskname]
 
-	taskEnv, err := b.Nix().BuildEnvironment(task.Dependencies(), task.Nixpkgs())
+	taskEnv, err := task.Environment()
 	errz.Fatal(err)
 
+	sort.Strings(taskEnv)
 	for _, e := range taskEnv {
-		println(e)
+		fmt.Println(e)
 	}
 }
 
@@ -147,12 +152,21 @@ func runInspectArtifactList() {
 }
 
 var inputCmd = &cobra.Command{
-	Use:   "input",
-	Short: "List inputs",
-	Args:  cobra.ExactArgs(1),
-	Long:  ``,
+	Use:     "input",
+	Aliases: []string{"inputs"},
+	Short:   "List inputs",
+	Args:    cobra.ExactArgs(1),
+	Long:    ``,
 	Run: func(cmd *cobra.Command, args []string) {
 		taskname := args[0]
+
+		trace, err := cmd.Flags().GetBool("trace")
+		errz.Fatal(err)
+		if trace {
+			runInspectInputsTrace(taskname)
+			return
+		}
+
 		runInspectInputs(taskname)
 	},
 }
@@ -205,6 +219,49 @@ func runInspectInputs(taskname string) {
 	fmt.Printf("\tinput hash:          %s\n", hash)
 }
 
+// runInspectInputsTrace runs the task and suggests inputs based on the files it read
+func runInspectInputsTrace(taskname string) {
+	b, err := bob.Bob()
+	boblog.Log.Error(err, "Unable to initialise bob")
+
+	if !proctrace.Supported() {
+		fmt.Printf("%s\n", aurora.Yellow("Files opened by child processes can't be traced on this platform, the files read are a lower bound"))
+	}
+
+	result, err := b.TraceInputs(context.Background(), taskname)
+	if err != nil {
+		if errors.As(err, &usererror.Err) {
+			fmt.Printf("%s\n", aurora.Red(err.Error()))
+			exit(1)
+		}
+		errz.Log(err)
+		exit(1)
+	}
+
+	fmt.Println()
+	fmt.Printf("Suggested input for task %s", taskname)
+	if result.Dir != "." {
+		fmt.Printf(" (relative to %s)", result.Dir)
+	}
+	fmt.Println(":")
+	fmt.Println("  input: |-")
+	for _, input := range result.Suggested {
+		fmt.Printf("    %s\n", input)
+	}
+	fmt.Println()
+
+	if len(result.Missing) == 0 && len(result.Unused) == 0 {
+		fmt.Println(aurora.Green("Declared inputs match the files read"))
+		return
+	}
+	for _, path := range result.Missing {
+		fmt.Printf("\t%s %s\n", aurora.Green("missing "), path)
+	}
+	for _, path := range result.Unused {
+		fmt.Printf("\t%s %s\n", aurora.Yellow("not read"), path)
+	}
+}
+
 var inspectBuildInfoCmd = &cobra.Command{
 	Use:   "buildinfo",
 	Short: "Inspect build info",
@@ -265,3 +322,81 @@ func diffBuildInfo(hashA, hashB string) {
 	diffs := dmp.DiffMain(biA.Describe(), biB.Describe(), false)
 	fmt.Println(dmp.DiffPrettyText(diffs))
 }
+
+var inspectWhyCmd = &cobra.Command{
+	Use:   "why",
+	Short: "Explain why the input hash of a task changed since its last build",
+	Args:  cobra.ExactArgs(1),
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		runInspectWhy(args[0])
+	},
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		tasks, err := getBuildTasks()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		return tasks, cobra.ShellCompDirectiveDefault
+	},
+}
+
+func runInspectWhy(taskname string) {
+	b, err := bob.Bob()
+	boblog.Log.Error(err, "Unable to initialise bob")
+
+	why, err := b.Why(taskname)
+	if err != nil {
+		if errors.As(err, &usererror.Err) {
+			fmt.Printf("%s\n", aurora.Red(err.Error()))
+			exit(1)
+		}
+		errz.Log(err)
+		exit(1)
+	}
+
+	previous := why.Previous.Meta
+	fmt.Printf("Comparing against the last build of %s [input hash: %s]", taskname, previous.InputHash)
+	if previous.CreatedAt != 0 {
+		fmt.Printf(" from %s", time.Unix(0, previous.CreatedAt).Format(time.RFC3339))
+	}
+	fmt.Println()
+
+	if why.UpToDate() {
+		fmt.Println(aurora.Green("Inputs did not change"))
+		return
+	}
+	if why.Diff.Empty() {
+		// e.g. hash version bumps or changes to the task's name
+		fmt.Printf("Input hash changed [%s => %s] without changes to the recorded inputs\n", previous.InputHash, why.InputHash)
+		return
+	}
+
+	printChanges("files", why.Diff.Files)
+	printChanges("env", why.Diff.Env)
+	printChanges("cmds", why.Diff.Cmds)
+	printChanges("dependencies", why.Diff.Dependencies)
+	printChanges("targets", why.Diff.Targets)
+	if why.Diff.Nixpkgs != nil {
+		fmt.Println("nixpkgs:")
+		fmt.Printf("\t%s %s\n", aurora.Red("-"), why.Diff.Nixpkgs[0])
+		fmt.Printf("\t%s %s\n", aurora.Green("+"), why.Diff.Nixpkgs[1])
+	}
+}
+
+func printChanges(name string, c buildinfo.Changes) {
+	if c.Empty() {
+		return
+	}
+
+	fmt.Printf("%s:\n", name)
+	for _, v := range c.Added {
+		fmt.Printf("\t%s %s\n", aurora.Green("added   "), v)
+	}
+	for _, v := range c.Removed {
+		fmt.Printf("\t%s %s\n", aurora.Red("removed "), v)
+	}
+	for _, v := range c.Modified {
+		fmt.Printf("\t%s %s\n", aurora.Yellow("modified"), v)
+	}
+}
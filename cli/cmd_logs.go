@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/benchkram/errz"
+	"github.com/spf13/cobra"
+
+	"github.com/benchkram/bob/bob"
+	"github.com/benchkram/bob/pkg/boblog"
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs [runtask]",
+	Short: "Show the logs of a run task written by bob run",
+	Args:  cobra.ExactArgs(1),
+	Long: `Show the logs of a run task written by bob run.
+Logs are kept in .bob/logs/<runtask>/ of the workspace,
+the directory is never used as a task input or watched.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		follow, err := cmd.Flags().GetBool("follow")
+		errz.Fatal(err)
+
+		runLogs(args[0], follow)
+	},
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		tasks, err := getRunTasks()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return tasks, cobra.ShellCompDirectiveDefault
+	},
+}
+
+func runLogs(taskname string, follow bool) {
+	var exitCode int
+	defer func() {
+		exit(exitCode)
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+		<-stop
+		cancel()
+	}()
+
+	b, err := bob.Bob()
+	if err == nil {
+		err = b.Logs(ctx, taskname, os.Stdout, follow)
+	}
+	if err != nil {
+		exitCode = 1
+		if errors.As(err, &usererror.Err) {
+			boblog.Log.UserError(err)
+		} else {
+			errz.Log(err)
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 
 var zsh bool
 var flagEnvVars []string
+var flagContainerRuntime, flagContainerHost string
 
 func init() {
 	configInit()
@@ -45,14 +46,32 @@ func init() {
 	runCmd.Flags().Bool("no-cache", false, "Set to true to not use cache")
 	runCmd.Flags().Bool("insecure", false, "Set to true to use http instead of https when accessing a remote artifact store")
 	runCmd.Flags().StringSliceVar(&flagEnvVars, "env", []string{}, "Set environment variables to run task")
+	runCmd.Flags().StringVar(&flagContainerRuntime, "container-runtime", "", "Container runtime for docker image targets [docker, podman, containerd, auto], overrides the bobfile and BOB_CONTAINER_RUNTIME")
+	runCmd.Flags().StringVar(&flagContainerHost, "container-host", "", "Socket of the container runtime, e.g. unix:///run/podman/podman.sock")
+	runCmd.Flags().String("events-file", "", "Write build events as json lines to a file")
+	runCmd.Flags().Bool("no-tui", false, "Stream the output of all run tasks prefixed with their names instead of starting the interactive terminal ui")
+	runCmd.Flags().Bool("exit-on-first", false, "Stop all run tasks as soon as one exits, requires --no-tui")
 	runCmd.AddCommand(runListCmd)
 	rootCmd.AddCommand(runCmd)
 
+	logsCmd.Flags().BoolP("follow", "f", false, "Keep printing new output of the run task")
+	rootCmd.AddCommand(logsCmd)
+
 	// buildCmd
 	buildCmd.Flags().Bool("dummy", false, "Create a dummy bobfile")
 	buildCmd.Flags().Bool("no-cache", false, "Set to true to not use cache")
 	buildCmd.Flags().Bool("push", false, "Set to true to push artifacts to remote store")
 	buildCmd.Flags().Bool("no-pull", false, "Set to true to disable artifacts download from remote store")
+	buildCmd.Flags().String("compression", "", "Compression codec used for artifacts [gzip, zstd, brotli, none], overrides the bobfile setting")
+	buildCmd.Flags().Bool("dry-run", false, "Print what a build would do without running any task")
+	buildCmd.Flags().Bool("watch", false, "Rebuild on changes to the inputs of the task and its dependencies")
+	buildCmd.Flags().Bool("strict", false, "Run all tasks sandboxed, failing on access to undeclared inputs or targets")
+	buildCmd.Flags().StringP("output", "o", "text", "Output format [text, json, jsonl], json is only supported with --dry-run, jsonl streams build events to stdout")
+	buildCmd.Flags().StringVar(&flagContainerRuntime, "container-runtime", "", "Container runtime for docker image targets [docker, podman, containerd, auto], overrides the bobfile and BOB_CONTAINER_RUNTIME")
+	buildCmd.Flags().StringVar(&flagContainerHost, "container-host", "", "Socket of the container runtime, e.g. unix:///run/podman/podman.sock")
+	buildCmd.Flags().String("events-file", "", "Write build events as json lines to a file")
+	buildCmd.Flags().String("trace-file", "", "Write task timings in chrome trace event format to a file")
+	buildCmd.Flags().String("junit-file", "", "Write a junit xml report with one test case per task to a file")
 	buildCmd.Flags().Bool("insecure", false, "Set to true to use http instead of https when accessing a remote artifact store")
 	buildCmd.Flags().Bool("debug", false, "Enable debug output")
 	buildCmd.Flags().IntP("jobs", "j", runtime.NumCPU(), "Maximum number of parallel started jobs")
@@ -60,6 +79,13 @@ func init() {
 	buildCmd.AddCommand(buildListCmd)
 	rootCmd.AddCommand(buildCmd)
 
+	exportCmd.Flags().String("oci", "", "Directory to write the oci image layout to")
+	exportCmd.Flags().Bool("insecure", false, "Set to true to use http instead of https when accessing a remote artifact store")
+	exportCmd.Flags().StringSliceVar(&flagEnvVars, "env", []string{}, "Set environment variables to build task")
+	exportCmd.Flags().StringVar(&flagContainerRuntime, "container-runtime", "", "Container runtime for docker image targets [docker, podman, containerd, auto], overrides the bobfile and BOB_CONTAINER_RUNTIME")
+	exportCmd.Flags().StringVar(&flagContainerHost, "container-host", "", "Socket of the container runtime, e.g. unix:///run/podman/podman.sock")
+	rootCmd.AddCommand(exportCmd)
+
 	// gitCmd
 	CmdGitCommit.Flags().StringP("message", "m", "", "Set the commit message for all repository")
 	CmdGit.AddCommand(CmdGitAdd)
@@ -81,6 +107,7 @@ func init() {
 	cleanCmd.AddCommand(cleanTargetsCmd)
 	cleanCmd.AddCommand(cleanSystemCmd)
 	cleanCmd.AddCommand(cleanAllCmd)
+	cleanCmd.AddCommand(cleanArtifactsCmd)
 	rootCmd.AddCommand(cleanCmd)
 }
 
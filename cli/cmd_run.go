@@ -2,6 +2,11 @@ package cli
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/benchkram/errz"
 	"github.com/pkg/errors"
@@ -31,7 +36,25 @@ var runCmd = &cobra.Command{
 		allowInsecure, err := cmd.Flags().GetBool("insecure")
 		errz.Fatal(err)
 
-		run(taskname, noCache, allowInsecure)
+		eventsFile, err := cmd.Flags().GetString("events-file")
+		errz.Fatal(err)
+
+		noTUI, err := cmd.Flags().GetBool("no-tui")
+		errz.Fatal(err)
+
+		exitOnFirst, err := cmd.Flags().GetBool("exit-on-first")
+		errz.Fatal(err)
+		if exitOnFirst && !noTUI {
+			boblog.Log.Error(fmt.Errorf("--exit-on-first requires --no-tui"), "invalid flags")
+			os.Exit(1)
+		}
+
+		if noTUI {
+			runPlain(taskname, noCache, allowInsecure, eventsFile, exitOnFirst)
+			return
+		}
+
+		run(taskname, noCache, allowInsecure, eventsFile)
 	},
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		tasks, err := getRunTasks()
@@ -42,17 +65,30 @@ var runCmd = &cobra.Command{
 	},
 }
 
-func run(taskname string, noCache bool, allowInsecure bool) {
+func run(taskname string, noCache bool, allowInsecure bool, eventsFile string) {
 	var exitCode int
 	defer func() {
 		exit(exitCode)
 	}()
 	defer errz.Recover()
 
+	var eventWriters []io.Writer
+	if eventsFile != "" {
+		f, err := os.Create(eventsFile)
+		if err != nil {
+			exitCode = 1
+			errz.Fatal(err)
+		}
+		defer f.Close()
+		eventWriters = append(eventWriters, f)
+	}
+
 	b, err := bob.Bob(
 		bob.WithCachingEnabled(!noCache),
 		bob.WithInsecure(allowInsecure),
 		bob.WithEnvVariables(parseEnvVarsFlag(flagEnvVars)),
+		bob.WithContainerRuntime(flagContainerRuntime, flagContainerHost),
+		bob.WithEventHandler(eventHandler(eventWriters...)),
 	)
 	if err != nil {
 		exitCode = 1
@@ -95,6 +131,74 @@ func run(taskname string, noCache bool, allowInsecure bool) {
 	}
 }
 
+// runPlain runs without the interactive tui, the output
+// of run tasks is streamed to stdout.
+func runPlain(taskname string, noCache bool, allowInsecure bool, eventsFile string, exitOnFirst bool) {
+	var exitCode int
+	defer func() {
+		exit(exitCode)
+	}()
+	defer errz.Recover()
+
+	var eventWriters []io.Writer
+	if eventsFile != "" {
+		f, err := os.Create(eventsFile)
+		if err != nil {
+			exitCode = 1
+			errz.Fatal(err)
+		}
+		defer f.Close()
+		eventWriters = append(eventWriters, f)
+	}
+
+	b, err := bob.Bob(
+		bob.WithCachingEnabled(!noCache),
+		bob.WithInsecure(allowInsecure),
+		bob.WithEnvVariables(parseEnvVarsFlag(flagEnvVars)),
+		bob.WithContainerRuntime(flagContainerRuntime, flagContainerHost),
+		bob.WithEventHandler(eventHandler(eventWriters...)),
+	)
+	if err != nil {
+		exitCode = 1
+		errz.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// shutdown run tasks on SIGINT and SIGTERM
+	go func() {
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+		<-stop
+		cancel()
+	}()
+
+	commander, err := b.Run(ctx, taskname)
+	if err != nil {
+		exitCode = 1
+		if errors.As(err, &usererror.Err) {
+			boblog.Log.UserError(err)
+			return
+		}
+		errz.Fatal(err)
+	}
+
+	err = tui.RunPlain(ctx, commander, exitOnFirst)
+	if err != nil {
+		exitCode = 1
+		if errors.As(err, &usererror.Err) {
+			boblog.Log.UserError(err)
+		} else {
+			boblog.Log.Error(err, "Error during commander execution")
+		}
+	}
+
+	cancel()
+	<-commander.Done()
+}
+
 func getRunTasks() ([]string, error) {
 	b, err := bob.Bob()
 	if err != nil {
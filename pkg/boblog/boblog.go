@@ -7,6 +7,11 @@ import (
 	"errors"
 	"fmt"
 	"github.com/benchkram/bob/pkg/usererror"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
 	"unicode"
 
 	"github.com/benchkram/errz"
@@ -17,6 +22,9 @@ var Log = log{level: 0}
 
 var globalLogLevel = 1
 
+// output log messages are written to.
+var output io.Writer = os.Stdout
+
 func SetLogLevel(level int) {
 	if level < 0 {
 		level = 0
@@ -24,6 +32,61 @@ func SetLogLevel(level int) {
 	globalLogLevel = level
 }
 
+// SetOutput sets the writer log messages are written to, defaults to os.Stdout.
+// Use os.Stderr to keep stdout parsable, e.g. for json output.
+func SetOutput(w io.Writer) {
+	output = w
+}
+
+// Output returns the writer log messages are written to.
+func Output() io.Writer {
+	return output
+}
+
+const mask = "***"
+
+var (
+	secretsMu sync.RWMutex
+	// secrets are sorted by length, longest first, to fully
+	// mask secrets containing another secret.
+	secrets    []string
+	secretsSet = map[string]bool{}
+)
+
+// AddSecrets registers values which are masked in all log output.
+// Values are registered once, no matter how often a task runs.
+func AddSecrets(values ...string) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+
+	var added bool
+	for _, v := range values {
+		if v == "" || secretsSet[v] {
+			continue
+		}
+		secretsSet[v] = true
+		secrets = append(secrets, v)
+		added = true
+	}
+
+	if added {
+		sort.SliceStable(secrets, func(i, j int) bool {
+			return len(secrets[i]) > len(secrets[j])
+		})
+	}
+}
+
+// Redact masks registered secrets in s.
+func Redact(s string) string {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, mask)
+	}
+	return s
+}
+
 type log struct {
 	level int
 }
@@ -41,7 +104,7 @@ func (l log) Info(msg string) {
 	if l.level > globalLogLevel {
 		return
 	}
-	fmt.Println(msg)
+	fmt.Fprintln(output, Redact(msg))
 }
 
 func (l log) Error(err error, msg string, keysAndValues ...interface{}) {
@@ -51,7 +114,7 @@ func (l log) Error(err error, msg string, keysAndValues ...interface{}) {
 	}
 
 	// Error message will always be logged if exists
-	fmt.Print(aurora.Red(msg + ": "))
+	fmt.Fprint(output, aurora.Red(msg+": "))
 
 	// Stack trace will only be logged if globalLogLevel >= 2
 	if globalLogLevel >= 2 {
@@ -66,7 +129,7 @@ func (l log) Error(err error, msg string, keysAndValues ...interface{}) {
 			err = er
 		}
 
-		fmt.Println(aurora.Red(err))
+		fmt.Fprintln(output, aurora.Red(Redact(err.Error())))
 	}
 }
 
@@ -99,5 +162,5 @@ func (l log) UserError(err error) {
 		msg = string(tmp)
 	}
 
-	fmt.Println(aurora.Red(msg))
+	fmt.Fprintln(output, aurora.Red(Redact(msg)))
 }
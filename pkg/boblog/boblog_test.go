@@ -0,0 +1,15 @@
+package boblog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	AddSecrets("abc", "xabcx")
+	AddSecrets("abc", "", "xabcx")
+
+	assert.Len(t, secrets, 2, "secrets are registered once")
+	assert.Equal(t, "key=*** other=***", Redact("key=xabcx other=abc"))
+}
@@ -46,7 +46,7 @@ func FindBobRoot() (string, error) {
 
 }
 
-// isBobRoot checks if a ".bob" folder is present in this directory
+// isBobRoot checks if a ".bob.workspace" file is present in this directory
 func isBobRoot(dir string) (bool, error) {
 	files, err := os.ReadDir(dir)
 	if err != nil {
@@ -0,0 +1,98 @@
+package buildinfostore
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/benchkram/errz"
+)
+
+// DurationStore keeps the most recent build duration of tasks.
+// Durations are a scheduling hint, losing them is not an error.
+type DurationStore interface {
+	Durations() (map[string]time.Duration, error)
+
+	// UpdateDurations merges the given durations into the store.
+	UpdateDurations(map[string]time.Duration) error
+}
+
+type ds struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewDurationStore creates a duration store persisted as json to path.
+// Parent directories are created on the first update.
+func NewDurationStore(path string) DurationStore {
+	return &ds{path: path}
+}
+
+// durations are stored in milliseconds to keep the file readable.
+type durationsFile map[string]int64
+
+func (ds *ds) Durations() (_ map[string]time.Duration, err error) {
+	defer errz.Recover(&err)
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	f, err := ds.read()
+	errz.Fatal(err)
+
+	durations := make(map[string]time.Duration, len(f))
+	for task, ms := range f {
+		durations[task] = time.Duration(ms) * time.Millisecond
+	}
+	return durations, nil
+}
+
+func (ds *ds) UpdateDurations(durations map[string]time.Duration) (err error) {
+	defer errz.Recover(&err)
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	f, err := ds.read()
+	errz.Fatal(err)
+
+	for task, d := range durations {
+		f[task] = d.Milliseconds()
+	}
+
+	b, err := json.Marshal(f)
+	errz.Fatal(err)
+
+	err = os.MkdirAll(filepath.Dir(ds.path), 0775)
+	errz.Fatal(err)
+
+	// write to a temporary file first, so concurrent
+	// readers never see a partially written file.
+	tmp := ds.path + ".tmp"
+	err = os.WriteFile(tmp, b, 0664)
+	errz.Fatal(err)
+
+	return os.Rename(tmp, ds.path)
+}
+
+func (ds *ds) read() (durationsFile, error) {
+	f := durationsFile{}
+
+	b, err := os.ReadFile(ds.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return f, nil
+		}
+		return nil, err
+	}
+
+	err = json.Unmarshal(b, &f)
+	if err != nil {
+		// a corrupted file is replaced on the next update
+		return durationsFile{}, nil
+	}
+	return f, nil
+}
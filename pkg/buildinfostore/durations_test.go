@@ -0,0 +1,33 @@
+package buildinfostore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDurationStore(t *testing.T) {
+	dir, err := os.MkdirTemp("", "test-durations")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	s := NewDurationStore(filepath.Join(dir, "durations", "workspace.json"))
+
+	durations, err := s.Durations()
+	assert.Nil(t, err)
+	assert.Empty(t, durations)
+
+	err = s.UpdateDurations(map[string]time.Duration{"build": time.Second, "test": 2 * time.Second})
+	assert.Nil(t, err)
+
+	// updates are merged
+	err = s.UpdateDurations(map[string]time.Duration{"build": 3 * time.Second})
+	assert.Nil(t, err)
+
+	durations, err = NewDurationStore(filepath.Join(dir, "durations", "workspace.json")).Durations()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]time.Duration{"build": 3 * time.Second, "test": 2 * time.Second}, durations)
+}
@@ -0,0 +1,110 @@
+package buildinfostore
+
+import (
+	"fmt"
+
+	"github.com/benchkram/errz"
+
+	"github.com/benchkram/bob/bobtask/buildinfo"
+	"github.com/benchkram/bob/pkg/boblog"
+)
+
+type ls struct {
+	local  Store
+	remote Store
+
+	// push writes new build infos to the remote store.
+	push bool
+	// pull reads build infos missing locally from the remote store.
+	pull bool
+	// cache writes pulled build infos to the local store.
+	cache bool
+}
+
+// NewLayeredStore creates a store using the local store as a cache in
+// front of the remote store. Pushing and pulling is disabled by default.
+func NewLayeredStore(local, remote Store, opts ...LayeredOption) Store {
+	ls := &ls{
+		local:  local,
+		remote: remote,
+		cache:  true,
+	}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(ls)
+	}
+
+	return ls
+}
+
+// NewBuildInfo writes the build info to the local store and
+// in case pushing is enabled to the remote store.
+// Failing to push is not considered an error.
+func (ls *ls) NewBuildInfo(id string, info *buildinfo.I) (err error) {
+	defer errz.Recover(&err)
+
+	err = ls.local.NewBuildInfo(id, info)
+	errz.Fatal(err)
+
+	if ls.push && !ls.remote.BuildInfoExists(id) {
+		err = ls.remote.NewBuildInfo(id, info)
+		if err != nil {
+			boblog.Log.V(1).Info(fmt.Sprintf("failed to push build info [id: %s]: %s", id, err))
+		}
+	}
+
+	return nil
+}
+
+// GetBuildInfo reads the build info from the local store. In case it
+// does not exist locally it's pulled from the remote store and cached,
+// unless caching is disabled.
+func (ls *ls) GetBuildInfo(id string) (info *buildinfo.I, err error) {
+	info, err = ls.local.GetBuildInfo(id)
+	if err == nil || !ls.pull {
+		return info, err
+	}
+
+	info, err = ls.remote.GetBuildInfo(id)
+	if err != nil {
+		return nil, err
+	}
+	if !ls.cache {
+		return info, nil
+	}
+
+	err = ls.local.NewBuildInfo(id, info)
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// GetBuildInfos returns the build infos of the local store.
+func (ls *ls) GetBuildInfos() ([]*buildinfo.I, error) {
+	return ls.local.GetBuildInfos()
+}
+
+// BuildInfoExists checks the local store first. On a remote hit
+// the build info is pulled, so subsequent reads are local
+// if caching is enabled.
+func (ls *ls) BuildInfoExists(id string) bool {
+	if ls.local.BuildInfoExists(id) {
+		return true
+	}
+	if !ls.pull {
+		return false
+	}
+
+	_, err := ls.GetBuildInfo(id)
+	return err == nil
+}
+
+// Clean cleans the local store only.
+func (ls *ls) Clean() error {
+	return ls.local.Clean()
+}
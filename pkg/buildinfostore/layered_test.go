@@ -0,0 +1,80 @@
+package buildinfostore
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/benchkram/bob/bobtask/buildinfo"
+	"github.com/benchkram/bob/pkg/store/filestore"
+)
+
+func newTestStores(t *testing.T) (local, remote Store, cleanup func()) {
+	localDir, err := os.MkdirTemp("", "test-layered-local")
+	assert.Nil(t, err)
+	remoteDir, err := os.MkdirTemp("", "test-layered-remote")
+	assert.Nil(t, err)
+
+	return NewProtoStore(localDir), NewRemoteStore(filestore.New(remoteDir)), func() {
+		os.RemoveAll(localDir)
+		os.RemoveAll(remoteDir)
+	}
+}
+
+func newTestBuildInfo(task string) *buildinfo.I {
+	bi := buildinfo.New()
+	bi.Meta.Task = task
+	return bi
+}
+
+func TestLayeredStorePull(t *testing.T) {
+	local, remote, cleanup := newTestStores(t)
+	defer cleanup()
+
+	assert.Nil(t, remote.NewBuildInfo("aaa", newTestBuildInfo("build")))
+
+	// pulling disabled
+	s := NewLayeredStore(local, remote)
+	assert.False(t, s.BuildInfoExists("aaa"))
+	_, err := s.GetBuildInfo("aaa")
+	assert.ErrorIs(t, err, ErrBuildInfoDoesNotExist)
+
+	s = NewLayeredStore(local, remote, WithPullEnabled(true))
+	assert.True(t, s.BuildInfoExists("aaa"))
+	assert.False(t, s.BuildInfoExists("bbb"))
+
+	// pulled build infos are cached locally
+	assert.True(t, local.BuildInfoExists("aaa"))
+	bi, err := local.GetBuildInfo("aaa")
+	assert.Nil(t, err)
+	assert.Equal(t, "build", bi.Meta.Task)
+
+	// read through without caching
+	assert.Nil(t, remote.NewBuildInfo("ccc", newTestBuildInfo("test")))
+	s = NewLayeredStore(local, remote, WithPullEnabled(true), WithCacheEnabled(false))
+	assert.True(t, s.BuildInfoExists("ccc"))
+	bi, err = s.GetBuildInfo("ccc")
+	assert.Nil(t, err)
+	assert.Equal(t, "test", bi.Meta.Task)
+	assert.False(t, local.BuildInfoExists("ccc"))
+}
+
+func TestLayeredStorePush(t *testing.T) {
+	local, remote, cleanup := newTestStores(t)
+	defer cleanup()
+
+	// pushing disabled
+	s := NewLayeredStore(local, remote)
+	assert.Nil(t, s.NewBuildInfo("aaa", newTestBuildInfo("build")))
+	assert.True(t, local.BuildInfoExists("aaa"))
+	assert.False(t, remote.BuildInfoExists("aaa"))
+
+	s = NewLayeredStore(local, remote, WithPushEnabled(true))
+	assert.Nil(t, s.NewBuildInfo("bbb", newTestBuildInfo("build")))
+	assert.True(t, local.BuildInfoExists("bbb"))
+
+	bi, err := remote.GetBuildInfo("bbb")
+	assert.Nil(t, err)
+	assert.Equal(t, "build", bi.Meta.Task)
+}
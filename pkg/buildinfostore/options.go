@@ -0,0 +1,33 @@
+package buildinfostore
+
+type LayeredOption func(ls *ls)
+
+func WithPushEnabled(enabled bool) LayeredOption {
+	return func(ls *ls) {
+		ls.push = enabled
+	}
+}
+
+func WithPullEnabled(enabled bool) LayeredOption {
+	return func(ls *ls) {
+		ls.pull = enabled
+	}
+}
+
+// WithCacheEnabled controls if build infos pulled from the remote
+// store are written to the local store. Enabled by default.
+func WithCacheEnabled(enabled bool) LayeredOption {
+	return func(ls *ls) {
+		ls.cache = enabled
+	}
+}
+
+type RemoteOption func(rs *rs)
+
+// WithIDPrefix stores build infos under prefixed ids,
+// allows to share the remote store with artifacts.
+func WithIDPrefix(prefix string) RemoteOption {
+	return func(rs *rs) {
+		rs.prefix = prefix
+	}
+}
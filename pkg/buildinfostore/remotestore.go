@@ -0,0 +1,137 @@
+package buildinfostore
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/benchkram/errz"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/benchkram/bob/bobtask/buildinfo"
+	"github.com/benchkram/bob/bobtask/buildinfo/protos"
+	"github.com/benchkram/bob/pkg/store"
+)
+
+type rs struct {
+	// remote stores build infos in protobuf format.
+	// The store must not be shared with artifacts,
+	// as both are indexed by the input hash, unless
+	// a prefix is used.
+	remote store.Store
+
+	// prefix is prepended to the ids in the remote store.
+	prefix string
+}
+
+// NewRemoteStore creates a build info store on top of a remote store.
+func NewRemoteStore(remote store.Store, opts ...RemoteOption) Store {
+	rs := &rs{remote: remote}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(rs)
+	}
+
+	return rs
+}
+
+func (rs *rs) key(id string) string {
+	return rs.prefix + id
+}
+
+// NewBuildInfo uploads a build info.
+func (rs *rs) NewBuildInfo(id string, info *buildinfo.I) (err error) {
+	defer errz.Recover(&err)
+
+	b, err := proto.Marshal(info.ToProto(id))
+	errz.Fatal(err)
+
+	w, err := rs.remote.NewArtifact(context.TODO(), rs.key(id), int64(len(b)))
+	errz.Fatal(err)
+
+	_, err = io.Copy(w, bytes.NewReader(b))
+	if err != nil {
+		_ = w.Close()
+		errz.Fatal(err)
+	}
+	err = w.Close()
+	errz.Fatal(err)
+
+	return rs.remote.Done()
+}
+
+// GetBuildInfo downloads a build info.
+func (rs *rs) GetBuildInfo(id string) (info *buildinfo.I, err error) {
+	defer errz.Recover(&err)
+
+	if !rs.remote.ArtifactExists(context.TODO(), rs.key(id)) {
+		return nil, ErrBuildInfoDoesNotExist
+	}
+
+	rc, _, err := rs.remote.GetArtifact(context.TODO(), rs.key(id))
+	errz.Fatal(err)
+	defer rc.Close()
+
+	b, err := io.ReadAll(rc)
+	errz.Fatal(err)
+
+	protoInfo := &protos.BuildInfo{}
+	err = proto.Unmarshal(b, protoInfo)
+	errz.Fatal(err)
+
+	if !isValid(protoInfo) {
+		return nil, ErrBuildInfoInvalid
+	}
+
+	return buildinfo.FromProto(protoInfo), nil
+}
+
+func (rs *rs) GetBuildInfos() (_ []*buildinfo.I, err error) {
+	defer errz.Recover(&err)
+
+	ids, err := rs.remote.List(context.TODO())
+	errz.Fatal(err)
+
+	var buildInfos []*buildinfo.I
+	for _, id := range ids {
+		if !strings.HasPrefix(id, rs.prefix) {
+			continue
+		}
+		bi, err := rs.GetBuildInfo(strings.TrimPrefix(id, rs.prefix))
+		errz.Fatal(err)
+
+		buildInfos = append(buildInfos, bi)
+	}
+
+	return buildInfos, nil
+}
+
+// Clean removes all build infos, with a prefix
+// other items of the remote store are kept.
+func (rs *rs) Clean() (err error) {
+	defer errz.Recover(&err)
+
+	if rs.prefix == "" {
+		return rs.remote.Clean(context.TODO())
+	}
+
+	ids, err := rs.remote.List(context.TODO())
+	errz.Fatal(err)
+	for _, id := range ids {
+		if !strings.HasPrefix(id, rs.prefix) {
+			continue
+		}
+		err = rs.remote.ArtifactRemove(context.TODO(), id)
+		errz.Fatal(err)
+	}
+
+	return nil
+}
+
+func (rs *rs) BuildInfoExists(id string) bool {
+	return rs.remote.ArtifactExists(context.TODO(), rs.key(id))
+}
@@ -0,0 +1,43 @@
+package buildinfostore
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/benchkram/bob/pkg/store/filestore"
+)
+
+func TestRemoteStoreIDPrefix(t *testing.T) {
+	ctx := context.Background()
+
+	// the remote is shared with an artifact of the same id
+	shared := filestore.New(t.TempDir())
+	w, err := shared.NewArtifact(ctx, "aaa", 8)
+	assert.Nil(t, err)
+	_, err = io.Copy(w, strings.NewReader("artifact"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+
+	s := NewRemoteStore(shared, WithIDPrefix("buildinfo-"))
+	assert.False(t, s.BuildInfoExists("aaa"))
+
+	assert.Nil(t, s.NewBuildInfo("aaa", newTestBuildInfo("build")))
+	assert.True(t, shared.ArtifactExists(ctx, "buildinfo-aaa"))
+
+	bi, err := s.GetBuildInfo("aaa")
+	assert.Nil(t, err)
+	assert.Equal(t, "build", bi.Meta.Task)
+
+	infos, err := s.GetBuildInfos()
+	assert.Nil(t, err)
+	assert.Len(t, infos, 1)
+
+	// cleaning keeps the artifacts
+	assert.Nil(t, s.Clean())
+	assert.False(t, s.BuildInfoExists("aaa"))
+	assert.True(t, shared.ArtifactExists(ctx, "aaa"))
+}
@@ -54,6 +54,7 @@ func ResolvePortConflicts(conflicts PortConfigs) (PortConfigs, error) {
 					Protocol:     cfg.Protocol,
 					Services:     []string{service},
 				})
+				continue
 			}
 
 			// check the next port
@@ -0,0 +1,9 @@
+package ctl
+
+// ExitNotifier is implemented by commands reporting exits which were
+// not requested through Stop(), Restart() or Shutdown(). The exit
+// error is nil when the command exited with status 0. Exited returns
+// nil if the command doesn't report exits, e.g. a wrapped compose command.
+type ExitNotifier interface {
+	Exited() <-chan error
+}
@@ -0,0 +1,16 @@
+package ctl
+
+// HealthState of a command with a healthcheck.
+type HealthState string
+
+const (
+	HealthStarting  HealthState = "starting"
+	HealthHealthy   HealthState = "healthy"
+	HealthUnhealthy HealthState = "unhealthy"
+	HealthStopped   HealthState = "stopped"
+)
+
+// HealthReporter is implemented by commands with a healthcheck.
+type HealthReporter interface {
+	Health() HealthState
+}
@@ -0,0 +1,26 @@
+package ctl
+
+// RebuildState is the progress of a rebuild.
+type RebuildState string
+
+const (
+	RebuildStarted   RebuildState = "rebuilding"
+	RebuildFailed    RebuildState = "failed"
+	RebuildRestarted RebuildState = "restarted"
+)
+
+// Rebuild reports an automatic rebuild triggered
+// by changes to the build dependencies of commands.
+type Rebuild struct {
+	// Commands restarted after a successful rebuild.
+	Commands []string
+
+	State RebuildState
+	Err   error
+}
+
+// Rebuilder is implemented by commanders rebuilding and
+// restarting commands when their build dependencies change.
+type Rebuilder interface {
+	Rebuilds() <-chan Rebuild
+}
@@ -0,0 +1,173 @@
+package dockermobyutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/defaults"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/images/archive"
+	"github.com/containerd/containerd/namespaces"
+	"github.com/containerd/containerd/platforms"
+	refdocker "github.com/containerd/containerd/reference/docker"
+)
+
+// C is a registry client for the image store of containerd.
+type C struct {
+	client     *containerd.Client
+	namespace  string
+	archiveDir string
+}
+
+// newContainerdClient connects to the containerd socket at address,
+// defaults to `CONTAINERD_ADDRESS` or `/run/containerd/containerd.sock`.
+func newContainerdClient(address string, namespace string, archiveDir string) (*C, error) {
+	if address == "" {
+		address = os.Getenv("CONTAINERD_ADDRESS")
+	}
+	if address == "" {
+		address = defaults.DefaultAddress
+	}
+
+	cli, err := containerd.New(address, containerd.WithTimeout(5*time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrConnectionFailed, err.Error())
+	}
+
+	return &C{
+		client:     cli,
+		namespace:  namespace,
+		archiveDir: archiveDir,
+	}, nil
+}
+
+func (c *C) context() context.Context {
+	return namespaces.WithNamespace(context.Background(), c.namespace)
+}
+
+// reference returns the fully qualified name containerd stores image under,
+// e.g. `docker.io/library/alpine:latest` for `alpine`.
+func (c *C) reference(image string) string {
+	ref, err := refdocker.ParseDockerRef(image)
+	if err != nil {
+		return image
+	}
+	return ref.String()
+}
+
+func (c *C) ImageExists(image string) (bool, error) {
+	_, err := c.ImageHash(image)
+	if err != nil {
+		if errors.Is(err, ErrImageNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ImageHash returns the digest of the image manifest (or index).
+func (c *C) ImageHash(image string) (string, error) {
+	img, err := c.client.ImageService().Get(c.context(), c.reference(image))
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return "", fmt.Errorf("%s, %w", image, ErrImageNotFound)
+		}
+		return "", err
+	}
+
+	return img.Target.Digest.String(), nil
+}
+
+// ImageSave exports the image for the default platform
+// to a tar archive readable by `docker load`.
+func (c *C) ImageSave(image string) (pathToArchive string, _ error) {
+	pathToArchive = archivePath(c.archiveDir, image)
+	f, err := os.Create(pathToArchive)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	err = c.client.Export(c.context(), f,
+		archive.WithImage(c.client.ImageService(), c.reference(image)),
+		archive.WithPlatform(platforms.DefaultStrict()),
+	)
+	if err != nil {
+		_ = os.Remove(pathToArchive)
+		return "", err
+	}
+
+	return pathToArchive, nil
+}
+
+// ImageRemove removes an image by name or by the digest returned from `ImageHash`,
+// the latter removes all names pointing to the image.
+func (c *C) ImageRemove(image string) error {
+	ctx := c.context()
+	is := c.client.ImageService()
+
+	err := is.Delete(ctx, c.reference(image))
+	if !errdefs.IsNotFound(err) {
+		return err
+	}
+
+	imgs, err := is.List(ctx)
+	if err != nil {
+		return err
+	}
+	var removed bool
+	for _, img := range imgs {
+		if img.Target.Digest.String() != image {
+			continue
+		}
+		err = is.Delete(ctx, img.Name)
+		if err != nil {
+			return err
+		}
+		removed = true
+	}
+	if !removed {
+		return fmt.Errorf("%s, %w", image, ErrImageNotFound)
+	}
+
+	return nil
+}
+
+// ImageLoad imports images from a tar archive
+func (c *C) ImageLoad(pathToArchive string) error {
+	f, err := os.Open(pathToArchive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = c.client.Import(c.context(), f)
+	return err
+}
+
+// ImageTag adds the target reference to the src image
+func (c *C) ImageTag(src string, target string) error {
+	ctx := c.context()
+	is := c.client.ImageService()
+
+	img, err := is.Get(ctx, c.reference(src))
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return fmt.Errorf("%s, %w", src, ErrImageNotFound)
+		}
+		return err
+	}
+
+	img.Name = c.reference(target)
+	_, err = is.Create(ctx, img)
+	if errdefs.IsAlreadyExists(err) {
+		_, err = is.Update(ctx, img, "target")
+	}
+	return err
+}
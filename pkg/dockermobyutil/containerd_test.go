@@ -0,0 +1,213 @@
+package dockermobyutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	contentapi "github.com/containerd/containerd/api/services/content/v1"
+	imagesapi "github.com/containerd/containerd/api/services/images/v1"
+	leasesapi "github.com/containerd/containerd/api/services/leases/v1"
+	"github.com/containerd/containerd/api/types"
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/content/local"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/services/content/contentserver"
+	ptypes "github.com/gogo/protobuf/types"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+// fakeImages is an in-memory image metadata store.
+type fakeImages struct {
+	imagesapi.UnimplementedImagesServer
+
+	mu     sync.Mutex
+	images map[string]imagesapi.Image
+}
+
+func (f *fakeImages) Get(_ context.Context, req *imagesapi.GetImageRequest) (*imagesapi.GetImageResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	img, ok := f.images[req.Name]
+	if !ok {
+		return nil, errdefs.ToGRPC(errdefs.ErrNotFound)
+	}
+	return &imagesapi.GetImageResponse{Image: &img}, nil
+}
+
+func (f *fakeImages) List(_ context.Context, _ *imagesapi.ListImagesRequest) (*imagesapi.ListImagesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	resp := &imagesapi.ListImagesResponse{}
+	for _, img := range f.images {
+		resp.Images = append(resp.Images, img)
+	}
+	return resp, nil
+}
+
+func (f *fakeImages) Create(_ context.Context, req *imagesapi.CreateImageRequest) (*imagesapi.CreateImageResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.images[req.Image.Name]; ok {
+		return nil, errdefs.ToGRPC(errdefs.ErrAlreadyExists)
+	}
+	f.images[req.Image.Name] = req.Image
+	return &imagesapi.CreateImageResponse{Image: req.Image}, nil
+}
+
+func (f *fakeImages) Update(_ context.Context, req *imagesapi.UpdateImageRequest) (*imagesapi.UpdateImageResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.images[req.Image.Name]; !ok {
+		return nil, errdefs.ToGRPC(errdefs.ErrNotFound)
+	}
+	f.images[req.Image.Name] = req.Image
+	return &imagesapi.UpdateImageResponse{Image: req.Image}, nil
+}
+
+func (f *fakeImages) Delete(_ context.Context, req *imagesapi.DeleteImageRequest) (*ptypes.Empty, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.images[req.Name]; !ok {
+		return nil, errdefs.ToGRPC(errdefs.ErrNotFound)
+	}
+	delete(f.images, req.Name)
+	return &ptypes.Empty{}, nil
+}
+
+// fakeLeases accepts the leases created during an import.
+type fakeLeases struct {
+	leasesapi.UnimplementedLeasesServer
+}
+
+func (fakeLeases) Create(_ context.Context, req *leasesapi.CreateRequest) (*leasesapi.CreateResponse, error) {
+	return &leasesapi.CreateResponse{Lease: &leasesapi.Lease{ID: req.ID, Labels: req.Labels}}, nil
+}
+
+func (fakeLeases) Delete(_ context.Context, _ *leasesapi.DeleteRequest) (*ptypes.Empty, error) {
+	return &ptypes.Empty{}, nil
+}
+
+// labelStore keeps content labels in memory.
+type labelStore struct {
+	mu     sync.Mutex
+	labels map[digest.Digest]map[string]string
+}
+
+func (s *labelStore) Get(d digest.Digest) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.labels[d], nil
+}
+
+func (s *labelStore) Set(d digest.Digest, labels map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.labels[d] = labels
+	return nil
+}
+
+func (s *labelStore) Update(d digest.Digest, update map[string]string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	labels := s.labels[d]
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	for k, v := range update {
+		if v == "" {
+			delete(labels, k)
+			continue
+		}
+		labels[k] = v
+	}
+	s.labels[d] = labels
+	return labels, nil
+}
+
+// newFakeContainerd serves the images, content and leases services
+// of containerd on a unix socket. The content store is file based.
+func newFakeContainerd(t *testing.T) (address string, _ content.Store, _ *fakeImages) {
+	t.Helper()
+
+	cs, err := local.NewLabeledStore(t.TempDir(), &labelStore{labels: map[digest.Digest]map[string]string{}})
+	assert.Nil(t, err)
+	images := &fakeImages{images: map[string]imagesapi.Image{}}
+
+	server := grpc.NewServer()
+	imagesapi.RegisterImagesServer(server, images)
+	leasesapi.RegisterLeasesServer(server, &fakeLeases{})
+	contentapi.RegisterContentServer(server, contentserver.New(cs))
+
+	address = filepath.Join(t.TempDir(), "containerd.sock")
+	l, err := net.Listen("unix", address)
+	assert.Nil(t, err)
+	go func() { _ = server.Serve(l) }()
+	t.Cleanup(server.Stop)
+
+	return address, cs, images
+}
+
+// writeBlob adds v as json to the content store.
+func writeBlob(t *testing.T, cs content.Store, mediaType string, v interface{}) v1.Descriptor {
+	t.Helper()
+
+	bin, err := json.Marshal(v)
+	assert.Nil(t, err)
+	desc := v1.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(bin),
+		Size:      int64(len(bin)),
+	}
+	assert.Nil(t, content.WriteBlob(context.Background(), cs, desc.Digest.String(), bytes.NewReader(bin), desc))
+	return desc
+}
+
+func TestContainerd(t *testing.T) {
+	address, cs, images := newFakeContainerd(t)
+
+	config := writeBlob(t, cs, v1.MediaTypeImageConfig, v1.Image{
+		Architecture: "amd64",
+		OS:           "linux",
+		RootFS:       v1.RootFS{Type: "layers"},
+	})
+	manifest := writeBlob(t, cs, v1.MediaTypeImageManifest, v1.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: v1.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []v1.Descriptor{},
+	})
+	images.images["docker.io/library/bob-test:latest"] = imagesapi.Image{
+		Name: "docker.io/library/bob-test:latest",
+		Target: types.Descriptor{
+			MediaType: manifest.MediaType,
+			Digest:    manifest.Digest,
+			Size_:     manifest.Size,
+		},
+	}
+
+	r, err := NewRegistryClient(WithRuntime(RuntimeContainerd), WithHost(address), WithArchiveDir(t.TempDir()))
+	assert.Nil(t, err)
+	assert.IsType(t, &C{}, r)
+
+	testRegistryClient(t, r, "bob-test:latest", manifest.Digest.String())
+
+	// removing by hash removes all names of the image
+	assert.Nil(t, r.ImageTag("bob-test", "bob-test-plus"))
+	assert.Nil(t, r.ImageRemove(manifest.Digest.String()))
+	assert.Len(t, images.images, 0)
+}
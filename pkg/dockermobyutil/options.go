@@ -0,0 +1,57 @@
+package dockermobyutil
+
+import (
+	"os"
+)
+
+type options struct {
+	runtime    Runtime
+	host       string
+	namespace  string
+	archiveDir string
+}
+
+func defaultOptions() *options {
+	namespace := os.Getenv("CONTAINERD_NAMESPACE")
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	return &options{
+		runtime:    Runtime(os.Getenv(EnvRuntime)),
+		namespace:  namespace,
+		archiveDir: os.TempDir(),
+	}
+}
+
+type Option func(o *options)
+
+// WithRuntime overrides the runtime set by `BOB_CONTAINER_RUNTIME`.
+func WithRuntime(runtime Runtime) Option {
+	return func(o *options) {
+		o.runtime = runtime
+	}
+}
+
+// WithHost sets the socket of the runtime, e.g. `unix:///run/podman/podman.sock`.
+// Auto-detection expects a Docker Engine API on host.
+func WithHost(host string) Option {
+	return func(o *options) {
+		o.host = host
+	}
+}
+
+// WithNamespace sets the containerd namespace images are stored in.
+// Defaults to `CONTAINERD_NAMESPACE` or `default`, docker uses `moby`.
+func WithNamespace(namespace string) Option {
+	return func(o *options) {
+		o.namespace = namespace
+	}
+}
+
+// WithArchiveDir sets the directory images are saved to.
+func WithArchiveDir(dir string) Option {
+	return func(o *options) {
+		o.archiveDir = dir
+	}
+}
@@ -0,0 +1,59 @@
+package dockermobyutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// P is a registry client for podman using its Docker Engine API
+// compatible socket. Podman stores images built or tagged with a
+// short name as `localhost/name`, lookups take that into account.
+type P struct {
+	*R
+}
+
+func (p *P) ImageExists(image string) (bool, error) {
+	_, err := p.ImageHash(image)
+	if err != nil {
+		if errors.Is(err, ErrImageNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (p *P) ImageHash(image string) (string, error) {
+	for _, name := range podmanNames(image) {
+		hash, err := p.R.ImageHash(name)
+		if err != nil {
+			if errors.Is(err, ErrImageNotFound) {
+				continue
+			}
+			return "", err
+		}
+		return hash, nil
+	}
+
+	return "", fmt.Errorf("%s, %w", image, ErrImageNotFound)
+}
+
+// podmanNames returns the names podman might list image under.
+func podmanNames(image string) []string {
+	names := []string{image}
+
+	domain, _, found := strings.Cut(image, "/")
+	if found && (strings.ContainsAny(domain, ".:") || domain == "localhost") {
+		return names
+	}
+
+	names = append(names, "localhost/"+image)
+	if !found {
+		names = append(names, "docker.io/library/"+image)
+	} else {
+		names = append(names, "docker.io/"+image)
+	}
+	return names
+}
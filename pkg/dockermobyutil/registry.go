@@ -11,14 +11,14 @@ import (
 	"strings"
 	"sync"
 
-	"github.com/benchkram/errz"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
 )
 
 var (
 	ErrImageNotFound    = fmt.Errorf("image not found")
-	ErrConnectionFailed = errors.New("connection to docker daemon failed")
+	ErrConnectionFailed = errors.New("connection to container runtime failed")
+	ErrUnknownRuntime   = errors.New("unknown container runtime")
 )
 
 type RegistryClient interface {
@@ -31,6 +31,7 @@ type RegistryClient interface {
 	ImageLoad(pathToArchive string) error
 }
 
+// R is a registry client for the Docker Engine API.
 type R struct {
 	client     *client.Client
 	archiveDir string
@@ -45,18 +46,64 @@ type R struct {
 	mutex *sync.Mutex
 }
 
-func NewRegistryClient() (RegistryClient, error) {
-	cli, err := client.NewClientWithOpts(
+// NewRegistryClient connects to the image store of the container runtime
+// selected by `WithRuntime`, `BOB_CONTAINER_RUNTIME` or auto-detection.
+func NewRegistryClient(opts ...Option) (RegistryClient, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(o)
+	}
+
+	runtime, err := ParseRuntime(string(o.runtime))
+	if err != nil {
+		return nil, err
+	}
+
+	switch runtime {
+	case RuntimeDocker:
+		return newMobyClient(o.host, o.archiveDir)
+	case RuntimePodman:
+		host := o.host
+		if host == "" {
+			host = podmanHost()
+		}
+		r, err := newMobyClient(host, o.archiveDir)
+		if err != nil {
+			return nil, err
+		}
+		return &P{R: r}, nil
+	case RuntimeContainerd:
+		return newContainerdClient(o.host, o.namespace, o.archiveDir)
+	}
+
+	candidates := socketCandidates()
+	if o.host != "" {
+		candidates = []candidate{{runtime: RuntimeDocker, host: o.host}}
+	}
+	return detect(candidates, o)
+}
+
+// newMobyClient connects to a Docker Engine API compatible socket,
+// host defaults to `DOCKER_HOST`.
+func newMobyClient(host string, archiveDir string) (*R, error) {
+	opts := []client.Opt{
 		client.FromEnv,
 		client.WithAPIVersionNegotiation(),
-	)
+	}
+	if host != "" {
+		opts = append(opts, client.WithHost(host))
+	}
+	cli, err := client.NewClientWithOpts(opts...)
 	if err != nil {
-		errz.Fatal(err)
+		return nil, err
 	}
 
 	r := &R{
 		client:     cli,
-		archiveDir: os.TempDir(),
+		archiveDir: archiveDir,
 	}
 
 	// Use a lock to suppress parallel image reads on zfs.
@@ -74,6 +121,20 @@ func NewRegistryClient() (RegistryClient, error) {
 	return r, nil
 }
 
+// isPodman checks if the server behind the docker api is podman.
+func (r *R) isPodman() (bool, error) {
+	version, err := r.client.ServerVersion(context.Background())
+	if err != nil {
+		return false, err
+	}
+	for _, c := range version.Components {
+		if strings.Contains(strings.ToLower(c.Name), "podman") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (r *R) ImageExists(image string) (bool, error) {
 	_, err := r.ImageHash(image)
 	if err != nil {
@@ -125,13 +186,7 @@ func (r *R) imageSaveToPath(image string, savedir string) (pathToArchive string,
 		return "", err
 	}
 
-	// rndExtension is added to the archive name. It prevents overwrite of images in tmp directory in case
-	// of a image beeing used as target in multiple tasks (which should be avoided).
-	rndExtension := randStringRunes(8)
-
-	image = strings.ReplaceAll(image, "/", "-")
-
-	pathToArchive = filepath.Join(savedir, image+"-"+rndExtension+".tar")
+	pathToArchive = archivePath(savedir, image)
 	err = os.WriteFile(pathToArchive, body, 0644)
 	if err != nil {
 		return "", err
@@ -176,11 +231,22 @@ func (r *R) ImageLoad(imgpath string) error {
 	return resp.Body.Close()
 }
 
-// ImageLoad from tar archive
+// ImageTag adds the target reference to the src image
 func (r *R) ImageTag(src string, target string) error {
 	return r.client.ImageTag(context.Background(), src, target)
 }
 
+// archivePath returns a unique path in dir to save image to.
+func archivePath(dir string, image string) string {
+	// rndExtension is added to the archive name. It prevents overwrite of images in tmp directory in case
+	// of a image beeing used as target in multiple tasks (which should be avoided).
+	rndExtension := randStringRunes(8)
+
+	image = strings.ReplaceAll(image, "/", "-")
+
+	return filepath.Join(dir, image+"-"+rndExtension+".tar")
+}
+
 // https://stackoverflow.com/questions/22892120/how-to-generate-a-random-string-of-a-fixed-length-in-go
 var letterRunes = []rune("abcdefghijklmnopqrstuvwxyz")
 
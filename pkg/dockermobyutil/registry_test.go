@@ -0,0 +1,273 @@
+package dockermobyutil
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMoby serves the parts of the Docker Engine API used by R
+// on a unix socket. With podman set it mimics podman's compat api.
+type fakeMoby struct {
+	mu     sync.Mutex
+	podman bool
+	images []types.ImageSummary
+	// versionErr lets `/version` fail
+	versionErr bool
+}
+
+var apiVersionPrefix = regexp.MustCompile(`^/v[0-9.]+`)
+
+func newFakeMoby(t *testing.T, podman bool, images ...types.ImageSummary) (host string, _ *fakeMoby) {
+	t.Helper()
+
+	f := &fakeMoby{podman: podman, images: images}
+
+	socket := filepath.Join(t.TempDir(), "api.sock")
+	l, err := net.Listen("unix", socket)
+	assert.Nil(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(f.serve))
+	server.Listener = l
+	server.Start()
+	t.Cleanup(server.Close)
+
+	return "unix://" + socket, f
+}
+
+// name returns the name an image is stored under.
+func (f *fakeMoby) name(image string) string {
+	if f.podman && !strings.Contains(image, "/") {
+		return "localhost/" + image
+	}
+	return image
+}
+
+// lookup returns the index of the image with the given name or id.
+func (f *fakeMoby) lookup(name string) int {
+	for i, image := range f.images {
+		if image.ID == name {
+			return i
+		}
+		for _, tag := range image.RepoTags {
+			if tag == name || tag == f.name(name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (f *fakeMoby) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := apiVersionPrefix.ReplaceAllString(r.URL.Path, "")
+	writeJSON := func(v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(v)
+	}
+
+	switch {
+	case path == "/_ping":
+		w.Header().Set("API-Version", "1.41")
+		_, _ = w.Write([]byte("OK"))
+	case path == "/version" && f.versionErr:
+		w.WriteHeader(http.StatusInternalServerError)
+	case path == "/version":
+		version := types.Version{APIVersion: "1.41", Components: []types.ComponentVersion{{Name: "Engine"}}}
+		if f.podman {
+			version.Components = []types.ComponentVersion{{Name: "Podman Engine"}}
+		}
+		writeJSON(version)
+	case path == "/info":
+		writeJSON(types.Info{Driver: "overlay"})
+	case path == "/images/json":
+		writeJSON(f.images)
+	case path == "/images/get":
+		i := f.lookup(r.URL.Query().Get("names"))
+		if i < 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// the archive is not a real image, loading it restores the summary.
+		writeJSON(f.images[i])
+	case path == "/images/load":
+		var image types.ImageSummary
+		if err := json.NewDecoder(r.Body).Decode(&image); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.images = append(f.images, image)
+		writeJSON(map[string]string{"stream": "Loaded image"})
+	case strings.HasSuffix(path, "/tag") && r.Method == http.MethodPost:
+		i := f.lookup(strings.TrimSuffix(strings.TrimPrefix(path, "/images/"), "/tag"))
+		if i < 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		tag := r.URL.Query().Get("repo") + ":" + r.URL.Query().Get("tag")
+		f.images[i].RepoTags = append(f.images[i].RepoTags, f.name(tag))
+		w.WriteHeader(http.StatusCreated)
+	case strings.HasPrefix(path, "/images/") && r.Method == http.MethodDelete:
+		name := strings.TrimPrefix(path, "/images/")
+		i := f.lookup(name)
+		if i < 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// untag, the image is deleted with its last tag
+		var tags []string
+		for _, tag := range f.images[i].RepoTags {
+			if tag != name && tag != f.name(name) {
+				tags = append(tags, tag)
+			}
+		}
+		f.images[i].RepoTags = tags
+		if len(tags) == 0 || f.images[i].ID == name {
+			f.images = append(f.images[:i], f.images[i+1:]...)
+		}
+		writeJSON([]types.ImageDeleteResponseItem{})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// testRegistryClient runs the operations bob uses on docker image targets.
+func testRegistryClient(t *testing.T, r RegistryClient, image string, hash string) {
+	exists, err := r.ImageExists(image)
+	assert.Nil(t, err)
+	assert.True(t, exists)
+
+	h, err := r.ImageHash(image)
+	assert.Nil(t, err)
+	assert.Equal(t, hash, h)
+
+	exists, err = r.ImageExists("not-existing:latest")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+	_, err = r.ImageHash("not-existing:latest")
+	assert.True(t, errors.Is(err, ErrImageNotFound))
+
+	assert.Nil(t, r.ImageTag(image, "bob-test-plus:latest"))
+	h, err = r.ImageHash("bob-test-plus:latest")
+	assert.Nil(t, err)
+	assert.Equal(t, hash, h)
+	assert.Nil(t, r.ImageRemove("bob-test-plus:latest"))
+
+	archive, err := r.ImageSave(image)
+	assert.Nil(t, err)
+	assert.FileExists(t, archive)
+
+	assert.Nil(t, r.ImageRemove(image))
+	exists, err = r.ImageExists(image)
+	assert.Nil(t, err)
+	assert.False(t, exists)
+
+	assert.Nil(t, r.ImageLoad(archive))
+	h, err = r.ImageHash(image)
+	assert.Nil(t, err)
+	assert.Equal(t, hash, h)
+}
+
+func TestDocker(t *testing.T) {
+	host, _ := newFakeMoby(t, false, types.ImageSummary{ID: "sha256:aaa", RepoTags: []string{"bob-test:latest"}})
+
+	r, err := NewRegistryClient(WithRuntime(RuntimeDocker), WithHost(host), WithArchiveDir(t.TempDir()))
+	assert.Nil(t, err)
+	assert.IsType(t, &R{}, r)
+
+	testRegistryClient(t, r, "bob-test:latest", "sha256:aaa")
+}
+
+func TestPodman(t *testing.T) {
+	host, _ := newFakeMoby(t, true, types.ImageSummary{ID: "sha256:bbb", RepoTags: []string{"localhost/bob-test:latest"}})
+
+	// podman is detected on the docker api socket
+	r, err := NewRegistryClient(WithRuntime(RuntimeAuto), WithHost(host), WithArchiveDir(t.TempDir()))
+	assert.Nil(t, err)
+	assert.IsType(t, &P{}, r)
+
+	testRegistryClient(t, r, "bob-test:latest", "sha256:bbb")
+}
+
+func TestDetect(t *testing.T) {
+	o := defaultOptions()
+	o.archiveDir = t.TempDir()
+
+	missing := filepath.Join(t.TempDir(), "missing.sock")
+	_, err := detect([]candidate{{runtime: RuntimeDocker, host: "unix://" + missing}}, o)
+	assert.True(t, errors.Is(err, ErrConnectionFailed))
+
+	docker, _ := newFakeMoby(t, false)
+	podman, _ := newFakeMoby(t, true)
+
+	r, err := detect([]candidate{
+		{runtime: RuntimeContainerd, host: missing},
+		{runtime: RuntimeDocker, host: podman},
+		{runtime: RuntimeDocker, host: docker},
+	}, o)
+	assert.Nil(t, err)
+	assert.IsType(t, &P{}, r)
+
+	// a leftover socket without a daemon is skipped
+	dead := filepath.Join(t.TempDir(), "docker.sock")
+	l, err := net.Listen("unix", dead)
+	assert.Nil(t, err)
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	assert.Nil(t, l.Close())
+	assert.FileExists(t, dead)
+
+	_, err = detect([]candidate{{runtime: RuntimeDocker, host: "unix://" + dead}}, o)
+	assert.True(t, errors.Is(err, ErrConnectionFailed))
+
+	// so is a server failing to report its version
+	broken, f := newFakeMoby(t, false)
+	f.versionErr = true
+
+	r, err = detect([]candidate{
+		{runtime: RuntimeDocker, host: "unix://" + dead},
+		{runtime: RuntimeDocker, host: broken},
+		{runtime: RuntimeDocker, host: podman},
+	}, o)
+	assert.Nil(t, err)
+	assert.IsType(t, &P{}, r)
+}
+
+func TestParseRuntime(t *testing.T) {
+	for in, expected := range map[string]Runtime{
+		"":            RuntimeAuto,
+		"auto":        RuntimeAuto,
+		"docker":      RuntimeDocker,
+		" Podman":     RuntimePodman,
+		"containerd ": RuntimeContainerd,
+	} {
+		r, err := ParseRuntime(in)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, r)
+	}
+
+	_, err := ParseRuntime("lxc")
+	assert.True(t, errors.Is(err, ErrUnknownRuntime))
+
+	t.Setenv(EnvRuntime, "lxc")
+	_, err = NewRegistryClient()
+	assert.True(t, errors.Is(err, ErrUnknownRuntime))
+}
+
+func TestPodmanNames(t *testing.T) {
+	assert.Equal(t, []string{"app:1.0", "localhost/app:1.0", "docker.io/library/app:1.0"}, podmanNames("app:1.0"))
+	assert.Equal(t, []string{"org/app:1.0", "localhost/org/app:1.0", "docker.io/org/app:1.0"}, podmanNames("org/app:1.0"))
+	assert.Equal(t, []string{"ghcr.io/org/app:1.0"}, podmanNames("ghcr.io/org/app:1.0"))
+	assert.Equal(t, []string{"localhost/app:1.0"}, podmanNames("localhost/app:1.0"))
+}
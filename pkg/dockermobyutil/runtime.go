@@ -0,0 +1,139 @@
+package dockermobyutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containerd/containerd/defaults"
+
+	"github.com/benchkram/bob/pkg/boblog"
+)
+
+// Runtime is the container runtime whose image store is used
+// to save, load and verify docker image targets.
+type Runtime string
+
+const (
+	// RuntimeAuto detects the runtime from the available sockets.
+	RuntimeAuto       Runtime = ""
+	RuntimeDocker     Runtime = "docker"
+	RuntimePodman     Runtime = "podman"
+	RuntimeContainerd Runtime = "containerd"
+)
+
+// EnvRuntime selects the runtime if not set by `WithRuntime`.
+const EnvRuntime = "BOB_CONTAINER_RUNTIME"
+
+func ParseRuntime(s string) (Runtime, error) {
+	switch r := Runtime(strings.ToLower(strings.TrimSpace(s))); r {
+	case RuntimeDocker, RuntimePodman, RuntimeContainerd:
+		return r, nil
+	case RuntimeAuto, "auto":
+		return RuntimeAuto, nil
+	}
+	return "", fmt.Errorf("%w [%s], use one of docker, podman, containerd or auto", ErrUnknownRuntime, s)
+}
+
+// candidate is a socket a runtime might be listening on.
+type candidate struct {
+	runtime Runtime
+	host    string
+}
+
+// socketCandidates lists the sockets probed by auto-detection
+// in order of precedence. Sockets of the Docker Engine API are
+// reported as docker, podman is detected after connecting.
+func socketCandidates() []candidate {
+	var candidates []candidate
+
+	for _, env := range []string{"DOCKER_HOST", "CONTAINER_HOST"} {
+		if host := os.Getenv(env); host != "" {
+			candidates = append(candidates, candidate{runtime: RuntimeDocker, host: host})
+		}
+	}
+	candidates = append(candidates, candidate{runtime: RuntimeDocker, host: "unix:///var/run/docker.sock"})
+	for _, path := range podmanSockets() {
+		candidates = append(candidates, candidate{runtime: RuntimeDocker, host: "unix://" + path})
+	}
+
+	if address := os.Getenv("CONTAINERD_ADDRESS"); address != "" {
+		candidates = append(candidates, candidate{runtime: RuntimeContainerd, host: address})
+	}
+	candidates = append(candidates, candidate{runtime: RuntimeContainerd, host: defaults.DefaultAddress})
+
+	return candidates
+}
+
+// podmanSockets returns the rootless and the rootful podman api socket.
+func podmanSockets() []string {
+	var sockets []string
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		sockets = append(sockets, filepath.Join(dir, "podman", "podman.sock"))
+	}
+	return append(sockets, "/run/podman/podman.sock")
+}
+
+// podmanHost returns `CONTAINER_HOST` or the first existing podman socket.
+func podmanHost() string {
+	if host := os.Getenv("CONTAINER_HOST"); host != "" {
+		return host
+	}
+	for _, path := range podmanSockets() {
+		if _, err := os.Stat(path); err == nil {
+			return "unix://" + path
+		}
+	}
+	return ""
+}
+
+// detect connects to the first reachable candidate. Candidates without
+// an existing socket or failing to connect, e.g. a leftover docker socket,
+// are skipped.
+func detect(candidates []candidate, o *options) (RegistryClient, error) {
+	connErr := ErrConnectionFailed
+	for _, c := range candidates {
+		path := strings.TrimPrefix(c.host, "unix://")
+		if !strings.Contains(path, "://") {
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+		}
+
+		r, err := connect(c, o)
+		if err != nil {
+			if errors.Is(err, ErrConnectionFailed) {
+				boblog.Log.V(2).Info(fmt.Sprintf("Skipping container runtime at %s: %s", c.host, err.Error()))
+				connErr = err
+				continue
+			}
+			return nil, err
+		}
+		return r, nil
+	}
+
+	return nil, connErr
+}
+
+// connect to the runtime of a candidate, for a docker api socket
+// podman is detected from the server version.
+func connect(c candidate, o *options) (RegistryClient, error) {
+	if c.runtime == RuntimeContainerd {
+		return newContainerdClient(c.host, o.namespace, o.archiveDir)
+	}
+
+	r, err := newMobyClient(c.host, o.archiveDir)
+	if err != nil {
+		return nil, err
+	}
+	podman, err := r.isPodman()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get server version: %s", ErrConnectionFailed, err.Error())
+	}
+	if podman {
+		return &P{R: r}, nil
+	}
+	return r, nil
+}
@@ -18,6 +18,7 @@ var (
 
 // assert Cmd implements the Command interface
 var _ ctl.Command = (*Cmd)(nil)
+var _ ctl.ExitNotifier = (*Cmd)(nil)
 
 // Cmd allows to control a process started through os.Exec with additional start, stop and restart capabilities, and
 // provides readers/writers for the command's outputs and input, respectively.
@@ -32,9 +33,11 @@ type Cmd struct {
 	stdin       pipe
 	running     bool
 	interrupted bool
-	err         chan error
-	lastErr     error
-	env         []string
+	// done is closed when the last started process exited
+	done    chan struct{}
+	exited  chan error
+	lastErr error
+	env     []string
 }
 
 type pipe struct {
@@ -45,9 +48,9 @@ type pipe struct {
 // NewCmd creates a new Cmd, ready to be started
 func NewCmd(name string, exe string, opts ...Option) (c *Cmd, err error) {
 	c = &Cmd{
-		name: name,
-		exe:  exe,
-		err:  make(chan error, 1),
+		name:   name,
+		exe:    exe,
+		exited: make(chan error, 1),
 	}
 
 	for _, opt := range opts {
@@ -116,17 +119,34 @@ func (c *Cmd) Start() error {
 	// start the command
 	err := c.cmd.Start()
 	if err != nil {
+		c.running = false
 		return usererror.Wrapm(err, "Command execution failed")
 	}
 
+	done := make(chan struct{})
+	c.done = done
+
 	go func() {
-		err = cmd.Wait()
+		defer close(done)
 
-		c.err <- err
+		err := cmd.Wait()
 
 		c.mux.Lock()
 
-		c.running = false
+		// the command might have been restarted in the meantime
+		if c.cmd == cmd {
+			c.running = false
+			if err != nil && c.interrupted && strings.Contains(err.Error(), "signal: interrupt") {
+				err = nil
+			}
+			c.lastErr = err
+			if !c.interrupted {
+				select {
+				case c.exited <- err:
+				default:
+				}
+			}
+		}
 
 		c.mux.Unlock()
 	}()
@@ -190,33 +210,18 @@ func (c *Cmd) Stdin() io.Writer {
 // exited, if any.
 func (c *Cmd) Wait() error {
 	c.mux.Lock()
-
 	running := c.running
-	errChan := c.err
-	lastErr := c.lastErr
-	interrupted := c.interrupted
-
-	if !running {
-		c.mux.Unlock()
-
-		return lastErr
-	}
-
+	done := c.done
 	c.mux.Unlock()
 
-	err := <-errChan
-
-	c.mux.Lock()
-
-	if err != nil && interrupted && strings.Contains(err.Error(), "signal: interrupt") {
-		err = nil
-	} else if err != nil {
-		c.lastErr = err
+	if running {
+		<-done
 	}
 
-	c.mux.Unlock()
+	c.mux.Lock()
+	defer c.mux.Unlock()
 
-	return err
+	return c.lastErr
 }
 
 // stop requests for the command to stop, if it has already started.
@@ -244,6 +249,11 @@ func (c *Cmd) stop() error {
 	return nil
 }
 
+// Exited emits when the command exits without being stopped.
+func (c *Cmd) Exited() <-chan error {
+	return c.exited
+}
+
 // Shutdown stops the cmd
 func (c *Cmd) Shutdown() error {
 	return c.Stop()
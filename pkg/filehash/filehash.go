@@ -32,6 +32,24 @@ func (h *H) AddFile(file string) error {
 	return err
 }
 
+// AddFileWithSum adds the file to the hash and returns
+// the hash of the file's content alone.
+func (h *H) AddFileWithSum(file string) ([]byte, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open: %w", err)
+	}
+
+	fh := hashFunc()
+	_, err = io.CopyBuffer(io.MultiWriter(h.hash, fh), f, h.buffer)
+	f.Close() // avoiding defer for performance
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy: %w", err)
+	}
+
+	return fh.Sum(nil), nil
+}
+
 func (h *H) AddBytes(r io.Reader) error {
 	if _, err := io.CopyBuffer(h.hash, r, h.buffer); err != nil {
 		return fmt.Errorf("failed to copy: %w", err)
@@ -0,0 +1,520 @@
+// Package ocilayout creates OCI image layouts from files on disk without
+// the need of a container daemon. See
+// https://github.com/opencontainers/image-spec/blob/main/image-layout.md
+package ocilayout
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	indexFile = "index.json"
+	blobsDir  = "blobs"
+)
+
+var (
+	ErrInvalidLayout   = fmt.Errorf("invalid oci layout")
+	ErrImageNotFound   = fmt.Errorf("image not found in oci layout")
+	ErrInvalidFileMap  = fmt.Errorf("invalid file")
+	ErrInvalidPlatform = fmt.Errorf("invalid platform")
+)
+
+// Config of an image created by Create.
+type Config struct {
+	// Base is a directory containing an OCI image layout (optional).
+	// An image of the layout is selected by appending `:ref`,
+	// matching the `org.opencontainers.image.ref.name` annotation.
+	// Defaults to the first image of the layout.
+	Base string
+
+	Entrypoint []string
+	Cmd        []string
+	// Env in the form "key=value", added to the env of the base image.
+	Env        []string
+	WorkingDir string
+
+	// Tag is stored as `org.opencontainers.image.ref.name` annotation.
+	Tag string
+
+	// Platform in the form `os/arch[/variant]`, e.g. `linux/arm64`.
+	// Defaults to the platform of the base image, selected from a multi
+	// platform base image by DefaultPlatform(). Images without a base
+	// image default to DefaultPlatform().
+	Platform string
+}
+
+// DefaultPlatform of images, linux on the architecture of the host.
+func DefaultPlatform() string {
+	return "linux/" + runtime.GOARCH
+}
+
+// ParsePlatform parses a platform in the form `os/arch[/variant]`.
+func ParsePlatform(s string) (*v1.Platform, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("%w `%s`, expected `os/arch[/variant]`", ErrInvalidPlatform, s)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("%w `%s`, expected `os/arch[/variant]`", ErrInvalidPlatform, s)
+		}
+	}
+
+	platform := &v1.Platform{OS: parts[0], Architecture: parts[1]}
+	if len(parts) == 3 {
+		platform.Variant = parts[2]
+	}
+	return platform, nil
+}
+
+// Create writes an OCI image layout to dst. The image consists of the
+// layers of the base image and one layer containing files, which maps
+// paths inside the image to paths on the filesystem.
+//
+// The result is reproducible, timestamps and owners are not preserved.
+func Create(dst string, files map[string]string, config Config) (err error) {
+	err = os.MkdirAll(filepath.Join(dst, blobsDir, string(digest.Canonical)), 0755)
+	if err != nil {
+		return err
+	}
+
+	var platform *v1.Platform
+	if config.Platform != "" {
+		platform, err = ParsePlatform(config.Platform)
+		if err != nil {
+			return err
+		}
+	}
+
+	image := v1.Image{
+		RootFS: v1.RootFS{Type: "layers"},
+	}
+	var layers []v1.Descriptor
+
+	if config.Base != "" {
+		base, manifest, variant, err := readBase(config.Base, platform)
+		if err != nil {
+			return err
+		}
+
+		bin, err := readBlob(base, manifest.Config)
+		if err != nil {
+			return err
+		}
+		err = json.Unmarshal(bin, &image)
+		if err != nil {
+			return fmt.Errorf("%w, failed to read config of %s: %s", ErrInvalidLayout, config.Base, err.Error())
+		}
+
+		basePlatform := &v1.Platform{OS: image.OS, Architecture: image.Architecture, Variant: variant}
+		if platform != nil && !matchPlatform(basePlatform, platform) {
+			return fmt.Errorf("%w, base image %s is %s, not %s", ErrInvalidPlatform, config.Base, formatPlatform(basePlatform), config.Platform)
+		}
+		platform = basePlatform
+
+		for _, layer := range manifest.Layers {
+			err = copyBlob(base, dst, layer)
+			if err != nil {
+				return err
+			}
+		}
+		layers = append(layers, manifest.Layers...)
+	} else if platform == nil {
+		platform, err = ParsePlatform(DefaultPlatform())
+		if err != nil {
+			return err
+		}
+	}
+	image.OS = platform.OS
+	image.Architecture = platform.Architecture
+
+	layer, diffID, err := writeLayer(dst, files)
+	if err != nil {
+		return err
+	}
+	layers = append(layers, layer)
+	image.RootFS.DiffIDs = append(image.RootFS.DiffIDs, diffID)
+	image.History = append(image.History, v1.History{CreatedBy: "bob"})
+
+	if len(config.Entrypoint) > 0 {
+		image.Config.Entrypoint = config.Entrypoint
+		// a cmd of the base image is meant for its entrypoint
+		image.Config.Cmd = nil
+	}
+	if len(config.Cmd) > 0 {
+		image.Config.Cmd = config.Cmd
+	}
+	if config.WorkingDir != "" {
+		image.Config.WorkingDir = config.WorkingDir
+	}
+	image.Config.Env = mergeEnv(image.Config.Env, config.Env)
+
+	configDescriptor, err := writeJSON(dst, v1.MediaTypeImageConfig, image)
+	if err != nil {
+		return err
+	}
+
+	manifestDescriptor, err := writeJSON(dst, v1.MediaTypeImageManifest, v1.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		Config:    configDescriptor,
+		Layers:    layers,
+	})
+	if err != nil {
+		return err
+	}
+	manifestDescriptor.Platform = &v1.Platform{
+		Architecture: platform.Architecture,
+		OS:           platform.OS,
+		Variant:      platform.Variant,
+	}
+	if config.Tag != "" {
+		manifestDescriptor.Annotations = map[string]string{v1.AnnotationRefName: config.Tag}
+	}
+
+	index := v1.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		Manifests: []v1.Descriptor{manifestDescriptor},
+	}
+	bin, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(dst, indexFile), bin, 0644)
+	if err != nil {
+		return err
+	}
+
+	bin, err = json.Marshal(v1.ImageLayout{Version: v1.ImageLayoutVersion})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dst, v1.ImageLayoutFile), bin, 0644)
+}
+
+// readBase returns the directory of the base layout, the manifest of the
+// selected image and its platform variant. Images of a multi platform base
+// image are selected by platform, by DefaultPlatform() if it's nil.
+func readBase(base string, platform *v1.Platform) (dir string, _ *v1.Manifest, variant string, _ error) {
+	dir, ref := base, ""
+	if i := strings.LastIndex(base, ":"); i > strings.LastIndex(base, "/") {
+		dir, ref = base[:i], base[i+1:]
+	}
+
+	bin, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if err != nil {
+		return "", nil, "", fmt.Errorf("%w, failed to read %s: %s", ErrInvalidLayout, dir, err.Error())
+	}
+	var index v1.Index
+	err = json.Unmarshal(bin, &index)
+	if err != nil {
+		return "", nil, "", fmt.Errorf("%w, failed to read %s: %s", ErrInvalidLayout, dir, err.Error())
+	}
+
+	var descriptor *v1.Descriptor
+	for i, d := range index.Manifests {
+		if ref == "" || d.Annotations[v1.AnnotationRefName] == ref {
+			descriptor = &index.Manifests[i]
+			break
+		}
+	}
+	if descriptor == nil {
+		return "", nil, "", fmt.Errorf("%w, [%s]", ErrImageNotFound, base)
+	}
+
+	// multi platform images reference an index of manifests
+	if descriptor.MediaType == v1.MediaTypeImageIndex {
+		bin, err := readBlob(dir, *descriptor)
+		if err != nil {
+			return "", nil, "", err
+		}
+		var nested v1.Index
+		err = json.Unmarshal(bin, &nested)
+		if err != nil || len(nested.Manifests) == 0 {
+			return "", nil, "", fmt.Errorf("%w, invalid index of %s", ErrInvalidLayout, base)
+		}
+		want := platform
+		if want == nil {
+			want, _ = ParsePlatform(DefaultPlatform())
+			descriptor = &nested.Manifests[0]
+		} else {
+			descriptor = nil
+		}
+		for i, d := range nested.Manifests {
+			if d.Platform != nil && matchPlatform(d.Platform, want) {
+				descriptor = &nested.Manifests[i]
+				break
+			}
+		}
+		if descriptor == nil {
+			return "", nil, "", fmt.Errorf("%w, [%s] for platform %s", ErrImageNotFound, base, formatPlatform(want))
+		}
+	}
+	if descriptor.Platform != nil {
+		variant = descriptor.Platform.Variant
+	}
+
+	bin, err = readBlob(dir, *descriptor)
+	if err != nil {
+		return "", nil, "", err
+	}
+	var manifest v1.Manifest
+	err = json.Unmarshal(bin, &manifest)
+	if err != nil {
+		return "", nil, "", fmt.Errorf("%w, invalid manifest in %s: %s", ErrInvalidLayout, base, err.Error())
+	}
+
+	return dir, &manifest, variant, nil
+}
+
+// matchPlatform returns true if p is the wanted platform,
+// the variant is only compared if it's wanted.
+func matchPlatform(p, want *v1.Platform) bool {
+	return p.OS == want.OS && p.Architecture == want.Architecture &&
+		(want.Variant == "" || p.Variant == want.Variant)
+}
+
+func formatPlatform(p *v1.Platform) string {
+	if p.Variant != "" {
+		return p.OS + "/" + p.Architecture + "/" + p.Variant
+	}
+	return p.OS + "/" + p.Architecture
+}
+
+func blobPath(dir string, d digest.Digest) string {
+	return filepath.Join(dir, blobsDir, d.Algorithm().String(), d.Encoded())
+}
+
+func readBlob(dir string, d v1.Descriptor) ([]byte, error) {
+	if err := d.Digest.Validate(); err != nil {
+		return nil, fmt.Errorf("%w, invalid digest %q", ErrInvalidLayout, d.Digest)
+	}
+	bin, err := os.ReadFile(blobPath(dir, d.Digest))
+	if err != nil {
+		return nil, fmt.Errorf("%w, missing blob: %s", ErrInvalidLayout, err.Error())
+	}
+	if d.Digest.Algorithm().FromBytes(bin) != d.Digest {
+		return nil, fmt.Errorf("%w, digest mismatch of blob %s", ErrInvalidLayout, d.Digest)
+	}
+	return bin, nil
+}
+
+func copyBlob(src, dst string, d v1.Descriptor) error {
+	if err := d.Digest.Validate(); err != nil {
+		return fmt.Errorf("%w, invalid digest %q", ErrInvalidLayout, d.Digest)
+	}
+
+	in, err := os.Open(blobPath(src, d.Digest))
+	if err != nil {
+		return fmt.Errorf("%w, missing blob: %s", ErrInvalidLayout, err.Error())
+	}
+	defer in.Close()
+
+	path := blobPath(dst, d.Digest)
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func writeJSON(dir string, mediaType string, v interface{}) (v1.Descriptor, error) {
+	bin, err := json.Marshal(v)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	d := digest.FromBytes(bin)
+	err = os.WriteFile(blobPath(dir, d), bin, 0644)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	return v1.Descriptor{MediaType: mediaType, Digest: d, Size: int64(len(bin))}, nil
+}
+
+// writeLayer writes files as gzip compressed tar layer. It returns the
+// descriptor of the layer and the digest of the uncompressed tar.
+func writeLayer(dir string, files map[string]string) (_ v1.Descriptor, diffID digest.Digest, err error) {
+	tmp, err := os.CreateTemp(filepath.Join(dir, blobsDir), "layer-")
+	if err != nil {
+		return v1.Descriptor{}, "", err
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	compressed := digest.Canonical.Digester()
+	uncompressed := digest.Canonical.Digester()
+	counter := &countWriter{}
+
+	gz := gzip.NewWriter(io.MultiWriter(tmp, compressed.Hash(), counter))
+	tw := tar.NewWriter(io.MultiWriter(gz, uncompressed.Hash()))
+
+	err = writeFiles(tw, files)
+	if err != nil {
+		return v1.Descriptor{}, "", err
+	}
+	if err = tw.Close(); err != nil {
+		return v1.Descriptor{}, "", err
+	}
+	if err = gz.Close(); err != nil {
+		return v1.Descriptor{}, "", err
+	}
+	if err = tmp.Close(); err != nil {
+		return v1.Descriptor{}, "", err
+	}
+
+	d := compressed.Digest()
+	err = os.Rename(tmp.Name(), blobPath(dir, d))
+	if err != nil {
+		return v1.Descriptor{}, "", err
+	}
+
+	return v1.Descriptor{
+		MediaType: v1.MediaTypeImageLayerGzip,
+		Digest:    d,
+		Size:      counter.n,
+	}, uncompressed.Digest(), nil
+}
+
+// writeFiles adds files and their parent directories in a stable order.
+func writeFiles(tw *tar.Writer, files map[string]string) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		clean := strings.TrimPrefix(filepath.ToSlash(filepath.Clean("/"+name)), "/")
+		if clean == "" {
+			return fmt.Errorf("%w, [%s] is not a file", ErrInvalidFileMap, name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	dirs := map[string]bool{}
+	for _, name := range names {
+		path := strings.TrimPrefix(filepath.ToSlash(filepath.Clean("/"+name)), "/")
+
+		// parent directories
+		var parents []string
+		for p := filepath.Dir(path); p != "." && p != "/" && !dirs[p]; p = filepath.Dir(p) {
+			parents = append([]string{p}, parents...)
+		}
+		for _, p := range parents {
+			dirs[p] = true
+			err := tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeDir,
+				Name:     p + "/",
+				Mode:     0755,
+				ModTime:  time.Unix(0, 0),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err := writeFile(tw, path, files[name])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeFile(tw *tar.Writer, name, src string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	var link string
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		link, err = os.Readlink(src)
+		if err != nil {
+			return err
+		}
+	case info.Mode().IsRegular():
+	default:
+		return fmt.Errorf("%w, [%s] is not a regular file or symlink", ErrInvalidFileMap, src)
+	}
+
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.ModTime = time.Unix(0, 0)
+	header.AccessTime = time.Time{}
+	header.ChangeTime = time.Time{}
+	header.Uid, header.Gid = 0, 0
+	header.Uname, header.Gname = "", ""
+
+	err = tw.WriteHeader(header)
+	if err != nil {
+		return err
+	}
+	if link != "" {
+		return nil
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// mergeEnv adds env to base, variables existing in base are replaced.
+func mergeEnv(base, env []string) []string {
+	merged := append([]string{}, base...)
+	for _, kv := range env {
+		key, _, _ := strings.Cut(kv, "=")
+
+		replaced := false
+		for i, existing := range merged {
+			if k, _, _ := strings.Cut(existing, "="); k == key {
+				merged[i] = kv
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, kv)
+		}
+	}
+	return merged
+}
+
+type countWriter struct {
+	n int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
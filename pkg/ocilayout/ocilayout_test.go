@@ -0,0 +1,192 @@
+package ocilayout
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func readJSON(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	bin, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(bin, v))
+}
+
+// readImage returns the manifest descriptor, manifest and config of the only image in dir.
+func readImage(t *testing.T, dir string) (v1.Descriptor, v1.Manifest, v1.Image) {
+	t.Helper()
+
+	var index v1.Index
+	readJSON(t, filepath.Join(dir, "index.json"), &index)
+	assert.Len(t, index.Manifests, 1)
+	descriptor := index.Manifests[0]
+
+	var manifest v1.Manifest
+	readJSON(t, blobPath(dir, descriptor.Digest), &manifest)
+
+	var image v1.Image
+	readJSON(t, blobPath(dir, manifest.Config.Digest), &image)
+
+	return descriptor, manifest, image
+}
+
+func layerFiles(t *testing.T, dir string, layer v1.Descriptor) map[string]string {
+	t.Helper()
+
+	f, err := os.Open(blobPath(dir, layer.Digest))
+	assert.Nil(t, err)
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	assert.Nil(t, err)
+
+	files := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		assert.Nil(t, err)
+		content, err := io.ReadAll(tr)
+		assert.Nil(t, err)
+		files[header.Name] = string(content)
+	}
+	return files
+}
+
+func TestCreate(t *testing.T) {
+	src := t.TempDir()
+	server := filepath.Join(src, "server")
+	assert.Nil(t, os.WriteFile(server, []byte("binary"), 0755))
+	assets := filepath.Join(src, "index.html")
+	assert.Nil(t, os.WriteFile(assets, []byte("<html>"), 0644))
+
+	// base image
+	base := t.TempDir()
+	err := Create(base, map[string]string{"etc/os-release": assets}, Config{
+		Env: []string{"PATH=/bin", "LANG=C"},
+		Cmd: []string{"sh"},
+		Tag: "3.19",
+	})
+	assert.Nil(t, err)
+
+	dst := t.TempDir()
+	config := Config{
+		Base:       base + ":3.19",
+		Entrypoint: []string{"/bin/server"},
+		Env:        []string{"PORT=8080", "LANG=en_US.UTF-8"},
+		Tag:        "server:latest",
+	}
+	err = Create(dst, map[string]string{
+		"bin/server":            server,
+		"srv/static/index.html": assets,
+	}, config)
+	assert.Nil(t, err)
+
+	var layout v1.ImageLayout
+	readJSON(t, filepath.Join(dst, v1.ImageLayoutFile), &layout)
+	assert.Equal(t, v1.ImageLayoutVersion, layout.Version)
+
+	descriptor, manifest, image := readImage(t, dst)
+	assert.Equal(t, "server:latest", descriptor.Annotations[v1.AnnotationRefName])
+	assert.Len(t, manifest.Layers, 2)
+	assert.Len(t, image.RootFS.DiffIDs, 2)
+	assert.Equal(t, []string{"/bin/server"}, image.Config.Entrypoint)
+	assert.Nil(t, image.Config.Cmd, "cmd of the base image must be dropped with a new entrypoint")
+	assert.Equal(t, []string{"PATH=/bin", "LANG=en_US.UTF-8", "PORT=8080"}, image.Config.Env)
+
+	assert.Equal(t, map[string]string{"etc/": "", "etc/os-release": "<html>"}, layerFiles(t, dst, manifest.Layers[0]))
+	assert.Equal(t, map[string]string{
+		"bin/":                  "",
+		"bin/server":            "binary",
+		"srv/":                  "",
+		"srv/static/":           "",
+		"srv/static/index.html": "<html>",
+	}, layerFiles(t, dst, manifest.Layers[1]))
+
+	// reproducible
+	again := t.TempDir()
+	err = Create(again, map[string]string{
+		"bin/server":            server,
+		"srv/static/index.html": assets,
+	}, config)
+	assert.Nil(t, err)
+	againDescriptor, _, _ := readImage(t, again)
+	assert.Equal(t, descriptor.Digest, againDescriptor.Digest)
+}
+
+func TestCreateMissingBase(t *testing.T) {
+	err := Create(t.TempDir(), map[string]string{}, Config{Base: filepath.Join(t.TempDir(), "missing")})
+	assert.True(t, errors.Is(err, ErrInvalidLayout))
+
+	base := t.TempDir()
+	assert.Nil(t, Create(base, map[string]string{}, Config{Tag: "1.0"}))
+	err = Create(t.TempDir(), map[string]string{}, Config{Base: base + ":2.0"})
+	assert.True(t, errors.Is(err, ErrImageNotFound))
+}
+
+func TestCreatePlatform(t *testing.T) {
+	// without a base image
+	dst := t.TempDir()
+	assert.Nil(t, Create(dst, map[string]string{}, Config{Platform: "linux/arm/v7"}))
+	descriptor, _, image := readImage(t, dst)
+	assert.Equal(t, "linux", image.OS)
+	assert.Equal(t, "arm", image.Architecture)
+	assert.Equal(t, &v1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}, descriptor.Platform)
+
+	dst = t.TempDir()
+	assert.Nil(t, Create(dst, map[string]string{}, Config{}))
+	descriptor, _, _ = readImage(t, dst)
+	assert.Equal(t, DefaultPlatform(), formatPlatform(descriptor.Platform))
+
+	// defaults to the platform of the base image
+	base := t.TempDir()
+	assert.Nil(t, Create(base, map[string]string{}, Config{Platform: "linux/s390x"}))
+	dst = t.TempDir()
+	assert.Nil(t, Create(dst, map[string]string{}, Config{Base: base}))
+	descriptor, _, image = readImage(t, dst)
+	assert.Equal(t, "s390x", image.Architecture)
+	assert.Equal(t, "linux/s390x", formatPlatform(descriptor.Platform))
+
+	err := Create(t.TempDir(), map[string]string{}, Config{Base: base, Platform: "linux/amd64"})
+	assert.True(t, errors.Is(err, ErrInvalidPlatform))
+
+	// selected from a multi platform base image
+	multi := t.TempDir()
+	var manifests []v1.Descriptor
+	for _, platform := range []string{"linux/s390x", "linux/ppc64le"} {
+		image := t.TempDir()
+		assert.Nil(t, Create(image, map[string]string{}, Config{Platform: platform}))
+		descriptor, manifest, _ := readImage(t, image)
+		for _, blob := range append([]v1.Descriptor{descriptor, manifest.Config}, manifest.Layers...) {
+			assert.Nil(t, copyBlob(image, multi, blob))
+		}
+		manifests = append(manifests, descriptor)
+	}
+	nested, err := writeJSON(multi, v1.MediaTypeImageIndex, v1.Index{Manifests: manifests})
+	assert.Nil(t, err)
+	bin, err := json.Marshal(v1.Index{Manifests: []v1.Descriptor{nested}})
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(filepath.Join(multi, indexFile), bin, 0644))
+
+	dst = t.TempDir()
+	assert.Nil(t, Create(dst, map[string]string{}, Config{Base: multi, Platform: "linux/ppc64le"}))
+	_, _, image = readImage(t, dst)
+	assert.Equal(t, "ppc64le", image.Architecture)
+
+	err = Create(t.TempDir(), map[string]string{}, Config{Base: multi, Platform: "linux/riscv64"})
+	assert.True(t, errors.Is(err, ErrImageNotFound))
+
+	err = Create(t.TempDir(), map[string]string{}, Config{Platform: "linux"})
+	assert.True(t, errors.Is(err, ErrInvalidPlatform))
+}
@@ -0,0 +1,70 @@
+// Package proctrace records the files opened by a command and all
+// processes it spawns. On linux (amd64, arm64) the command is traced
+// with ptrace and every open and exec is recorded, including files
+// which don't exist. Other platforms run the command untraced.
+package proctrace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Tracer records files opened by traced commands.
+type Tracer struct {
+	mu      sync.Mutex
+	read    map[string]struct{}
+	written map[string]struct{}
+}
+
+func New() *Tracer {
+	return &Tracer{
+		read:    map[string]struct{}{},
+		written: map[string]struct{}{},
+	}
+}
+
+// Files returns the absolute paths opened by all commands traced so far.
+// Files opened for writing are not listed as read.
+func (t *Tracer) Files() (read, written []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for path := range t.read {
+		if _, ok := t.written[path]; ok {
+			continue
+		}
+		read = append(read, path)
+	}
+	for path := range t.written {
+		written = append(written, path)
+	}
+	return read, written
+}
+
+func (t *Tracer) add(path string, write bool) {
+	path = filepath.Clean(path)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if write {
+		t.written[path] = struct{}{}
+	} else {
+		t.read[path] = struct{}{}
+	}
+}
+
+// ExitError reports a traced command which didn't exit successfully.
+type ExitError struct {
+	// Code is the exit code, -1 if the command was killed by a signal.
+	Code int
+	// Signal which killed the command, nil if it exited.
+	Signal os.Signal
+}
+
+func (e *ExitError) Error() string {
+	if e.Signal != nil {
+		return fmt.Sprintf("signal: %v", e.Signal)
+	}
+	return fmt.Sprintf("exit status %d", e.Code)
+}
@@ -0,0 +1,43 @@
+package proctrace
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracer(t *testing.T) {
+	if !Supported() {
+		t.Skip("tracing not supported")
+	}
+
+	dir, err := os.MkdirTemp("", "test-proctrace")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	dir, err = filepath.EvalSymlinks(dir)
+	assert.Nil(t, err)
+
+	in := filepath.Join(dir, "in")
+	out := filepath.Join(dir, "out")
+	missing := filepath.Join(dir, "missing")
+	assert.Nil(t, os.WriteFile(in, []byte("in"), 0664))
+
+	// files are only opened briefly by child processes
+	cmd := exec.Command("sh", "-c", "cat in > out; cat missing 2>/dev/null; exit 3")
+	cmd.Dir = dir
+
+	tracer := New()
+	p, err := tracer.Start(cmd)
+	assert.Nil(t, err)
+	err = p.Wait()
+	assert.Equal(t, &ExitError{Code: 3}, err)
+
+	read, written := tracer.Files()
+	assert.Contains(t, read, in)
+	assert.Contains(t, read, missing)
+	assert.Contains(t, written, out)
+	assert.NotContains(t, read, out)
+}
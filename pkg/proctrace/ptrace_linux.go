@@ -0,0 +1,252 @@
+//go:build linux && (amd64 || arm64)
+
+package proctrace
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"syscall"
+)
+
+const (
+	// wait only for children of the calling thread, which is the tracer.
+	wNoThread = 0x20000000
+
+	ptraceOptions = syscall.PTRACE_O_TRACESYSGOOD |
+		syscall.PTRACE_O_TRACEFORK |
+		syscall.PTRACE_O_TRACEVFORK |
+		syscall.PTRACE_O_TRACECLONE |
+		syscall.PTRACE_O_TRACEEXEC
+
+	atFdCwd = -100
+	pathMax = 4096
+)
+
+// Supported returns true if commands are traced on this platform.
+func Supported() bool {
+	return true
+}
+
+// Process is a traced command started by Start().
+type Process struct {
+	cmd *exec.Cmd
+
+	done   chan struct{}
+	status syscall.WaitStatus
+	err    error
+}
+
+// Start starts the command and traces it together with all the processes
+// it spawns. Use Wait() of the returned process instead of cmd.Wait().
+func (t *Tracer) Start(cmd *exec.Cmd) (*Process, error) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Ptrace = true
+
+	p := &Process{
+		cmd:  cmd,
+		done: make(chan struct{}),
+	}
+
+	started := make(chan error)
+	go func() {
+		// All ptrace requests must be made by the thread which started the
+		// command. The thread is never unlocked and exits with the goroutine,
+		// which detaches tracees still running, e.g. daemons.
+		runtime.LockOSThread()
+		defer close(p.done)
+
+		err := cmd.Start()
+		started <- err
+		if err != nil {
+			return
+		}
+		p.status, p.err = t.trace(cmd.Process.Pid)
+	}()
+
+	if err := <-started; err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Signal sends a signal to the command unless it already exited.
+func (p *Process) Signal(sig os.Signal) error {
+	select {
+	case <-p.done:
+		return os.ErrProcessDone
+	default:
+		return p.cmd.Process.Signal(sig)
+	}
+}
+
+// Wait waits for the command to exit, an unsuccessful exit is
+// reported as *ExitError.
+func (p *Process) Wait() error {
+	<-p.done
+
+	// The command was already reaped by the tracer, waiting only
+	// releases its resources and copies the remaining output.
+	err := p.cmd.Wait()
+	if err != nil && !errors.Is(err, syscall.ECHILD) {
+		return err
+	}
+
+	if p.err != nil {
+		return p.err
+	}
+	switch {
+	case p.status.Signaled():
+		return &ExitError{Code: -1, Signal: p.status.Signal()}
+	case p.status.ExitStatus() != 0:
+		return &ExitError{Code: p.status.ExitStatus()}
+	}
+	return nil
+}
+
+// trace resumes the stopped root process and records the files opened
+// by it and its descendants until it exits.
+func (t *Tracer) trace(root int) (status syscall.WaitStatus, err error) {
+	// the root process stops after exec
+	if _, err := wait4(root, &status); err != nil {
+		return status, err
+	}
+	if !status.Stopped() {
+		return status, nil
+	}
+	if err := syscall.PtraceSetOptions(root, ptraceOptions); err != nil {
+		return status, err
+	}
+	if err := syscall.PtraceSyscall(root, 0); err != nil {
+		return status, err
+	}
+
+	known := map[int]bool{root: true}
+	inSyscall := map[int]bool{}
+	for {
+		pid, err := wait4(-1, &status)
+		if err != nil {
+			return status, err
+		}
+
+		if status.Exited() || status.Signaled() {
+			if pid == root {
+				return status, nil
+			}
+			delete(known, pid)
+			delete(inSyscall, pid)
+			continue
+		}
+		if !status.Stopped() {
+			continue
+		}
+
+		var signal int
+		switch sig := status.StopSignal(); {
+		case sig == syscall.SIGTRAP|0x80:
+			if !inSyscall[pid] {
+				t.syscallEntry(pid)
+			}
+			inSyscall[pid] = !inSyscall[pid]
+		case sig == syscall.SIGTRAP:
+			// ptrace events, new processes are traced automatically
+		case sig == syscall.SIGSTOP && !known[pid]:
+			// initial stop of a new process
+			known[pid] = true
+		default:
+			signal = int(sig)
+		}
+
+		// fails if the process was killed in the meantime
+		_ = syscall.PtraceSyscall(pid, signal)
+	}
+}
+
+func wait4(pid int, status *syscall.WaitStatus) (int, error) {
+	for {
+		wpid, err := syscall.Wait4(pid, status, syscall.WALL|wNoThread, nil)
+		if err != syscall.EINTR {
+			return wpid, err
+		}
+	}
+}
+
+// syscallEntry records the path passed to open and exec calls.
+func (t *Tracer) syscallEntry(pid int) {
+	var regs syscall.PtraceRegs
+	if err := syscall.PtraceGetRegs(pid, &regs); err != nil {
+		return
+	}
+	nr, args := syscallArgs(&regs)
+
+	dirfd := atFdCwd
+	var path uint64
+	var write bool
+	switch nr {
+	case sysOpen:
+		path, write = args[0], isWrite(args[1])
+	case sysCreat:
+		path, write = args[0], true
+	case syscall.SYS_OPENAT:
+		dirfd, path, write = int(int32(args[0])), args[1], isWrite(args[2])
+	case sysOpenat2:
+		// the flags are the first field of struct open_how
+		flags := make([]byte, 8)
+		if n, _ := syscall.PtracePeekData(pid, uintptr(args[2]), flags); n != len(flags) {
+			return
+		}
+		dirfd, path, write = int(int32(args[0])), args[1], isWrite(binary.NativeEndian.Uint64(flags))
+	case syscall.SYS_EXECVE:
+		path = args[0]
+	case sysExecveat:
+		dirfd, path = int(int32(args[0])), args[1]
+	default:
+		return
+	}
+
+	name := readString(pid, uintptr(path))
+	if name == "" {
+		return
+	}
+	if !filepath.IsAbs(name) {
+		dir := "cwd"
+		if dirfd != atFdCwd {
+			dir = filepath.Join("fd", strconv.Itoa(dirfd))
+		}
+		base, err := os.Readlink(filepath.Join("/proc", strconv.Itoa(pid), dir))
+		if err != nil {
+			return
+		}
+		name = filepath.Join(base, name)
+	}
+	t.add(name, write)
+}
+
+func isWrite(flags uint64) bool {
+	return flags&(syscall.O_WRONLY|syscall.O_RDWR|syscall.O_CREAT|syscall.O_TRUNC|syscall.O_APPEND) != 0
+}
+
+// readString reads a null terminated string from the memory of a tracee.
+func readString(pid int, addr uintptr) string {
+	var s []byte
+	buf := make([]byte, 256)
+	for len(s) < pathMax {
+		n, _ := syscall.PtracePeekData(pid, addr+uintptr(len(s)), buf)
+		for i := 0; i < n; i++ {
+			if buf[i] == 0 {
+				return string(append(s, buf[:i]...))
+			}
+		}
+		if n < len(buf) {
+			return ""
+		}
+		s = append(s, buf...)
+	}
+	return ""
+}
@@ -0,0 +1,16 @@
+package proctrace
+
+import "syscall"
+
+const (
+	sysOpen     = syscall.SYS_OPEN
+	sysCreat    = syscall.SYS_CREAT
+	sysOpenat2  = 437
+	sysExecveat = 322
+)
+
+// syscallArgs returns the syscall number and its first arguments
+// at a syscall entry stop.
+func syscallArgs(regs *syscall.PtraceRegs) (uint64, [3]uint64) {
+	return regs.Orig_rax, [3]uint64{regs.Rdi, regs.Rsi, regs.Rdx}
+}
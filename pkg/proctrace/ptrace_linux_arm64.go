@@ -0,0 +1,17 @@
+package proctrace
+
+import "syscall"
+
+const (
+	// open and creat only exist as openat on arm64
+	sysOpen     = ^uint64(0)
+	sysCreat    = ^uint64(0) - 1
+	sysOpenat2  = 437
+	sysExecveat = 281
+)
+
+// syscallArgs returns the syscall number and its first arguments
+// at a syscall entry stop.
+func syscallArgs(regs *syscall.PtraceRegs) (uint64, [3]uint64) {
+	return regs.Regs[8], [3]uint64{regs.Regs[0], regs.Regs[1], regs.Regs[2]}
+}
@@ -0,0 +1,43 @@
+//go:build !linux || !(amd64 || arm64)
+
+package proctrace
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// Supported returns true if commands are traced on this platform.
+func Supported() bool {
+	return false
+}
+
+// Process is a command started by Start().
+type Process struct {
+	cmd *exec.Cmd
+}
+
+// Start starts the command, it is not traced on this platform.
+func (t *Tracer) Start(cmd *exec.Cmd) (*Process, error) {
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &Process{cmd: cmd}, nil
+}
+
+// Signal sends a signal to the command unless it already exited.
+func (p *Process) Signal(sig os.Signal) error {
+	return p.cmd.Process.Signal(sig)
+}
+
+// Wait waits for the command to exit, an unsuccessful exit is
+// reported as *ExitError.
+func (p *Process) Wait() error {
+	err := p.cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return &ExitError{Code: exitErr.ExitCode()}
+	}
+	return err
+}
@@ -0,0 +1,21 @@
+package runlog
+
+type Option func(w *Writer)
+
+// WithMaxSize in bytes of a log file before it is rotated.
+func WithMaxSize(size int64) Option {
+	return func(w *Writer) {
+		if size > 0 {
+			w.maxSize = size
+		}
+	}
+}
+
+// WithMaxFiles kept including the file currently written to.
+func WithMaxFiles(files int) Option {
+	return func(w *Writer) {
+		if files > 0 {
+			w.maxFiles = files
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package runlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// FileName of the log file currently written to,
+	// rotated files are suffixed with `.1`, `.2`, ...
+	FileName = "output.log"
+
+	DefaultMaxSize  = 10 * 1024 * 1024
+	DefaultMaxFiles = 5
+)
+
+// Dir returns the log directory of a task inside of base.
+func Dir(base, task string) string {
+	return filepath.Join(base, filepath.FromSlash(task))
+}
+
+// Writer writes to a log file in a directory. The file is rotated when it
+// exceeds the max size, the oldest files are removed when there are more
+// than max files.
+type Writer struct {
+	mux sync.Mutex
+
+	dir      string
+	maxSize  int64
+	maxFiles int
+
+	file *os.File
+	size int64
+}
+
+// NewWriter creates the log directory and rotates an existing
+// log file so that every writer starts with an empty file.
+func NewWriter(dir string, opts ...Option) (*Writer, error) {
+	w := &Writer{
+		dir:      dir,
+		maxSize:  DefaultMaxSize,
+		maxFiles: DefaultMaxFiles,
+	}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(w)
+	}
+
+	err := os.MkdirAll(dir, 0775)
+	if err != nil {
+		return nil, err
+	}
+
+	if fi, err := os.Stat(w.path()); err == nil && fi.Size() > 0 {
+		err = w.rotate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = w.open()
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Write p to the log file. p is never split between two files.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		err = w.file.Close()
+		if err != nil {
+			return 0, err
+		}
+		err = w.rotate()
+		if err != nil {
+			return 0, err
+		}
+		err = w.open()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err = w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *Writer) Close() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *Writer) path() string {
+	return filepath.Join(w.dir, FileName)
+}
+
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.path(), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0664)
+	if err != nil {
+		return err
+	}
+	w.file = f
+	w.size = 0
+	return nil
+}
+
+// rotate shifts `output.log.n` to `output.log.n+1`,
+// files exceeding max files are removed.
+func (w *Writer) rotate() error {
+	files, err := Files(w.dir)
+	if err != nil {
+		return err
+	}
+
+	// oldest first
+	for i, path := range files {
+		index := len(files) - i
+		if index >= w.maxFiles {
+			err = os.Remove(path)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		err = os.Rename(path, filepath.Join(w.dir, fmt.Sprintf("%s.%d", FileName, index)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Files returns the log files in dir, oldest first.
+// The file currently written to is the last one.
+func Files(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	indexes := map[string]int{}
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		if name == FileName {
+			indexes[name] = 0
+			files = append(files, name)
+			continue
+		}
+		suffix, ok := strings.CutPrefix(name, FileName+".")
+		if !ok {
+			continue
+		}
+		index, err := strconv.Atoi(suffix)
+		if err != nil || index < 1 {
+			continue
+		}
+		indexes[name] = index
+		files = append(files, name)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return indexes[files[i]] > indexes[files[j]]
+	})
+
+	for i := range files {
+		files[i] = filepath.Join(dir, files[i])
+	}
+	return files, nil
+}
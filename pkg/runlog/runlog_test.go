@@ -0,0 +1,59 @@
+package runlog
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriterRotates(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := NewWriter(dir, WithMaxSize(10), WithMaxFiles(3))
+	assert.Nil(t, err)
+
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n", "five\n", "six\n"} {
+		_, err = w.Write([]byte(line))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, w.Close())
+
+	files, err := Files(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, FileName+".2"),
+		filepath.Join(dir, FileName+".1"),
+		filepath.Join(dir, FileName),
+	}, files)
+
+	var out bytes.Buffer
+	err = Tail(context.Background(), dir, &out, false)
+	assert.Nil(t, err)
+	// "one\ntwo\n" was dropped with the oldest file
+	assert.Equal(t, "three\nfour\nfive\nsix\n", out.String())
+}
+
+func TestNewWriterRotatesPreviousLog(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, FileName), []byte("previous\n"), 0664)
+	assert.Nil(t, err)
+
+	w, err := NewWriter(dir)
+	assert.Nil(t, err)
+	_, err = w.Write([]byte("current\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+
+	previous, err := os.ReadFile(filepath.Join(dir, FileName+".1"))
+	assert.Nil(t, err)
+	assert.Equal(t, "previous\n", string(previous))
+
+	current, err := os.ReadFile(filepath.Join(dir, FileName))
+	assert.Nil(t, err)
+	assert.Equal(t, "current\n", string(current))
+}
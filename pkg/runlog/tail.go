@@ -0,0 +1,100 @@
+package runlog
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// pollInterval used to check for new content when following.
+const pollInterval = 250 * time.Millisecond
+
+// Tail copies all log files in dir to w, oldest first. With follow it keeps
+// copying what is appended to the current log file until ctx is canceled,
+// rotations are followed.
+func Tail(ctx context.Context, dir string, w io.Writer, follow bool) error {
+	files, err := Files(dir)
+	if err != nil {
+		return err
+	}
+
+	current := filepath.Join(dir, FileName)
+	for _, path := range files {
+		if path == current {
+			continue
+		}
+		err = copyFile(path, w)
+		if err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Open(current)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		if !follow {
+			return nil
+		}
+	}
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+
+	for {
+		if f != nil {
+			_, err = io.Copy(w, f)
+			if err != nil {
+				return err
+			}
+		}
+
+		if !follow {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(pollInterval):
+		}
+
+		// reopen on rotation or when the file was not created yet
+		fi, err := os.Stat(current)
+		if err != nil {
+			continue
+		}
+		if f != nil {
+			ofi, err := f.Stat()
+			if err == nil && os.SameFile(fi, ofi) {
+				continue
+			}
+			// drain what was written before the rotation
+			_, err = io.Copy(w, f)
+			if err != nil {
+				return err
+			}
+			f.Close()
+		}
+		f, err = os.Open(current)
+		if err != nil {
+			f = nil
+		}
+	}
+}
+
+func copyFile(path string, w io.Writer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
@@ -0,0 +1,122 @@
+package filestore
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/benchkram/bob/pkg/file"
+	"github.com/benchkram/bob/pkg/filehash"
+	"github.com/benchkram/errz"
+)
+
+var ErrBlobHashMismatch = fmt.Errorf("blob content does not match its id")
+
+// NewBlob creates a new blob. The caller is responsible to call Close().
+//
+// The blob is written to a temporary file first and only moved to its
+// final location on Close() when the content matches the blob id. This
+// assures concurrent or interrupted writes never leave a corrupted blob behind.
+func (s *s) NewBlob(_ context.Context, id string, _ int64) (_ io.WriteCloser, err error) {
+	defer errz.Recover(&err)
+
+	err = os.MkdirAll(s.blobDir, 0775)
+	errz.Fatal(err)
+
+	f, err := os.CreateTemp(s.blobDir, ".tmp-"+id+"-*")
+	errz.Fatal(err)
+
+	return &blobWriter{
+		f:    f,
+		h:    filehash.New(),
+		id:   id,
+		path: filepath.Join(s.blobDir, id),
+	}, nil
+}
+
+// GetBlob opens a blob
+func (s *s) GetBlob(_ context.Context, id string) (_ io.ReadCloser, size int64, _ error) {
+	f, err := os.Open(filepath.Join(s.blobDir, id))
+	if err != nil {
+		return nil, 0, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	return f, stat.Size(), nil
+}
+
+// ListBlobs lists the id's of all blobs in the store
+func (s *s) ListBlobs(_ context.Context) (ids []string, err error) {
+	defer errz.Recover(&err)
+
+	ids = []string{}
+
+	entrys, err := os.ReadDir(s.blobDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ids, nil
+		}
+		errz.Fatal(err)
+	}
+
+	for _, e := range entrys {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".tmp-") {
+			continue
+		}
+		ids = append(ids, e.Name())
+	}
+
+	return ids, nil
+}
+
+func (s *s) BlobExists(_ context.Context, id string) bool {
+	return file.Exists(filepath.Join(s.blobDir, id))
+}
+
+func (s *s) BlobRemove(ctx context.Context, id string) error {
+	if !s.BlobExists(ctx, id) {
+		return nil
+	}
+	return os.Remove(filepath.Join(s.blobDir, id))
+}
+
+// blobWriter hashes the content while writing
+// and verifies it against the blob id on Close().
+type blobWriter struct {
+	f    *os.File
+	h    *filehash.H
+	id   string
+	path string
+}
+
+func (w *blobWriter) Write(p []byte) (int, error) {
+	n, err := w.f.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, w.h.AddBytes(bytes.NewReader(p[:n]))
+}
+
+func (w *blobWriter) Close() error {
+	err := w.f.Close()
+	if err != nil {
+		_ = os.Remove(w.f.Name())
+		return err
+	}
+
+	if hex.EncodeToString(w.h.Sum()) != w.id {
+		_ = os.Remove(w.f.Name())
+		return fmt.Errorf("%w [id: %s]", ErrBlobHashMismatch, w.id)
+	}
+
+	return os.Rename(w.f.Name(), w.path)
+}
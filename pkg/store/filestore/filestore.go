@@ -14,6 +14,9 @@ import (
 
 type s struct {
 	dir string
+
+	// blobDir is the directory content addressable blobs are stored in.
+	blobDir string
 }
 
 // New creates a filestore. The caller is responsible to pass a
@@ -30,6 +33,10 @@ func New(dir string, opts ...Option) store.Store {
 		opt(s)
 	}
 
+	if s.blobDir == "" {
+		s.blobDir = filepath.Join(s.dir, "blobs")
+	}
+
 	return s
 }
 
@@ -71,6 +78,14 @@ func (s *s) Clean(_ context.Context) (err error) {
 		_ = os.Remove(filepath.Join(s.dir, entry.Name()))
 	}
 
+	// All artifacts are gone, so there is no blob left
+	// which could be referenced.
+	blobs, err := s.ListBlobs(context.TODO())
+	errz.Fatal(err)
+	for _, id := range blobs {
+		_ = os.Remove(filepath.Join(s.blobDir, id))
+	}
+
 	return nil
 }
 
@@ -82,6 +97,9 @@ func (s *s) List(_ context.Context) (items []string, err error) {
 
 	items = []string{}
 	for _, e := range entrys {
+		if e.IsDir() {
+			continue
+		}
 		items = append(items, e.Name())
 	}
 
@@ -7,3 +7,11 @@ func WithDir(dir string) Option {
 		s.dir = dir
 	}
 }
+
+// WithBlobDir sets the directory used to store content addressable blobs.
+// Defaults to a `blobs` directory inside the store directory.
+func WithBlobDir(dir string) Option {
+	return func(s *s) {
+		s.blobDir = dir
+	}
+}
@@ -0,0 +1,244 @@
+package httpstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/benchkram/errz"
+
+	"github.com/benchkram/bob/pkg/store"
+)
+
+// Artifacts and blobs are stored using the layout of common http
+// build caches. Artifacts are addressed by their input hash and
+// kept in the action cache, blobs are addressed by the hash of their
+// content and kept in the content addressable storage.
+const (
+	artifactPrefix = "ac"
+	blobPrefix     = "cas"
+)
+
+var ErrUnexpectedStatus = fmt.Errorf("unexpected status code")
+
+type s struct {
+	// url is the base url of the cache.
+	url *url.URL
+
+	client *http.Client
+
+	username string
+	password string
+}
+
+// New creates a store talking the plain http cache protocol
+// (GET/PUT/HEAD/DELETE) against the given base url.
+func New(u *url.URL, opts ...Option) store.Store {
+	s := &s{
+		url:    u,
+		client: http.DefaultClient,
+	}
+
+	if u.User != nil {
+		s.username = u.User.Username()
+		s.password, _ = u.User.Password()
+	}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(s)
+	}
+
+	return s
+}
+
+// NewArtifact uploads an artifact. The upload is finished when Close() returns.
+// Existing artifacts are overwritten.
+func (s *s) NewArtifact(ctx context.Context, artifactID string, size int64) (io.WriteCloser, error) {
+	return s.upload(ctx, s.path(artifactPrefix, artifactID), size), nil
+}
+
+// GetArtifact downloads an artifact. The caller is responsible to call Close().
+func (s *s) GetArtifact(ctx context.Context, id string) (io.ReadCloser, int64, error) {
+	rc, size, err := s.get(ctx, s.path(artifactPrefix, id))
+	if err == errNotFound {
+		return nil, 0, store.ErrArtifactNotFoundinSrc
+	}
+	return rc, size, err
+}
+
+// List is not supported by the http cache protocol.
+func (s *s) List(_ context.Context) ([]string, error) {
+	return nil, store.ErrListNotSupported
+}
+
+func (s *s) Clean(_ context.Context) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (s *s) ArtifactExists(ctx context.Context, id string) bool {
+	return s.exists(ctx, s.path(artifactPrefix, id))
+}
+
+func (s *s) ArtifactRemove(ctx context.Context, id string) error {
+	return s.remove(ctx, s.path(artifactPrefix, id))
+}
+
+// Done does nothing, uploads are finished on Close().
+func (s *s) Done() error {
+	return nil
+}
+
+func (s *s) NewBlob(ctx context.Context, id string, size int64) (io.WriteCloser, error) {
+	return s.upload(ctx, s.path(blobPrefix, id), size), nil
+}
+
+func (s *s) GetBlob(ctx context.Context, id string) (io.ReadCloser, int64, error) {
+	rc, size, err := s.get(ctx, s.path(blobPrefix, id))
+	if err == errNotFound {
+		return nil, 0, store.ErrBlobNotFoundInSrc
+	}
+	return rc, size, err
+}
+
+// ListBlobs is not supported by the http cache protocol.
+func (s *s) ListBlobs(_ context.Context) ([]string, error) {
+	return nil, store.ErrListNotSupported
+}
+
+func (s *s) BlobExists(ctx context.Context, id string) bool {
+	return s.exists(ctx, s.path(blobPrefix, id))
+}
+
+func (s *s) BlobRemove(ctx context.Context, id string) error {
+	return s.remove(ctx, s.path(blobPrefix, id))
+}
+
+var errNotFound = fmt.Errorf("not found")
+
+func (s *s) path(prefix, id string) string {
+	u := *s.url
+	u.User = nil
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + prefix + "/" + id
+	return u.String()
+}
+
+func (s *s) newRequest(ctx context.Context, method, u string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if s.username != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+	return req, nil
+}
+
+func (s *s) get(ctx context.Context, u string) (_ io.ReadCloser, _ int64, err error) {
+	defer errz.Recover(&err)
+
+	req, err := s.newRequest(ctx, http.MethodGet, u, nil)
+	errz.Fatal(err)
+
+	resp, err := s.client.Do(req)
+	errz.Fatal(err)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, resp.ContentLength, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, 0, errNotFound
+	default:
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("%w [%d] on GET %s", ErrUnexpectedStatus, resp.StatusCode, u)
+	}
+}
+
+func (s *s) exists(ctx context.Context, u string) bool {
+	req, err := s.newRequest(ctx, http.MethodHead, u, nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK
+}
+
+func (s *s) remove(ctx context.Context, u string) (err error) {
+	defer errz.Recover(&err)
+
+	req, err := s.newRequest(ctx, http.MethodDelete, u, nil)
+	errz.Fatal(err)
+
+	resp, err := s.client.Do(req)
+	errz.Fatal(err)
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("%w [%d] on DELETE %s", ErrUnexpectedStatus, resp.StatusCode, u)
+	}
+}
+
+// upload streams everything written to the returned writer
+// to the cache. Close() waits for the request to finish.
+func (s *s) upload(ctx context.Context, u string, size int64) io.WriteCloser {
+	reader, writer := io.Pipe()
+	w := &uploadWriter{
+		PipeWriter: writer,
+		done:       make(chan error, 1),
+	}
+
+	go func() {
+		err := s.put(ctx, u, reader, size)
+		// unblock the writer in case the request failed early
+		_ = reader.CloseWithError(err)
+		w.done <- err
+	}()
+
+	return w
+}
+
+func (s *s) put(ctx context.Context, u string, body io.Reader, size int64) (err error) {
+	defer errz.Recover(&err)
+
+	req, err := s.newRequest(ctx, http.MethodPut, u, body)
+	errz.Fatal(err)
+	if size > 0 {
+		req.ContentLength = size
+	}
+
+	resp, err := s.client.Do(req)
+	errz.Fatal(err)
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent:
+		return nil
+	default:
+		return fmt.Errorf("%w [%d] on PUT %s", ErrUnexpectedStatus, resp.StatusCode, u)
+	}
+}
+
+type uploadWriter struct {
+	*io.PipeWriter
+	done chan error
+}
+
+func (w *uploadWriter) Close() error {
+	_ = w.PipeWriter.Close()
+	return <-w.done
+}
@@ -0,0 +1,157 @@
+package httpstore
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/benchkram/bob/pkg/filehash"
+	"github.com/benchkram/bob/pkg/store"
+	"github.com/benchkram/bob/pkg/store/filestore"
+)
+
+// newCache starts a in-memory http cache.
+func newCache(t *testing.T) (*httptest.Server, map[string][]byte) {
+	var mu sync.Mutex
+	items := map[string][]byte{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		user, pass, _ := r.BasicAuth()
+		if user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPut:
+			bin, err := io.ReadAll(r.Body)
+			assert.Nil(t, err)
+			items[r.URL.Path] = bin
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet, http.MethodHead:
+			bin, ok := items[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if r.Method == http.MethodGet {
+				_, _ = w.Write(bin)
+			}
+		case http.MethodDelete:
+			delete(items, r.URL.Path)
+		}
+	}))
+
+	return server, items
+}
+
+func TestHTTPStore(t *testing.T) {
+	server, items := newCache(t)
+	defer server.Close()
+
+	u, err := url.Parse(server.URL + "/cache")
+	assert.Nil(t, err)
+	u.User = url.UserPassword("user", "pass")
+	s := New(u)
+	ctx := context.Background()
+
+	w, err := s.NewArtifact(ctx, "aaa", 7)
+	assert.Nil(t, err)
+	_, err = w.Write([]byte("content"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+	assert.Equal(t, "content", string(items["/cache/ac/aaa"]))
+
+	assert.True(t, s.ArtifactExists(ctx, "aaa"))
+	assert.False(t, s.ArtifactExists(ctx, "bbb"))
+
+	found, err := store.Exists(ctx, s, "aaa")
+	assert.Nil(t, err)
+	assert.True(t, found)
+
+	rc, _, err := s.GetArtifact(ctx, "aaa")
+	assert.Nil(t, err)
+	bin, err := io.ReadAll(rc)
+	assert.Nil(t, err)
+	assert.Nil(t, rc.Close())
+	assert.Equal(t, "content", string(bin))
+
+	_, _, err = s.GetArtifact(ctx, "bbb")
+	assert.ErrorIs(t, err, store.ErrArtifactNotFoundinSrc)
+
+	assert.Nil(t, s.ArtifactRemove(ctx, "aaa"))
+	assert.False(t, s.ArtifactExists(ctx, "aaa"))
+
+	// failed uploads are reported on close
+	unauthorized := New(u, WithBasicAuth("user", "wrong"))
+	w, err = unauthorized.NewArtifact(ctx, "ccc", 0)
+	assert.Nil(t, err)
+	_, _ = w.Write([]byte("content"))
+	assert.ErrorIs(t, w.Close(), ErrUnexpectedStatus)
+}
+
+func TestHTTPStoreSync(t *testing.T) {
+	server, items := newCache(t)
+	defer server.Close()
+
+	dir, err := os.MkdirTemp("", "test-httpstore-sync")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+	u.User = url.UserPassword("user", "pass")
+	remote := New(u)
+	local := filestore.New(dir)
+	ctx := context.Background()
+
+	w, err := local.NewArtifact(ctx, "aaa", 0)
+	assert.Nil(t, err)
+	_, err = w.Write([]byte("artifact"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+
+	// the blob id must match the hash of its content
+	h := filehash.New()
+	assert.Nil(t, h.AddBytes(strings.NewReader("blob")))
+	blobID := hex.EncodeToString(h.Sum())
+	w, err = local.(store.BlobStore).NewBlob(ctx, blobID, 0)
+	assert.Nil(t, err)
+	_, err = w.Write([]byte("blob"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+
+	// push
+	assert.Nil(t, store.SyncBlob(ctx, local.(store.BlobStore), remote.(store.BlobStore), blobID))
+	assert.Nil(t, store.Sync(ctx, local, remote, "aaa", false))
+	assert.ErrorIs(t, store.Sync(ctx, local, remote, "aaa", false), store.ErrArtifactAlreadyExists)
+	assert.Equal(t, "blob", string(items["/cas/"+blobID]))
+
+	// pull
+	assert.Nil(t, local.ArtifactRemove(ctx, "aaa"))
+	assert.Nil(t, local.(store.BlobStore).BlobRemove(ctx, blobID))
+	assert.Nil(t, store.SyncBlob(ctx, remote.(store.BlobStore), local.(store.BlobStore), blobID))
+	assert.Nil(t, store.Sync(ctx, remote, local, "aaa", true))
+
+	rc, _, err := local.GetArtifact(ctx, "aaa")
+	assert.Nil(t, err)
+	bin, err := io.ReadAll(rc)
+	assert.Nil(t, err)
+	assert.Nil(t, rc.Close())
+	assert.Equal(t, "artifact", string(bin))
+	assert.True(t, local.(store.BlobStore).BlobExists(ctx, blobID))
+
+	assert.ErrorIs(t, store.Sync(ctx, remote, local, "bbb", true), store.ErrArtifactNotFoundinSrc)
+}
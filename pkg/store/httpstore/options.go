@@ -0,0 +1,22 @@
+package httpstore
+
+import (
+	"net/http"
+)
+
+type Option func(s *s)
+
+func WithClient(client *http.Client) Option {
+	return func(s *s) {
+		s.client = client
+	}
+}
+
+// WithBasicAuth sets credentials used for every request.
+// Takes precedence over credentials passed in the url.
+func WithBasicAuth(username, password string) Option {
+	return func(s *s) {
+		s.username = username
+		s.password = password
+	}
+}
@@ -0,0 +1,45 @@
+package s3store
+
+import (
+	"net/http"
+)
+
+type Option func(s *s)
+
+func WithClient(client *http.Client) Option {
+	return func(s *s) {
+		s.client = client
+	}
+}
+
+// WithPrefix stores all objects below the given prefix of the bucket.
+func WithPrefix(prefix string) Option {
+	return func(s *s) {
+		s.prefix = prefix
+	}
+}
+
+func WithRegion(region string) Option {
+	return func(s *s) {
+		if region != "" {
+			s.region = region
+		}
+	}
+}
+
+// WithCredentials sets the credentials used to sign requests.
+// Requests are sent anonymously when no access key is given.
+func WithCredentials(accessKey, secretKey, sessionToken string) Option {
+	return func(s *s) {
+		s.accessKey = accessKey
+		s.secretKey = secretKey
+		s.sessionToken = sessionToken
+	}
+}
+
+// WithInsecure uses http instead of https to access the endpoint.
+func WithInsecure(insecure bool) Option {
+	return func(s *s) {
+		s.insecure = insecure
+	}
+}
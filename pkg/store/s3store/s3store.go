@@ -0,0 +1,338 @@
+package s3store
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/benchkram/errz"
+
+	"github.com/benchkram/bob/pkg/store"
+)
+
+const (
+	artifactPrefix = "artifacts"
+	blobPrefix     = "blobs"
+
+	DefaultRegion = "us-east-1"
+)
+
+var ErrUnexpectedStatus = fmt.Errorf("unexpected status code")
+
+type s struct {
+	// endpoint is the host[:port] of the s3 compatible api.
+	endpoint string
+	insecure bool
+
+	bucket string
+	// prefix all objects are stored under.
+	prefix string
+
+	region       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+
+	client *http.Client
+}
+
+// New creates a store using a bucket of an S3 compatible api.
+// Objects are addressed path-style, as supported by most implementations.
+func New(endpoint, bucket string, opts ...Option) store.Store {
+	s := &s{
+		endpoint: endpoint,
+		bucket:   bucket,
+		region:   DefaultRegion,
+		client:   http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(s)
+	}
+
+	s.prefix = strings.Trim(s.prefix, "/")
+
+	return s
+}
+
+// NewArtifact uploads an artifact. The upload is finished when Close() returns.
+// Existing artifacts are overwritten.
+func (s *s) NewArtifact(ctx context.Context, artifactID string, _ int64) (io.WriteCloser, error) {
+	return s.upload(ctx, s.key(artifactPrefix, artifactID))
+}
+
+// GetArtifact downloads an artifact. The caller is responsible to call Close().
+func (s *s) GetArtifact(ctx context.Context, id string) (io.ReadCloser, int64, error) {
+	rc, size, err := s.get(ctx, s.key(artifactPrefix, id))
+	if err == errNotFound {
+		return nil, 0, store.ErrArtifactNotFoundinSrc
+	}
+	return rc, size, err
+}
+
+// List the artifact id's in the bucket
+func (s *s) List(ctx context.Context) ([]string, error) {
+	return s.list(ctx, s.key(artifactPrefix, ""))
+}
+
+// Clean removes all artifacts and blobs from the bucket.
+func (s *s) Clean(ctx context.Context) (err error) {
+	defer errz.Recover(&err)
+
+	ids, err := s.List(ctx)
+	errz.Fatal(err)
+	for _, id := range ids {
+		err = s.ArtifactRemove(ctx, id)
+		errz.Fatal(err)
+	}
+
+	blobs, err := s.ListBlobs(ctx)
+	errz.Fatal(err)
+	for _, id := range blobs {
+		err = s.BlobRemove(ctx, id)
+		errz.Fatal(err)
+	}
+
+	return nil
+}
+
+func (s *s) ArtifactExists(ctx context.Context, id string) bool {
+	return s.exists(ctx, s.key(artifactPrefix, id))
+}
+
+func (s *s) ArtifactRemove(ctx context.Context, id string) error {
+	return s.remove(ctx, s.key(artifactPrefix, id))
+}
+
+// Done does nothing, uploads are finished on Close().
+func (s *s) Done() error {
+	return nil
+}
+
+func (s *s) NewBlob(ctx context.Context, id string, _ int64) (io.WriteCloser, error) {
+	return s.upload(ctx, s.key(blobPrefix, id))
+}
+
+func (s *s) GetBlob(ctx context.Context, id string) (io.ReadCloser, int64, error) {
+	rc, size, err := s.get(ctx, s.key(blobPrefix, id))
+	if err == errNotFound {
+		return nil, 0, store.ErrBlobNotFoundInSrc
+	}
+	return rc, size, err
+}
+
+func (s *s) ListBlobs(ctx context.Context) ([]string, error) {
+	return s.list(ctx, s.key(blobPrefix, ""))
+}
+
+func (s *s) BlobExists(ctx context.Context, id string) bool {
+	return s.exists(ctx, s.key(blobPrefix, id))
+}
+
+func (s *s) BlobRemove(ctx context.Context, id string) error {
+	return s.remove(ctx, s.key(blobPrefix, id))
+}
+
+var errNotFound = fmt.Errorf("not found")
+
+// key returns the object key of an item. An empty id
+// results in the prefix used to list items.
+func (s *s) key(prefix, id string) string {
+	return strings.TrimPrefix(path.Join(s.prefix, prefix)+"/"+id, "/")
+}
+
+func (s *s) newRequest(ctx context.Context, method, key string, query url.Values, body io.Reader) (*http.Request, error) {
+	scheme := "https"
+	if s.insecure {
+		scheme = "http"
+	}
+
+	u := &url.URL{
+		Scheme:   scheme,
+		Host:     s.endpoint,
+		Path:     "/" + s.bucket,
+		RawQuery: query.Encode(),
+	}
+	if key != "" {
+		u.Path += "/" + key
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+	if s.accessKey != "" {
+		sign(req, s.accessKey, s.secretKey, s.region, time.Now())
+	}
+
+	return req, nil
+}
+
+func (s *s) get(ctx context.Context, key string) (_ io.ReadCloser, _ int64, err error) {
+	defer errz.Recover(&err)
+
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil, nil)
+	errz.Fatal(err)
+
+	resp, err := s.client.Do(req)
+	errz.Fatal(err)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, resp.ContentLength, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, 0, errNotFound
+	default:
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("%w [%d] on GET %s", ErrUnexpectedStatus, resp.StatusCode, key)
+	}
+}
+
+func (s *s) exists(ctx context.Context, key string) bool {
+	req, err := s.newRequest(ctx, http.MethodHead, key, nil, nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK
+}
+
+func (s *s) remove(ctx context.Context, key string) (err error) {
+	defer errz.Recover(&err)
+
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil, nil)
+	errz.Fatal(err)
+
+	resp, err := s.client.Do(req)
+	errz.Fatal(err)
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("%w [%d] on DELETE %s", ErrUnexpectedStatus, resp.StatusCode, key)
+	}
+}
+
+type listBucketResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+}
+
+// list returns the names of all objects below prefix
+// using the ListObjectsV2 api.
+func (s *s) list(ctx context.Context, prefix string) (ids []string, err error) {
+	defer errz.Recover(&err)
+
+	ids = []string{}
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		req, err := s.newRequest(ctx, http.MethodGet, "", query, nil)
+		errz.Fatal(err)
+
+		resp, err := s.client.Do(req)
+		errz.Fatal(err)
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%w [%d] on list %s", ErrUnexpectedStatus, resp.StatusCode, prefix)
+		}
+
+		result := listBucketResult{}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		errz.Fatal(err)
+
+		for _, c := range result.Contents {
+			ids = append(ids, strings.TrimPrefix(c.Key, prefix))
+		}
+
+		if !result.IsTruncated {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+
+	return ids, nil
+}
+
+// upload spools everything written to the returned writer to a
+// temporary file, as the api requires the size of an object in
+// advance. The object is uploaded on Close().
+func (s *s) upload(ctx context.Context, key string) (io.WriteCloser, error) {
+	f, err := os.CreateTemp("", "bob-s3-upload-*")
+	if err != nil {
+		return nil, err
+	}
+	return &uploadWriter{File: f, ctx: ctx, key: key, s: s}, nil
+}
+
+func (s *s) put(ctx context.Context, key string, f *os.File) (err error) {
+	defer errz.Recover(&err)
+
+	stat, err := f.Stat()
+	errz.Fatal(err)
+	_, err = f.Seek(0, io.SeekStart)
+	errz.Fatal(err)
+
+	req, err := s.newRequest(ctx, http.MethodPut, key, nil, f)
+	errz.Fatal(err)
+	req.ContentLength = stat.Size()
+
+	resp, err := s.client.Do(req)
+	errz.Fatal(err)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w [%d] on PUT %s", ErrUnexpectedStatus, resp.StatusCode, key)
+	}
+
+	return nil
+}
+
+type uploadWriter struct {
+	*os.File
+
+	ctx context.Context
+	key string
+	s   *s
+}
+
+func (w *uploadWriter) Close() error {
+	defer os.Remove(w.File.Name())
+	defer w.File.Close()
+
+	return w.s.put(w.ctx, w.key, w.File)
+}
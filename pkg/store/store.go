@@ -27,7 +27,26 @@ type Store interface {
 	Done() error
 }
 
+// BlobStore is implemented by stores which are able to store
+// content addressable blobs. Blobs are indexed by the hash of their
+// content and are therefore shared between artifacts.
+type BlobStore interface {
+	NewBlob(_ context.Context, id string, size int64) (io.WriteCloser, error)
+	GetBlob(_ context.Context, id string) (io.ReadCloser, int64, error)
+
+	ListBlobs(context.Context) ([]string, error)
+
+	BlobExists(ctx context.Context, id string) bool
+
+	BlobRemove(ctx context.Context, id string) error
+}
+
 var (
 	ErrArtifactNotFoundinSrc = fmt.Errorf("artifact not found in src")
 	ErrArtifactAlreadyExists = fmt.Errorf("artifact already exists")
+	ErrBlobNotFoundInSrc     = fmt.Errorf("blob not found in src")
+
+	// ErrListNotSupported is returned by stores which can not enumerate
+	// their content, e.g. a plain http cache.
+	ErrListNotSupported = fmt.Errorf("list not supported by store")
 )
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"errors"
 	"io"
 
 	"github.com/benchkram/errz"
@@ -12,14 +13,14 @@ import (
 func Sync(ctx context.Context, src, dst Store, id string, ignoreAlreadyExists bool) (err error) {
 	defer errz.Recover(&err)
 
-	found, err := exists(ctx, src, id)
+	found, err := Exists(ctx, src, id)
 	errz.Fatal(err)
 	if !found {
 		return ErrArtifactNotFoundinSrc
 	}
 
 	if !ignoreAlreadyExists {
-		found, err = exists(ctx, dst, id)
+		found, err = Exists(ctx, dst, id)
 		errz.Fatal(err)
 		if found {
 			return ErrArtifactAlreadyExists
@@ -46,10 +47,15 @@ func Sync(ctx context.Context, src, dst Store, id string, ignoreAlreadyExists bo
 	return src.Done()
 }
 
-func exists(ctx context.Context, store Store, id string) (found bool, err error) {
+// Exists checks if the artifact is listed in the store.
+// Stores which are not able to list their content are asked directly.
+func Exists(ctx context.Context, store Store, id string) (found bool, err error) {
 	defer errz.Recover(&err)
 
 	artifactIds, err := store.List(ctx)
+	if errors.Is(err, ErrListNotSupported) {
+		return store.ArtifactExists(ctx, id), nil
+	}
 	errz.Fatal(err)
 
 	for _, i := range artifactIds {
@@ -61,3 +67,31 @@ func exists(ctx context.Context, store Store, id string) (found bool, err error)
 
 	return found, nil
 }
+
+// SyncBlob copies a blob from the src store to the dst store.
+// In case the blob exists in dst SyncBlob does nothing and returns nil.
+func SyncBlob(ctx context.Context, src, dst BlobStore, id string) (err error) {
+	defer errz.Recover(&err)
+
+	if dst.BlobExists(ctx, id) {
+		return nil
+	}
+	if !src.BlobExists(ctx, id) {
+		return ErrBlobNotFoundInSrc
+	}
+
+	srcReader, size, err := src.GetBlob(ctx, id)
+	errz.Fatal(err)
+	defer srcReader.Close()
+
+	dstWriter, err := dst.NewBlob(ctx, id, size)
+	errz.Fatal(err)
+
+	_, err = io.Copy(dstWriter, srcReader)
+	if err != nil {
+		_ = dstWriter.Close()
+		errz.Fatal(err)
+	}
+
+	return dstWriter.Close()
+}
@@ -129,3 +129,62 @@ func BenchmarkFilterInputsSequential(b *testing.B) {
 
 	b.ReportAllocs()
 }
+
+// criticalPathBobfile has a chain of long running tasks which is
+// discovered after the short running tasks.
+const criticalPathBobfile = `build:
+  build:
+    cmd: echo done
+    dependsOn: [short1, short2, short3, short4, chain3]
+  short1:
+    cmd: sleep 0.05
+  short2:
+    cmd: sleep 0.05
+  short3:
+    cmd: sleep 0.05
+  short4:
+    cmd: sleep 0.05
+  chain1:
+    cmd: sleep 0.1
+  chain2:
+    cmd: sleep 0.1
+    dependsOn: [chain1]
+  chain3:
+    cmd: sleep 0.1
+    dependsOn: [chain2]
+`
+
+func BenchmarkCriticalPathBuild(b *testing.B) {
+	dir, storageDir, cleanup, err := setup.TestDirs("build-benchmark")
+	assert.Nil(b, err)
+	defer func() { _ = cleanup() }()
+
+	err = os.Chdir(dir)
+	assert.Nil(b, err)
+
+	err = os.WriteFile("bob.yaml", []byte(criticalPathBobfile), 0664)
+	assert.Nil(b, err)
+
+	bobInstance, err := bob.BobWithBaseStoreDir(storageDir,
+		bob.WithDir(dir),
+		bob.WithCachingEnabled(false),
+		bob.WithMaxParallel(2),
+	)
+	assert.Nil(b, err)
+
+	ctx := context.Background()
+
+	// records task durations
+	err = bobInstance.Build(ctx, "build")
+	assert.Nil(b, err)
+
+	b.ResetTimer()
+
+	var r error
+	for n := 0; n < b.N; n++ {
+		r = bobInstance.Build(ctx, "build")
+	}
+	// always store the result to a package level variable
+	// so the compiler cannot eliminate the Benchmark itself.
+	result = r
+}
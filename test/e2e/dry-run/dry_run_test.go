@@ -0,0 +1,79 @@
+package dryruntest
+
+import (
+	"context"
+	"os"
+
+	"github.com/benchkram/bob/bob"
+	"github.com/benchkram/bob/bob/playbook"
+	"github.com/benchkram/bob/bobtask/target"
+	"github.com/benchkram/bob/pkg/file"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Testing dry-run", func() {
+	ctx := context.Background()
+
+	var b *bob.B
+	It("should setup test environment", func() {
+		var err error
+		b, err = bobSetup()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should report a rebuild for all tasks without running them", func() {
+		results, err := b.BuildDryRun(ctx, "build")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(2))
+
+		Expect(results[0].Task).To(Equal("generate"))
+		Expect(results[0].Rebuild).To(BeTrue())
+		Expect(results[0].Cause).To(Equal(playbook.InputNotFoundInBuildInfo))
+
+		Expect(results[1].Task).To(Equal("build"))
+		Expect(results[1].Rebuild).To(BeTrue())
+
+		Expect(file.Exists("generated.txt")).To(BeFalse())
+		Expect(file.Exists("build.txt")).To(BeFalse())
+
+		artifacts, err := artifactStore.List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(artifacts).To(HaveLen(0))
+	})
+
+	It("should build", func() {
+		err := b.Build(ctx, "build")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should report all tasks as cached", func() {
+		results, err := b.BuildDryRun(ctx, "build")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(2))
+
+		for _, r := range results {
+			Expect(r.Rebuild).To(BeFalse())
+			Expect(r.Cause).To(BeEmpty())
+		}
+	})
+
+	It("should report a invalid target restored from the local store", func() {
+		err := os.Remove("generated.txt")
+		Expect(err).NotTo(HaveOccurred())
+
+		results, err := b.BuildDryRun(ctx, "build")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(2))
+
+		Expect(results[0].Rebuild).To(BeFalse())
+		Expect(results[0].Cause).To(Equal(playbook.TargetInvalid))
+		Expect(results[0].ArtifactSource).To(Equal(playbook.ArtifactSourceLocal))
+		Expect(results[0].InvalidFiles).To(HaveKeyWithValue("generated.txt", []target.Reason{target.ReasonMissing}))
+
+		Expect(results[1].Rebuild).To(BeFalse())
+
+		Expect(file.Exists("generated.txt")).To(BeFalse())
+	})
+})
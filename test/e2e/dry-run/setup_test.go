@@ -0,0 +1,39 @@
+package dryruntest
+
+import (
+	"os"
+
+	"github.com/benchkram/bob/bob"
+	"github.com/benchkram/errz"
+)
+
+const bobfile = `build:
+  generate:
+    input: input.txt
+    cmd: cp input.txt generated.txt
+    target: generated.txt
+  build:
+    input: input.txt
+    cmd: cp generated.txt build.txt
+    target: build.txt
+    dependsOn: [generate]
+`
+
+func bobSetup(opts ...bob.Option) (_ *bob.B, err error) {
+	defer errz.Recover(&err)
+
+	err = os.WriteFile("bob.yaml", []byte(bobfile), 0664)
+	errz.Fatal(err)
+	err = os.WriteFile("input.txt", []byte("input"), 0664)
+	errz.Fatal(err)
+
+	static := []bob.Option{
+		bob.WithDir(dir),
+		bob.WithFilestore(artifactStore),
+		bob.WithBuildinfoStore(buildInfoStore),
+	}
+	static = append(static, opts...)
+	return bob.Bob(
+		static...,
+	)
+}
@@ -0,0 +1,65 @@
+package dryruntest
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/benchkram/bob/bob"
+	"github.com/benchkram/bob/pkg/buildinfostore"
+	"github.com/benchkram/bob/pkg/store"
+	"github.com/benchkram/bob/test/setup"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var (
+	// dir is the basic test directory
+	// in which the test is executed.
+	dir string
+
+	// artifactStore temporary store to
+	// avoid interfeering with the users cache.
+	artifactStore store.Store
+	// buildInfoStore temporary store
+	// to avoid interfeering with the users cache.
+	buildInfoStore buildinfostore.Store
+
+	// cleanup is called at the end to remove all test files from the system.
+	cleanup func() error
+)
+
+var _ = BeforeSuite(func() {
+	var err error
+	var storageDir string
+	dir, storageDir, cleanup, err = setup.TestDirs("dry-run")
+	Expect(err).NotTo(HaveOccurred())
+
+	artifactStore, err = bob.Filestore(storageDir)
+	Expect(err).NotTo(HaveOccurred())
+	buildInfoStore, err = bob.BuildinfoStore(storageDir)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = os.Chdir(dir)
+	Expect(err).NotTo(HaveOccurred())
+})
+
+var _ = AfterSuite(func() {
+	err := cleanup()
+	Expect(err).NotTo(HaveOccurred())
+})
+
+func TestDryRun(t *testing.T) {
+	_, err := exec.LookPath("nix")
+	if err != nil {
+		// Allow to skip tests only locally.
+		// CI is always set to true on GitHub actions.
+		// https://docs.github.com/en/actions/learn-github-actions/environment-variables#default-environment-variables
+		if os.Getenv("CI") != "true" {
+			t.Skip("Test skipped because nix is not installed on your system")
+		}
+	}
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "dry-run suite")
+}
@@ -0,0 +1,143 @@
+package eventstest
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/benchkram/bob/bob"
+	"github.com/benchkram/bob/bob/playbook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// recorder collects events passed from concurrent workers.
+type recorder struct {
+	mu     sync.Mutex
+	events []playbook.Event
+}
+
+func (r *recorder) handle(e playbook.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+// states returns the state transitions of a task in order.
+func (r *recorder) states(task string) (states []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.events {
+		if e.Task == task {
+			states = append(states, e.State)
+		}
+	}
+	return states
+}
+
+func (r *recorder) last(task string) (last playbook.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.events {
+		if e.Task == task {
+			last = e
+		}
+	}
+	return last
+}
+
+var _ = Describe("Testing build events", func() {
+	ctx := context.Background()
+
+	var b *bob.B
+	var r *recorder
+	BeforeEach(func() {
+		var err error
+		r = &recorder{}
+		b, err = bobSetup(bob.WithEventHandler(r.handle))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should emit events for all state transitions", func() {
+		err := b.Build(ctx, "build")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(r.states("generate")).To(Equal([]string{"pending", "queued", "running", "completed"}))
+		Expect(r.states("build")).To(Equal([]string{"pending", "queued", "running", "completed"}))
+
+		last := r.last("generate")
+		Expect(last.Cause).To(Equal(playbook.InputNotFoundInBuildInfo))
+		Expect(last.InputHash).NotTo(BeEmpty())
+		Expect(last.DurationMs).NotTo(BeNil())
+		Expect(last.Error).To(BeEmpty())
+	})
+
+	It("should emit cached events", func() {
+		err := b.Build(ctx, "build")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(r.states("generate")).To(Equal([]string{"pending", "queued", "running", "cached"}))
+		Expect(r.last("generate").Cause).To(BeEmpty())
+	})
+
+	It("should report the artifact source of restored targets", func() {
+		err := os.Remove("generated.txt")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = b.Build(ctx, "build")
+		Expect(err).NotTo(HaveOccurred())
+
+		last := r.last("generate")
+		Expect(last.State).To(Equal("cached"))
+		Expect(last.Cause).To(Equal(playbook.TargetInvalid))
+		Expect(last.ArtifactSource).To(Equal(playbook.ArtifactSourceLocal))
+	})
+
+	It("should write a chrome trace and a junit report", func() {
+		traceFile := filepath.Join(dir, "trace.json")
+		junitFile := filepath.Join(dir, "junit.xml")
+
+		b, err := bobSetup(bob.WithTraceFile(traceFile), bob.WithJUnitFile(junitFile))
+		Expect(err).NotTo(HaveOccurred())
+
+		err = b.Build(ctx, "build")
+		Expect(err).NotTo(HaveOccurred())
+
+		raw, err := os.ReadFile(traceFile)
+		Expect(err).NotTo(HaveOccurred())
+		var trace struct {
+			TraceEvents []struct {
+				Name string `json:"name"`
+				Ph   string `json:"ph"`
+			} `json:"traceEvents"`
+		}
+		err = json.Unmarshal(raw, &trace)
+		Expect(err).NotTo(HaveOccurred())
+
+		spans := []string{}
+		for _, e := range trace.TraceEvents {
+			if e.Ph == "X" {
+				spans = append(spans, e.Name)
+			}
+		}
+		Expect(spans).To(Equal([]string{"generate", "build"}))
+
+		raw, err = os.ReadFile(junitFile)
+		Expect(err).NotTo(HaveOccurred())
+		var junit struct {
+			Suites []struct {
+				Tests    int `xml:"tests,attr"`
+				Failures int `xml:"failures,attr"`
+			} `xml:"testsuite"`
+		}
+		err = xml.Unmarshal(raw, &junit)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(junit.Suites).To(HaveLen(1))
+		Expect(junit.Suites[0].Tests).To(Equal(2))
+		Expect(junit.Suites[0].Failures).To(Equal(0))
+	})
+})
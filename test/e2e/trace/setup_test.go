@@ -0,0 +1,42 @@
+package tracetest
+
+import (
+	"os"
+
+	"github.com/benchkram/bob/bob"
+	"github.com/benchkram/errz"
+)
+
+// bobfile of a task which only reads files through child processes
+const bobfile = `build:
+  generate:
+    input: input.txt
+    cmd: sh -c 'cat input.txt > generated.txt'
+    target: generated.txt
+  build:
+    input: unused.txt
+    cmd: sh -c 'cat generated.txt other.txt > build.txt'
+    target: build.txt
+    dependsOn: [generate]
+`
+
+func bobSetup(opts ...bob.Option) (_ *bob.B, err error) {
+	defer errz.Recover(&err)
+
+	err = os.WriteFile("bob.yaml", []byte(bobfile), 0664)
+	errz.Fatal(err)
+	for _, name := range []string{"input.txt", "other.txt", "unused.txt"} {
+		err = os.WriteFile(name, []byte(name), 0664)
+		errz.Fatal(err)
+	}
+
+	static := []bob.Option{
+		bob.WithDir(dir),
+		bob.WithFilestore(artifactStore),
+		bob.WithBuildinfoStore(buildInfoStore),
+	}
+	static = append(static, opts...)
+	return bob.Bob(
+		static...,
+	)
+}
@@ -0,0 +1,37 @@
+package tracetest
+
+import (
+	"context"
+
+	"github.com/benchkram/bob/bob"
+	"github.com/benchkram/bob/pkg/proctrace"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Testing input tracing", func() {
+	ctx := context.Background()
+
+	var b *bob.B
+	It("should setup test environment", func() {
+		var err error
+		b, err = bobSetup()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should suggest the files read by child processes", func() {
+		if !proctrace.Supported() {
+			Skip("tracing not supported")
+		}
+
+		result, err := b.TraceInputs(ctx, "build")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(result.Task).To(Equal("build"))
+		Expect(result.Dir).To(Equal("."))
+		Expect(result.Suggested).To(Equal([]string{"generated.txt", "other.txt"}))
+		Expect(result.Missing).To(Equal([]string{"generated.txt", "other.txt"}))
+		Expect(result.Unused).To(Equal([]string{"unused.txt"}))
+	})
+})
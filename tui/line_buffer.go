@@ -1,11 +1,15 @@
 package tui
 
 import (
+	"github.com/benchkram/bob/pkg/boblog"
 	"github.com/mitchellh/go-wordwrap"
+	"regexp"
 	"strings"
 	"sync"
 )
 
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[a-zA-Z]`)
+
 type LineBuffer struct {
 	mutex    sync.Mutex
 	width    int
@@ -25,7 +29,8 @@ func (s *LineBuffer) Write(p []byte) (n int, err error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	l := string(p)
+	// secrets of build tasks must never show up in the tui
+	l := boblog.Redact(string(p))
 	s.messages = append(s.messages, l)
 	wl := s.wrap(l)
 	s.lines = append(s.lines, wl...)
@@ -100,3 +105,35 @@ func (s *LineBuffer) wrap(line string) []string {
 	wl := wordwrap.WrapString(line, uint(s.width))
 	return strings.Split(wl, "\n")
 }
+
+// Find returns the index of the next line containing query, ignoring case
+// and colors. The search starts at from and wraps around at the end
+// (or the beginning when searching backwards). Returns -1 if no line matches.
+func (s *LineBuffer) Find(query string, from int, forward bool) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	n := len(s.lines)
+	if query == "" || n == 0 {
+		return -1
+	}
+	query = strings.ToLower(query)
+
+	step := 1
+	if !forward {
+		step = -1
+	}
+	from = ((from % n) + n) % n
+
+	for i, j := 0, from; i < n; i, j = i+1, (j+step+n)%n {
+		if strings.Contains(strings.ToLower(stripColors(s.lines[j])), query) {
+			return j
+		}
+	}
+	return -1
+}
+
+// stripColors removes terminal escape sequences from a line.
+func stripColors(line string) string {
+	return ansiEscape.ReplaceAllString(line, "")
+}
@@ -0,0 +1,33 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineBufferFind(t *testing.T) {
+	buf := NewLineBuffer(120)
+	for _, l := range []string{
+		"starting server",
+		aurora.Red("ERROR connection refused").String(),
+		"listening on :8080",
+		"error: timeout",
+	} {
+		_, err := buf.Write([]byte(l))
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, 1, buf.Find("error", 0, true))
+	assert.Equal(t, 3, buf.Find("error", 2, true))
+	// wraps around at the end
+	assert.Equal(t, 1, buf.Find("error", 4, true))
+	assert.Equal(t, 1, buf.Find("error", 2, false))
+	// wraps around at the beginning
+	assert.Equal(t, 3, buf.Find("error", 0, false))
+	// colors are ignored
+	assert.Equal(t, 1, buf.Find("error connection", 0, true))
+	assert.Equal(t, -1, buf.Find("panic", 0, true))
+	assert.Equal(t, -1, buf.Find("", 0, true))
+}
@@ -1,11 +1,19 @@
 package tui
 
+import "github.com/benchkram/bob/pkg/ctl"
+
 type Quit struct{}
 
 type Started struct{}
 
 type Restarted struct{}
 
+// Rebuild is received when build dependencies
+// of commands changed and are rebuilt.
+type Rebuild struct {
+	ctl.Rebuild
+}
+
 type Update struct {
 	tab int
 }
@@ -38,15 +38,18 @@ type keyMap struct {
 	SelectScroll key.Binding
 	Up           key.Binding
 	Down         key.Binding
+	Search       key.Binding
+	NextMatch    key.Binding
+	PrevMatch    key.Binding
 }
 
 func (k keyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Restart, k.NextTab, k.FollowOutput, k.SelectScroll, k.Quit}
+	return []key.Binding{k.Restart, k.NextTab, k.FollowOutput, k.SelectScroll, k.Search, k.Quit}
 }
 
 func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Restart, k.NextTab, k.FollowOutput, k.SelectScroll, k.Quit},
+		{k.Restart, k.NextTab, k.FollowOutput, k.SelectScroll, k.Search, k.NextMatch, k.PrevMatch, k.Quit},
 	}
 }
 
@@ -77,6 +80,18 @@ var keys = keyMap{
 	Down: key.NewBinding(
 		key.WithKeys("down", "pgdown", "wheel down"),
 	),
+	Search: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("[/]", "search"),
+	),
+	NextMatch: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("[n]", "next match"),
+	),
+	PrevMatch: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("[N]", "previous match"),
+	),
 }
 
 type model struct {
@@ -89,6 +104,7 @@ type model struct {
 	currentTab    int
 	starting      bool
 	restarting    bool
+	rebuilding    bool
 	stopping      bool
 	width         int
 	height        int
@@ -99,11 +115,19 @@ type model struct {
 	scrollOffset  int
 	ready         bool
 	error         error
+
+	// searching is true while the search query is typed
+	searching bool
+	query     string
+	// match is the line of the current search match, -1 if none
+	match int
 }
 
 type tab struct {
 	name   string
 	output *LineBuffer
+	// health is nil for commands without a healthcheck
+	health ctl.HealthReporter
 }
 
 func newModel(cmder ctl.Commander, evts, programEvts chan interface{}, buffer *LineBuffer) *model {
@@ -120,9 +144,12 @@ func newModel(cmder ctl.Commander, evts, programEvts chan interface{}, buffer *L
 			errz.Log(err)
 		}
 
+		health, _ := cmd.(ctl.HealthReporter)
+
 		tabs = append(tabs, &tab{
 			name:   cmd.Name(),
 			output: buf,
+			health: health,
 		})
 	}
 
@@ -135,6 +162,7 @@ func newModel(cmder ctl.Commander, evts, programEvts chan interface{}, buffer *L
 		programEvents: programEvts,
 		keys:          keys,
 		follow:        true,
+		match:         -1,
 		footer: help.Model{
 			ShowAll:        false,
 			ShortSeparator: " · ",
@@ -176,11 +204,15 @@ func multiScanner(tabId int, events chan interface{}, rs ...io.Reader) (*LineBuf
 }
 
 func (m *model) Init() tea.Cmd {
-	return tea.Batch(
+	cmds := []tea.Cmd{
 		start(m),
 		nextEvent(m.events),
 		tick(),
-	)
+	}
+	if r, ok := m.cmder.(ctl.Rebuilder); ok {
+		cmds = append(cmds, nextRebuild(r.Rebuilds()))
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -191,6 +223,11 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
 	case tea.KeyMsg:
+		if m.searching && !key.Matches(msg, m.keys.Quit) {
+			m.updateSearch(msg)
+			break
+		}
+
 		// for _, r := range msg.Runes {
 		//	print(fmt.Sprintf("%s\n", strconv.QuoteRuneToASCII(r)))
 		// }
@@ -201,12 +238,15 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case key.Matches(msg, m.keys.NextTab):
 			m.currentTab = (m.currentTab + 1) % len(m.tabs)
+			m.match = -1
 
 			m.setOffset(m.tabs[m.currentTab].output.Len())
 			m.updateContent()
 			updateHeader = true
 
 		case key.Matches(msg, m.keys.FollowOutput):
+			m.query = ""
+			m.match = -1
 			m.follow = true
 			m.setOffset(m.tabs[m.currentTab].output.Len())
 			m.updateContent()
@@ -267,6 +307,18 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, m.keys.Down):
 			m.updateOffset(1)
 			m.updateContent()
+
+		case key.Matches(msg, m.keys.Search):
+			m.searching = true
+			m.query = ""
+			m.match = -1
+			m.updateContent()
+
+		case key.Matches(msg, m.keys.NextMatch):
+			m.findMatch(true)
+
+		case key.Matches(msg, m.keys.PrevMatch):
+			m.findMatch(false)
 		}
 
 	case tea.MouseMsg:
@@ -283,6 +335,8 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case time.Time:
 		cmds = append(cmds, tick())
+		// refresh the health of commands
+		updateHeader = true
 
 	case tea.WindowSizeMsg:
 		if !m.ready {
@@ -312,6 +366,8 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// update all lines in the buffers so that soft wrapping works nicely
 			t.output.SetWidth(m.width)
 		}
+		// line indexes changed with the wrapping
+		m.match = -1
 
 		if m.follow {
 			m.updateOffset(m.tabs[m.currentTab].output.Len())
@@ -330,6 +386,35 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.restarting = false
 		updateHeader = true
 
+	case Rebuild:
+		m.rebuilding = msg.State == ctl.RebuildStarted
+		updateHeader = true
+
+		status := fmt.Sprintf("\n%-*s\n", 10, msg.State)
+		switch msg.State {
+		case ctl.RebuildStarted:
+			status = aurora.Colorize(status, aurora.CyanFg|aurora.BoldFm).String()
+		case ctl.RebuildFailed:
+			status = aurora.Colorize(status, aurora.RedFg|aurora.BoldFm).String()
+		default:
+			status = aurora.Colorize(status, aurora.GreenFg|aurora.BoldFm).String()
+		}
+
+		// show the status inline in the tabs of affected commands
+		for i, t := range m.tabs {
+			if !contains(msg.Commands, t.name) {
+				continue
+			}
+			_, err := t.output.Write([]byte(status))
+			errz.Log(err)
+
+			m.events <- Update{tab: i}
+		}
+
+		if r, ok := m.cmder.(ctl.Rebuilder); ok {
+			cmds = append(cmds, nextRebuild(r.Rebuilds()))
+		}
+
 	case Update:
 		// ignore updates for tabs that are not currently in view
 		if msg.tab == m.currentTab {
@@ -353,6 +438,9 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.starting {
 			status = fmt.Sprintf("%-*s", 10, "starting")
 			status = aurora.Colorize(status, aurora.BlueFg|aurora.BoldFm).String()
+		} else if m.rebuilding {
+			status = fmt.Sprintf("%-*s", 10, "rebuilding")
+			status = aurora.Colorize(status, aurora.CyanFg|aurora.BoldFm).String()
 		} else if m.restarting {
 			status = fmt.Sprintf("%-*s", 10, "restarting")
 			status = aurora.Colorize(status, aurora.CyanFg|aurora.BoldFm).String()
@@ -374,6 +462,10 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				name = aurora.Colorize(tab.name, aurora.WhiteFg).String()
 			}
 
+			if tab.health != nil {
+				name = fmt.Sprintf("%s %s", name, healthIndicator(tab.health.Health()))
+			}
+
 			tabs[i] = fmt.Sprintf("[%s]", name)
 		}
 
@@ -406,7 +498,13 @@ func (m *model) updateContent() {
 	from := min(offset, maxOffset)
 	to := max(offset+viewportHeight, 0)
 
-	lines := buf.Lines(from, to)
+	lines := append([]string{}, buf.Lines(from, to)...)
+
+	// highlight the current search match
+	if m.match >= from && m.match < from+len(lines) {
+		i := m.match - from
+		lines[i] = aurora.Reverse(stripColors(lines[i])).String()
+	}
 
 	m.content.SetContent(strings.Join(lines, "\n"))
 }
@@ -434,11 +532,73 @@ func (m *model) View() string {
 	view.WriteString("\n")
 	view.WriteString(m.content.View())
 	view.WriteString("\n\n")
-	view.WriteString(m.footer.View(m.keys))
+	if m.searching || m.query != "" {
+		view.WriteString(m.searchView())
+	} else {
+		view.WriteString(m.footer.View(m.keys))
+	}
 
 	return view.String()
 }
 
+// updateSearch reads the search query while searching.
+// The search starts on enter and is canceled by esc.
+func (m *model) updateSearch(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.searching = false
+		m.match = -1
+		m.findMatch(true)
+	case tea.KeyEsc:
+		m.searching = false
+		m.query = ""
+	case tea.KeyBackspace:
+		if len(m.query) > 0 {
+			runes := []rune(m.query)
+			m.query = string(runes[:len(runes)-1])
+		}
+	case tea.KeySpace:
+		m.query += " "
+	case tea.KeyRunes:
+		m.query += string(msg.Runes)
+	}
+}
+
+// findMatch scrolls to the next or previous line matching the search query.
+func (m *model) findMatch(forward bool) {
+	if m.query == "" {
+		return
+	}
+
+	// start at the top of the view without a previous match
+	from := m.scrollOffset
+	if m.match >= 0 {
+		from = m.match - 1
+		if forward {
+			from = m.match + 1
+		}
+	}
+
+	m.match = m.tabs[m.currentTab].output.Find(m.query, from, forward)
+	if m.match >= 0 {
+		m.follow = false
+		m.setOffset(m.match)
+	}
+	m.updateContent()
+}
+
+func (m *model) searchView() string {
+	if m.searching {
+		return fmt.Sprintf("/%s█", m.query)
+	}
+
+	search := fmt.Sprintf("/%s", m.query)
+	if m.match < 0 {
+		search += aurora.Colorize("  no match", aurora.RedFg).String()
+	}
+	return search + m.footer.Styles.ShortDesc.Render("  · [n] next match · [N] previous match · [ESC] follow output")
+}
+
 func (m *model) updateOffset(delta int) {
 	m.setOffset(m.scrollOffset + delta*3)
 }
@@ -515,6 +675,35 @@ func stop(m *model) tea.Cmd {
 	}
 }
 
+// healthIndicator colors the health state of a command.
+func healthIndicator(health ctl.HealthState) string {
+	switch health {
+	case ctl.HealthHealthy:
+		return aurora.Colorize("● "+string(health), aurora.GreenFg).String()
+	case ctl.HealthUnhealthy:
+		return aurora.Colorize("● "+string(health), aurora.RedFg).String()
+	case ctl.HealthStarting:
+		return aurora.Colorize("● "+string(health), aurora.YellowFg).String()
+	default:
+		return aurora.Colorize("○ "+string(health), aurora.WhiteFg).String()
+	}
+}
+
+func nextRebuild(rebuilds <-chan ctl.Rebuild) tea.Cmd {
+	return func() tea.Msg {
+		return Rebuild{<-rebuilds}
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
 func nextEvent(evts chan interface{}) tea.Cmd {
 	return func() tea.Msg {
 		return <-evts
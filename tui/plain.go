@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/logrusorgru/aurora"
+
+	"github.com/benchkram/bob/pkg/boblog"
+	"github.com/benchkram/bob/pkg/ctl"
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+var plainColors = []aurora.Color{
+	aurora.GreenFg,
+	aurora.BlueFg,
+	aurora.CyanFg,
+	aurora.MagentaFg,
+	aurora.YellowFg,
+	aurora.Index(42, nil).Color(),
+	aurora.Index(111, nil).Color(),
+	aurora.Index(141, nil).Color(),
+}
+
+// RunPlain starts the commander and streams the output of its commands to
+// stdout, each line prefixed with the command's name. Use it where no
+// interactive terminal is available.
+//
+// Returns when ctx is canceled or all commands exited. An error is returned
+// if a command exited on its own. With exitOnFirst it returns on the first
+// exit. Commands are shutdown by canceling the commander's context.
+func RunPlain(ctx context.Context, cmder ctl.Commander, exitOnFirst bool) (err error) {
+	commands := cmder.Subcommands()
+
+	namePad := 0
+	for _, cmd := range commands {
+		if len(cmd.Name()) > namePad {
+			namePad = len(cmd.Name())
+		}
+	}
+
+	var mu sync.Mutex
+	for i, cmd := range commands {
+		name := aurora.Colorize(cmd.Name(), plainColors[i%len(plainColors)]).String()
+		for _, r := range []io.Reader{cmd.Stdout(), cmd.Stderr()} {
+			go func(r io.Reader) {
+				s := bufio.NewScanner(r)
+				s.Split(bufio.ScanLines)
+				for s.Scan() {
+					mu.Lock()
+					fmt.Fprintf(os.Stdout, "%-*s\t  %s\n", namePad, name, boblog.Redact(s.Text()))
+					mu.Unlock()
+				}
+			}(r)
+		}
+	}
+
+	err = cmder.Start()
+	if err != nil {
+		return err
+	}
+
+	type exit struct {
+		name string
+		err  error
+	}
+	exits := make(chan exit)
+	watched := 0
+	for _, cmd := range commands {
+		en, ok := cmd.(ctl.ExitNotifier)
+		if !ok || en.Exited() == nil {
+			// e.g. compose commands wrapped by WithInit
+			continue
+		}
+		watched++
+		go func(name string, exited <-chan error) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case err := <-exited:
+					select {
+					case exits <- exit{name: name, err: err}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}(cmd.Name(), en.Exited())
+	}
+
+	var exited []string
+	for {
+		select {
+		case <-ctx.Done():
+			return exitError(exited)
+		case e := <-exits:
+			reason := "exited"
+			if e.err != nil {
+				reason = fmt.Sprintf("exited: %s", e.err.Error())
+			}
+			boblog.Log.Info(aurora.Red(fmt.Sprintf("[%s] %s", e.name, reason)).String())
+
+			if !contains(exited, e.name) {
+				exited = append(exited, e.name)
+			}
+			if exitOnFirst || len(exited) >= watched {
+				return exitError(exited)
+			}
+		}
+	}
+}
+
+func exitError(exited []string) error {
+	if len(exited) == 0 {
+		return nil
+	}
+	return usererror.Wrap(fmt.Errorf("run tasks exited unexpectedly: %s", strings.Join(exited, ", ")))
+}
@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/benchkram/bob/pkg/ctl"
+	"github.com/benchkram/bob/pkg/usererror"
+)
+
+// fakeCommand reports exits on exited, a nil channel
+// mimics a wrapped compose command.
+type fakeCommand struct {
+	ctl.Command
+	name   string
+	exited chan error
+}
+
+func (c *fakeCommand) Name() string         { return c.name }
+func (c *fakeCommand) Stdout() io.Reader    { return strings.NewReader("") }
+func (c *fakeCommand) Stderr() io.Reader    { return strings.NewReader("") }
+func (c *fakeCommand) Exited() <-chan error { return c.exited }
+
+type fakeCommander struct {
+	ctl.Command
+	commands []ctl.Command
+}
+
+func (c *fakeCommander) Start() error               { return nil }
+func (c *fakeCommander) Subcommands() []ctl.Command { return c.commands }
+
+func TestRunPlainIgnoresCommandsWithoutExits(t *testing.T) {
+	server := &fakeCommand{name: "server", exited: make(chan error, 1)}
+	compose := &fakeCommand{name: "compose"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	server.exited <- errors.New("exit status 1")
+	err := RunPlain(ctx, &fakeCommander{commands: []ctl.Command{server, compose}}, false)
+
+	assert.Nil(t, ctx.Err(), "must return once all binaries exited")
+	assert.True(t, errors.As(err, &usererror.Err))
+	assert.Contains(t, err.Error(), "server")
+}